@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// addBatchFlags adds the flag (named "all" by convention) which selects all
+// functions within the path, and the flag limiting their concurrency.
+func addBatchFlags(cmd *cobra.Command, name string) {
+	cmd.Flags().Bool(name, false,
+		"Operate upon all functions within the path, rather than the function at the path. ($FUNC_"+strings.ToUpper(strings.ReplaceAll(name, "-", "_"))+")")
+	cmd.Flags().Int("concurrency", fn.DefaultBatchConcurrency,
+		"Number of functions operated upon concurrently when operating upon all functions. ($FUNC_CONCURRENCY)")
+}
+
+// discoverBatch returns the functions within path, and the dependencies
+// between them declared by the workspace file in path, if any.
+func discoverBatch(path string) (ff []fn.Function, dependencies map[string][]string, err error) {
+	if ff, err = fn.DiscoverFunctions(path); err != nil {
+		return
+	}
+	if len(ff) == 0 {
+		return ff, nil, fmt.Errorf("no functions found within %v", path)
+	}
+	if _, err = os.Stat(filepath.Join(path, fn.WorkspaceFile)); os.IsNotExist(err) {
+		return ff, nil, nil
+	}
+	w, err := fn.LoadWorkspace(path)
+	if err != nil {
+		return
+	}
+	return ff, w.Dependencies(), nil
+}
+
+// validateBatch returns an error if any of the given flags, which apply only
+// to a single function, were provided along with the batch flag.
+func validateBatch(cmd *cobra.Command, batchFlag string, flags ...string) error {
+	for _, flag := range flags {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("--%v can not be used with --%v", flag, batchFlag)
+		}
+	}
+	return nil
+}
+
+// configureBatch configures a function of a batch.  The global --registry and
+// --builder apply only to functions which do not configure their own, as
+// the defaults of these flags are not taken from any one function.
+func configureBatch(cfg buildConfig, f fn.Function) fn.Function {
+	if f.Registry == "" {
+		f.Registry = cfg.Registry
+	}
+	if f.Build.Builder == "" {
+		f.Build.Builder = cfg.Builder
+	}
+	return f
+}
+
+// indexOfRoot returns the index of the function with the given root.
+func indexOfRoot(ff []fn.Function, root string) int {
+	for i, f := range ff {
+		if f.Root == root {
+			return i
+		}
+	}
+	return -1
+}
+
+// printBatchResults writes a table of the outcome of the operation upon each
+// function of a batch, returning an error if any failed.
+func printBatchResults(out io.Writer, root, verb string, results []fn.BatchResult) error {
+	failed := 0
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPATH\tRESULT\tDETAIL")
+	for _, r := range results {
+		path, err := filepath.Rel(root, r.Function.Root)
+		if err != nil {
+			path = r.Function.Root
+		}
+		result, detail := verb, ""
+		if r.Err != nil {
+			failed++
+			result, detail = "failed", r.Err.Error()
+			if errors.As(r.Err, &fn.ErrDependencyFailed{}) {
+				result = "skipped"
+			}
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", r.Function.Name, filepath.ToSlash(path), result, detail)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%v of %v functions failed", failed, len(results))
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -30,7 +31,8 @@ SYNOPSIS
 	{{rootCmdUse}} build [-r|--registry] [--builder] [--builder-image]
 		         [--push] [--username] [--password] [--token]
 	             [--platform] [-p|--path] [-c|--confirm] [-v|--verbose]
-		         [--build-timestamp] [--registry-insecure]
+		         [--build-timestamp] [--registry-insecure] [--all]
+		         [--concurrency] [-o|--output]
 
 DESCRIPTION
 
@@ -46,6 +48,22 @@ DESCRIPTION
 	When building a function for the first time, either a registry or explicit
 	image name is required.  Subsequent builds will reuse these option values.
 
+	All Functions
+	  The --all flag builds (and if requested pushes) every function within
+	  the --path directory (by default the current directory), such as all
+	  functions of a monorepo, up to --concurrency at a time.  A function which
+	  fails does not prevent the building of others, and a summary of the
+	  outcome for each function is printed.  The --registry and --builder
+	  apply to functions which do not configure their own, and flags which
+	  configure a single function (such as --image) can not be used.
+
+	Progress
+	  By default progress is printed as messages to stderr.  With --output
+	  json, events are instead streamed to stdout as JSON lines as the build
+	  starts and completes, the image's layers are pushed and its digest is
+	  resolved, or an error occurs.  The --output json flag can not be used
+	  with --verbose.
+
 EXAMPLES
 
 	o Build a function container using the given registry.
@@ -56,6 +74,12 @@ EXAMPLES
 	  and function name.
 	  $ {{rootCmdUse}} build --image registry.example.com/alice/f:latest
 
+	o Build and push a function, streaming its progress as JSON lines.
+	  $ {{rootCmdUse}} build --push --output json
+
+	o Build all functions within the current directory and push them.
+	  $ {{rootCmdUse}} build --all --push
+
 	o Rebuild a function using prior values to determine container name.
 	  $ {{rootCmdUse}} build
 
@@ -70,7 +94,8 @@ EXAMPLES
 		SuggestFor: []string{"biuld", "buidl", "built"},
 		PreRunE: bindEnv("image", "path", "builder", "registry", "confirm",
 			"push", "builder-image", "platform", "verbose", "build-timestamp",
-			"registry-insecure", "username", "password", "token"),
+			"registry-insecure", "username", "password", "token", "all", "concurrency",
+			"output"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runBuild(cmd, args, newClient)
 		},
@@ -138,9 +163,12 @@ EXAMPLES
 	_ = cmd.Flags().MarkHidden("password")
 	_ = cmd.Flags().MarkHidden("token")
 
+	addBatchFlags(cmd, "all")
+
 	// Oft-shared flags:
 	addConfirmFlag(cmd, cfg.Confirm)
 	addPathFlag(cmd)
+	addProgressOutputFlag(cmd)
 	addVerboseFlag(cmd, cfg.Verbose)
 
 	// Tab Completion
@@ -159,6 +187,9 @@ func runBuild(cmd *cobra.Command, _ []string, newClient ClientFactory) (err erro
 		cfg buildConfig
 		f   fn.Function
 	)
+	if viper.GetBool("all") {
+		return runBuildAll(cmd, newClient)
+	}
 	if cfg, err = newBuildConfig().Prompt(); err != nil { // gather values into a single instruction set
 		return
 	}
@@ -180,6 +211,7 @@ func runBuild(cmd *cobra.Command, _ []string, newClient ClientFactory) (err erro
 	if err != nil {
 		return
 	}
+	clientOptions = append(clientOptions, cfg.progressOptions(cmd.OutOrStdout())...)
 	client, done := newClient(ClientConfig{Verbose: cfg.Verbose}, clientOptions...)
 	defer done()
 
@@ -204,6 +236,64 @@ func runBuild(cmd *cobra.Command, _ []string, newClient ClientFactory) (err erro
 	return f.Stamp()
 }
 
+// runBuildAll builds (and if requested pushes) all functions within the path
+// concurrently.
+func runBuildAll(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	cfg := newBuildConfig()
+	if err = cfg.Validate(); err != nil {
+		return
+	}
+	if err = validateBatch(cmd, "all", "image", "builder-image"); err != nil {
+		return
+	}
+	root, err := filepath.Abs(cfg.Path)
+	if err != nil {
+		return
+	}
+	ff, _, err := discoverBatch(root)
+	if err != nil {
+		return
+	}
+	cmd.SetContext(cfg.WithValues(cmd.Context()))
+
+	clientOptions, err := cfg.clientOptions()
+	if err != nil {
+		return
+	}
+	clientOptions = append(clientOptions, cfg.progressOptions(cmd.OutOrStdout())...)
+	client, done := newClient(ClientConfig{Verbose: cfg.Verbose}, clientOptions...)
+	defer done()
+	buildOptions, err := cfg.buildOptions()
+	if err != nil {
+		return
+	}
+
+	results, err := fn.Batch(cmd.Context(), ff, nil, viper.GetInt("concurrency"), func(ctx context.Context, f fn.Function) (fn.Function, error) {
+		var err error
+		f = configureBatch(cfg, f)
+		if f, err = client.Build(ctx, f, buildOptions...); err != nil {
+			return f, err
+		}
+		if cfg.Push {
+			if f, _, err = client.Push(ctx, f); err != nil {
+				return f, err
+			}
+		}
+		if err = f.Write(); err != nil {
+			return f, err
+		}
+		return f, f.Stamp()
+	})
+	if err != nil {
+		return
+	}
+	verb := "built"
+	if cfg.Push {
+		verb = "pushed"
+	}
+	return printBatchResults(cfg.messages(cmd), root, verb, results)
+}
+
 // WithValues returns a context populated with values from the build config
 // which are provided to the system via the context.
 func (c buildConfig) WithValues(ctx context.Context) context.Context {
@@ -250,6 +340,10 @@ type buildConfig struct {
 	// Build with the current timestamp as the created time for docker image.
 	// This is only useful for buildpacks builder.
 	WithTimestamp bool
+
+	// Output format of progress: "human" messages, or "json" progress events
+	// streamed to stdout.
+	Output string
 }
 
 // newBuildConfig gathers options into a single build request.
@@ -271,6 +365,7 @@ func newBuildConfig() buildConfig {
 		Password:      viper.GetString("password"),
 		Token:         viper.GetString("token"),
 		WithTimestamp: viper.GetBool("build-timestamp"),
+		Output:        viper.GetString("output"),
 	}
 }
 
@@ -366,6 +461,17 @@ func (c buildConfig) Validate() (err error) {
 		return
 	}
 
+	// Progress is output as human-readable messages or JSON events, with
+	// which verbose logs written to stdout would be interleaved.
+	switch Format(c.Output) {
+	case "", Human, JSON:
+	default:
+		return fmt.Errorf("unsupported output format %q (supported: human, json)", c.Output)
+	}
+	if Format(c.Output) == JSON && c.Verbose {
+		return errors.New("--verbose can not be used with --output json")
+	}
+
 	return
 }
 
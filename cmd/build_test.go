@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"reflect"
 	"testing"
 
 	fn "knative.dev/func/pkg/functions"
@@ -152,3 +157,49 @@ func TestBuild_Push(t *testing.T) {
 		t.Fatal("push should not be invoked on a failed build")
 	}
 }
+
+// TestBuild_OutputJSON ensures that with --output json the progress of a
+// build and push is streamed to stdout as JSON lines, and that verbose logs
+// may not be interleaved with them.
+func TestBuild_OutputJSON(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f := fn.Function{Root: root, Name: "myfunc", Runtime: "go", Registry: "example.com/alice"}
+	if _, err := fn.New().Init(f); err != nil {
+		t.Fatal(err)
+	}
+
+	// The options of the command, which include its listener, are retained.
+	pusher := mock.NewPusher()
+	pusher.PushFn = func(context.Context, fn.Function) (string, error) { return "sha256:1", nil }
+	newClient := func(_ ClientConfig, oo ...fn.Option) (*fn.Client, func()) {
+		return fn.New(append(oo, fn.WithBuilder(mock.NewBuilder()), fn.WithPusher(pusher))...), func() {}
+	}
+
+	cmd := NewBuildCmd(newClient)
+	out := bytes.Buffer{}
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--push", "--output", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	types := []fn.ProgressType{}
+	s := bufio.NewScanner(&out)
+	for s.Scan() {
+		var e fn.ProgressEvent
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			t.Fatalf("expected a line of JSON, got %q. %v", s.Text(), err)
+		}
+		types = append(types, e.Type)
+	}
+	expected := []fn.ProgressType{fn.ProgressBuildStarted, fn.ProgressBuildCompleted, fn.ProgressPushStarted, fn.ProgressDigestResolved}
+	if !reflect.DeepEqual(types, expected) {
+		t.Fatalf("expected events %v, got %v", expected, types)
+	}
+
+	cmd.SetArgs([]string{"--output", "json", "--verbose"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected --verbose with --output json to be rejected")
+	}
+}
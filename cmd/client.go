@@ -63,9 +63,12 @@ func NewClient(cfg ClientConfig, options ...fn.Option) (*fn.Client, func()) {
 			fn.WithVerbose(cfg.Verbose),
 			fn.WithTransport(t),
 			fn.WithRepositoriesPath(config.RepositoriesPath()),
+			fn.WithJobsPath(config.JobsPath()),
 			fn.WithBuilder(buildpacks.NewBuilder(buildpacks.WithVerbose(cfg.Verbose))),
 			fn.WithRemover(knative.NewRemover(cfg.Verbose)),
 			fn.WithDescriber(knative.NewDescriber(cfg.Verbose)),
+			fn.WithDiffer(newKnativeDiffer(cfg.Verbose)),
+			fn.WithRouter(knative.NewRouter(cfg.Verbose)),
 			fn.WithLister(knative.NewLister(cfg.Verbose)),
 			fn.WithDeployer(d),
 			fn.WithPipelinesProvider(pp),
@@ -131,6 +134,12 @@ func newKnativeDeployer(verbose bool) fn.Deployer {
 	return knative.NewDeployer(options...)
 }
 
+func newKnativeDiffer(verbose bool) fn.Differ {
+	return knative.NewDiffer(
+		knative.WithDifferVerbose(verbose),
+		knative.WithDifferDecorator(deployDecorator{}))
+}
+
 type deployDecorator struct {
 	oshDec k8s.OpenshiftMetadataDecorator
 }
@@ -158,3 +158,27 @@ func CompleteBuilderList(cmd *cobra.Command, args []string, complete string) (ma
 
 	return
 }
+
+func CompleteEnvironmentList(cmd *cobra.Command, args []string, complete string) (matches []string, directive cobra.ShellCompDirective) {
+	directive = cobra.ShellCompDirectiveError
+
+	path, err := cmd.Flags().GetString("path")
+	if err != nil {
+		return
+	}
+
+	f, err := fn.NewFunction(path)
+	if err != nil {
+		return
+	}
+
+	matches = []string{}
+	for _, name := range f.Environments.Names() {
+		if strings.HasPrefix(name, complete) {
+			matches = append(matches, name)
+		}
+	}
+
+	directive = cobra.ShellCompDirectiveNoFileComp
+	return
+}
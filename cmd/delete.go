@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/ory/viper"
@@ -21,6 +23,13 @@ This command undeploys a function from the cluster. By default the function from
 the project in the current directory is undeployed. Alternatively either the name
 of the function can be given as argument or the project path provided with --path.
 
+The --all-functions flag undeploys every deployed function within the path
+(by default the current directory), such as all functions of a monorepo, up
+to --concurrency at a time.  If the directory contains a workspace file
+(func-workspace.yaml), functions are undeployed before those on which they
+depend.  A summary of the outcome for each function is printed.  (The --all
+flag instead selects whether all resources of a function are deleted.)
+
 No local files are deleted.
 `,
 		Example: `
@@ -29,11 +38,18 @@ No local files are deleted.
 
 # Undeploy the function 'myfunc' in namespace 'apps'
 {{rootCmdUse}} delete myfunc --namespace apps
+
+# Undeploy the function defined in the local directory from its "staging"
+# environment
+{{rootCmdUse}} delete --environment staging
+
+# Undeploy all functions within the local directory
+{{rootCmdUse}} delete --all-functions
 `,
 		SuggestFor:        []string{"remove", "del"},
 		Aliases:           []string{"rm"},
 		ValidArgsFunction: CompleteFunctionList,
-		PreRunE:           bindEnv("path", "confirm", "all", "namespace", "verbose"),
+		PreRunE:           bindEnv("path", "confirm", "all", "namespace", "environment", "verbose", "all-functions", "concurrency"),
 		SilenceUsage:      true, // no usage dump on error
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runDelete(cmd, args, newClient)
@@ -49,10 +65,16 @@ No local files are deleted.
 	// Flags
 	cmd.Flags().StringP("namespace", "n", defaultNamespace(fn.Function{}, false), "The namespace when deleting by name. ($FUNC_NAMESPACE)")
 	cmd.Flags().StringP("all", "a", "true", "Delete all resources created for a function, eg. Pipelines, Secrets, etc. ($FUNC_ALL) (allowed values: \"true\", \"false\")")
+	addBatchFlags(cmd, "all-functions")
 	addConfirmFlag(cmd, cfg.Confirm)
+	addEnvironmentFlag(cmd)
 	addPathFlag(cmd)
 	addVerboseFlag(cmd, cfg.Verbose)
 
+	if err := cmd.RegisterFlagCompletionFunc("environment", CompleteEnvironmentList); err != nil {
+		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
+	}
+
 	return cmd
 }
 
@@ -61,6 +83,9 @@ func runDelete(cmd *cobra.Command, args []string, newClient ClientFactory) (err
 	if err != nil {
 		return
 	}
+	if cfg.AllFunctions {
+		return runDeleteAll(cmd, cfg, newClient)
+	}
 	if cfg, err = cfg.Prompt(); err != nil {
 		return
 	}
@@ -75,16 +100,59 @@ func runDelete(cmd *cobra.Command, args []string, newClient ClientFactory) (err
 		if err != nil {
 			return err
 		}
+		if f, err = f.ForEnvironment(cfg.Environment); err != nil {
+			return err
+		}
 		return client.Remove(cmd.Context(), "", "", f, cfg.All)
 	}
 }
 
+// runDeleteAll undeploys all deployed functions within the path concurrently,
+// undeploying each before those on which it depends.
+func runDeleteAll(cmd *cobra.Command, cfg deleteConfig, newClient ClientFactory) (err error) {
+	root, err := filepath.Abs(cfg.Path)
+	if err != nil {
+		return
+	}
+	ff, dependencies, err := discoverBatch(root)
+	if err != nil {
+		return
+	}
+	deployed := []fn.Function{}
+	for _, f := range ff {
+		if f, err = f.ForEnvironment(cfg.Environment); err != nil {
+			return
+		}
+		if f.Deploy.Namespace != "" {
+			deployed = append(deployed, f)
+		}
+	}
+	if len(deployed) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "No deployed functions found within %v\n", root)
+		return
+	}
+
+	client, done := newClient(ClientConfig{Verbose: cfg.Verbose})
+	defer done()
+
+	results, err := fn.Batch(cmd.Context(), deployed, fn.ReverseDependencies(dependencies), cfg.Concurrency, func(ctx context.Context, f fn.Function) (fn.Function, error) {
+		return f, client.Remove(ctx, "", "", f, cfg.All)
+	})
+	if err != nil {
+		return
+	}
+	return printBatchResults(cmd.OutOrStdout(), root, "deleted", results)
+}
+
 type deleteConfig struct {
-	Name      string
-	Namespace string
-	Path      string
-	All       bool
-	Verbose   bool
+	Name         string
+	Namespace    string
+	Environment  string
+	Path         string
+	All          bool
+	AllFunctions bool
+	Concurrency  int
+	Verbose      bool
 }
 
 // newDeleteConfig returns a config populated from the current execution context
@@ -95,11 +163,15 @@ func newDeleteConfig(cmd *cobra.Command, args []string) (cfg deleteConfig, err e
 		name = args[0]
 	}
 	cfg = deleteConfig{
-		All:       viper.GetBool("all"),
-		Name:      name, // args[0] or derived
-		Namespace: viper.GetString("namespace"),
-		Path:      viper.GetString("path"),
-		Verbose:   viper.GetBool("verbose"), // defined on root
+		All:         viper.GetBool("all"),
+		Name:        name, // args[0] or derived
+		Namespace:   viper.GetString("namespace"),
+		Environment: viper.GetString("environment"),
+		Path:        viper.GetString("path"),
+		Verbose:     viper.GetBool("verbose"), // defined on root
+
+		AllFunctions: viper.GetBool("all-functions"),
+		Concurrency:  viper.GetInt("concurrency"),
 	}
 	if cfg.Name == "" && cmd.Flags().Changed("namespace") {
 		// logicially inconsistent to supply only a namespace.
@@ -113,6 +185,14 @@ func newDeleteConfig(cmd *cobra.Command, args []string) (cfg deleteConfig, err e
 		// a name and a namespace to ignore any local function source.
 		err = fmt.Errorf("only one of --path and [NAME] should be provided")
 	}
+	if cfg.Name != "" && cfg.Environment != "" {
+		// Environments are defined by the function's local source.
+		err = fmt.Errorf("only one of --environment and [NAME] should be provided")
+	}
+	if cfg.Name != "" && cfg.AllFunctions {
+		// The functions are those found within the path.
+		err = fmt.Errorf("only one of --all-functions and [NAME] should be provided")
+	}
 	return
 }
 
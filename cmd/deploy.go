@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -36,6 +38,8 @@ SYNOPSIS
 	             [--domain] [--platform] [--build-timestamp] [--pvc-size]
 	             [--service-account] [-c|--confirm] [-v|--verbose]
 	             [--registry-insecure] [--remote-storage-class]
+	             [--environment] [--all] [--concurrency] [--canary]
+	             [-o|--output]
 
 DESCRIPTION
 
@@ -87,6 +91,57 @@ DESCRIPTION
 	  selectors. Note that the domain specified must be one of those configured
 	  or the flag will be ignored.
 
+	Environments
+	  A function may define named deployment environments in its func.yaml,
+	  such as "staging" or "prod", which override the function's namespace,
+	  registry, domain, environment variables, labels and scale/resource
+	  options.  Deploying with --environment uses the named environment's
+	  settings and records the deployed instance under that environment,
+	  leaving the function's default deployment untouched.  Each environment
+	  is built separately, with its built image and build stamp held in
+	  .func/environments/<name>.
+	    environments:
+	      staging:
+	        namespace: staging
+	        envs:
+	        - name: LOG_LEVEL
+	          value: debug
+
+	Canary
+	  A function which is already deployed may be updated with a canary: a
+	  new revision which receives only a percent of traffic, until promoted
+	  with '{{rootCmdUse}} promote' or aborted with '{{rootCmdUse}} rollout abort'.
+	  The percent is defined by the rollout of func.yaml, or by --canary, and
+	  the traffic may be stepped up automatically.  See '{{rootCmdUse}} rollout'.
+
+	All Functions
+	  The --all flag deploys every function within the --path directory (by
+	  default the current directory), such as all functions of a monorepo.
+	  Functions are built and pushed concurrently, up to --concurrency at a
+	  time, and then deployed.  If the directory contains a workspace file
+	  (func-workspace.yaml, see '{{rootCmdUse}} run --workspace') the functions
+	  on which each depends, as listed by its dependsOn, are deployed before
+	  it:
+	    functions:
+	      - path: orders
+	        dependsOn: [payments]
+	      - path: payments
+	  A function which fails does not prevent the deployment of others, except
+	  those which depend upon it, which are skipped.  A summary of the outcome
+	  for each function is printed.  The --registry and --builder apply to
+	  functions which do not configure their own, --namespace and --env to
+	  all, and flags which configure a single function (such as --image) can
+	  not be used.
+
+	Progress
+	  By default progress is printed as messages to stderr.  With --output
+	  json, events are instead streamed to stdout as JSON lines as the build
+	  starts and completes, the image's layers are pushed and its digest is
+	  resolved, the new revision is ready and the deployment completes, or
+	  an error occurs.  For example:
+	    {"type":"push.digest","time":"...","function":"f","digest":"sha256:..."}
+	  The --output json flag can not be used with --remote or --verbose.
+
 EXAMPLES
 
 	o Deploy the function
@@ -112,6 +167,20 @@ EXAMPLES
 	  the function in the specified git repository.
 	  $ {{rootCmdUse}} deploy --remote --git-url=https://example.com/alice/myfunc.git
 
+	o Deploy the function to the "staging" environment defined in func.yaml
+	  $ {{rootCmdUse}} deploy --environment staging
+
+	o Deploy the function with its new revision receiving 10% of traffic, to
+	  be promoted later.
+	  $ {{rootCmdUse}} deploy --canary 10
+
+	o Deploy the function, streaming its progress as JSON lines.
+	  $ {{rootCmdUse}} deploy --output json
+
+	o Deploy all functions within the current directory, building four at a
+	  time.
+	  $ {{rootCmdUse}} deploy --all --concurrency 4
+
 	o Deploy the function, rebuilding the image even if no changes have been
 	  detected in the local filesystem (source).
 	  $ {{rootCmdUse}} deploy --build
@@ -127,7 +196,7 @@ EXAMPLES
 
 `,
 		SuggestFor: []string{"delpoy", "deplyo"},
-		PreRunE:    bindEnv("build", "build-timestamp", "builder", "builder-image", "confirm", "domain", "env", "git-branch", "git-dir", "git-url", "image", "namespace", "path", "platform", "push", "pvc-size", "service-account", "registry", "registry-insecure", "remote", "username", "password", "token", "verbose", "remote-storage-class"),
+		PreRunE:    bindEnv("build", "build-timestamp", "builder", "builder-image", "confirm", "domain", "env", "environment", "git-branch", "git-dir", "git-url", "image", "namespace", "path", "platform", "push", "pvc-size", "service-account", "registry", "registry-insecure", "remote", "username", "password", "token", "verbose", "remote-storage-class", "all", "concurrency", "canary", "output"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runDeploy(cmd, newClient)
 		},
@@ -140,7 +209,9 @@ EXAMPLES
 	}
 
 	// Function Context
-	f, _ := fn.NewFunction(effectivePath())
+	// Flag defaults are taken from the function as targeted at the
+	// effective environment (if any).
+	f := functionContext()
 	if f.Initialized() {
 		cfg = cfg.Apply(f)
 	}
@@ -204,6 +275,8 @@ EXAMPLES
 	cmd.Flags().BoolP("build-timestamp", "", false, "Use the actual time as the created time for the docker image. This is only useful for buildpacks builder.")
 	cmd.Flags().StringP("namespace", "n", defaultNamespace(f, false),
 		"Deploy into a specific namespace. Will use the function's current namespace by default if already deployed, and the currently active context if it can be determined. ($FUNC_NAMESPACE)")
+	cmd.Flags().Int64("canary", 0,
+		"Percent of traffic to route to the new revision as a canary, overriding the rollout of func.yaml for this deployment.  Zero routes all traffic to it. ($FUNC_CANARY)")
 
 	// Temporarily Hidden Basic Auth Flags
 	// Username, Password and Token flags, which plumb through basic auth, are
@@ -215,9 +288,13 @@ EXAMPLES
 	_ = cmd.Flags().MarkHidden("password")
 	_ = cmd.Flags().MarkHidden("token")
 
+	addBatchFlags(cmd, "all")
+
 	// Oft-shared flags:
 	addConfirmFlag(cmd, cfg.Confirm)
+	addEnvironmentFlag(cmd)
 	addPathFlag(cmd)
+	addProgressOutputFlag(cmd)
 	addVerboseFlag(cmd, cfg.Verbose)
 
 	// Tab Completion
@@ -229,6 +306,10 @@ EXAMPLES
 		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
 	}
 
+	if err := cmd.RegisterFlagCompletionFunc("environment", CompleteEnvironmentList); err != nil {
+		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
+	}
+
 	return cmd
 }
 
@@ -237,6 +318,9 @@ func runDeploy(cmd *cobra.Command, newClient ClientFactory) (err error) {
 		cfg deployConfig
 		f   fn.Function
 	)
+	if viper.GetBool("all") {
+		return runDeployAll(cmd, newClient)
+	}
 	if cfg, err = newDeployConfig(cmd).Prompt(); err != nil {
 		return
 	}
@@ -249,6 +333,9 @@ func runDeploy(cmd *cobra.Command, newClient ClientFactory) (err error) {
 	if !f.Initialized() {
 		return fn.NewErrNotInitialized(f.Root)
 	}
+	if f, err = f.ForEnvironment(cfg.Environment); err != nil {
+		return
+	}
 	if f, err = cfg.Configure(f); err != nil { // Updates f with deploy cfg
 		return
 	}
@@ -275,7 +362,7 @@ func runDeploy(cmd *cobra.Command, newClient ClientFactory) (err error) {
 	}
 
 	// Informative non-error messages regarding the final deployment request
-	printDeployMessages(cmd.OutOrStdout(), f)
+	printDeployMessages(cfg.messages(cmd), f)
 
 	// Get options based on the value of the config such as concrete impls
 	// of builders and pushers based on the value of the --builder flag
@@ -283,6 +370,7 @@ func runDeploy(cmd *cobra.Command, newClient ClientFactory) (err error) {
 	if err != nil {
 		return
 	}
+	clientOptions = append(clientOptions, cfg.progressOptions(cmd.OutOrStdout())...)
 	client, done := newClient(ClientConfig{Verbose: cfg.Verbose, InsecureSkipVerify: cfg.RegistryInsecure}, clientOptions...)
 	defer done()
 
@@ -343,7 +431,7 @@ func runDeploy(cmd *cobra.Command, newClient ClientFactory) (err error) {
 				f.Deploy.Image = f.Build.Image
 			}
 		}
-		if f, err = client.Deploy(cmd.Context(), f, fn.WithDeploySkipBuildCheck(cfg.Build == "false")); err != nil {
+		if f, err = client.Deploy(cmd.Context(), f, cfg.deployOptions()...); err != nil {
 			return
 		}
 	}
@@ -360,6 +448,93 @@ func runDeploy(cmd *cobra.Command, newClient ClientFactory) (err error) {
 	return f.Stamp()
 }
 
+// runDeployAll deploys all functions within the path: building and pushing
+// them concurrently, and then deploying them in order of their dependencies.
+func runDeployAll(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	cfg := newDeployConfig(cmd)
+	if err = cfg.Validate(cmd); err != nil {
+		return
+	}
+	if err = validateBatch(cmd, "all", "image", "builder-image", "domain", "git-url", "git-dir", "git-branch", "remote", "remote-storage-class", "pvc-size", "service-account"); err != nil {
+		return
+	}
+	root, err := filepath.Abs(cfg.Path)
+	if err != nil {
+		return
+	}
+	ff, dependencies, err := discoverBatch(root)
+	if err != nil {
+		return
+	}
+	cmd.SetContext(cfg.WithValues(cmd.Context()))
+
+	clientOptions, err := cfg.clientOptions()
+	if err != nil {
+		return
+	}
+	clientOptions = append(clientOptions, cfg.progressOptions(cmd.OutOrStdout())...)
+	client, done := newClient(ClientConfig{Verbose: cfg.Verbose, InsecureSkipVerify: cfg.RegistryInsecure}, clientOptions...)
+	defer done()
+	buildOptions, err := cfg.buildOptions()
+	if err != nil {
+		return
+	}
+	concurrency := viper.GetInt("concurrency")
+
+	// Build and push each function concurrently, as they are independent.
+	built, err := fn.Batch(cmd.Context(), ff, nil, concurrency, func(ctx context.Context, f fn.Function) (fn.Function, error) {
+		var err error
+		if f, err = f.ForEnvironment(cfg.Environment); err != nil {
+			return f, err
+		}
+		f = configureBatch(cfg.buildConfig, f)
+		if cmd.Flags().Changed("namespace") {
+			f.Namespace = cfg.Namespace
+		}
+		if f.Run.Envs, err = applyEnvs(f.Run.Envs, cfg.Env); err != nil {
+			return f, err
+		}
+		justBuilt, justPushed := false, false
+		if f, justBuilt, err = build(cmd, cfg.Build, f, client, buildOptions); err != nil {
+			return f, err
+		}
+		if cfg.Push {
+			if f, justPushed, err = client.Push(ctx, f); err != nil {
+				return f, err
+			}
+		}
+		if (justBuilt || justPushed) && f.Build.Image != "" {
+			f.Deploy.Image = f.Build.Image
+		}
+		return f, nil
+	})
+	if err != nil {
+		return
+	}
+
+	// Deploy each function once those on which it depends are deployed.
+	for i := range ff {
+		ff[i] = built[i].Function
+	}
+	results, err := fn.Batch(cmd.Context(), ff, dependencies, concurrency, func(ctx context.Context, f fn.Function) (fn.Function, error) {
+		if err := built[indexOfRoot(ff, f.Root)].Err; err != nil {
+			return f, err
+		}
+		f, err := client.Deploy(ctx, f, cfg.deployOptions()...)
+		if err != nil {
+			return f, err
+		}
+		if err = f.Write(); err != nil {
+			return f, err
+		}
+		return f, f.Stamp()
+	})
+	if err != nil {
+		return
+	}
+	return printBatchResults(cfg.messages(cmd), root, "deployed", results)
+}
+
 // build when flag == 'auto' and the function is out-of-date, or when the
 // flag value is explicitly truthy such as 'true' or '1'.  Error if flag
 // is neither 'auto' nor parseable as a boolean.  Return CLI-specific error
@@ -370,7 +545,7 @@ func build(cmd *cobra.Command, flag string, f fn.Function, client *fn.Client, bu
 	var err error
 	if flag == "auto" {
 		if f.Built() {
-			fmt.Fprintln(cmd.OutOrStdout(), "function up-to-date. Force rebuild with --build")
+			fmt.Fprintln(cmd.ErrOrStderr(), "function up-to-date. Force rebuild with --build")
 			return f, false, nil
 		} else {
 			if f, err = client.Build(cmd.Context(), f, buildOptions...); err != nil {
@@ -459,6 +634,10 @@ type deployConfig struct {
 	// Env variables.  May include removals using a "-"
 	Env []string
 
+	// Environment is the name of an environment defined by the function
+	// whose settings are to be used for this deployment.
+	Environment string
+
 	// Domain to use for the function's route.  Default is to let the cluster
 	// apply its default.  If configured to use domain matching, the given domain
 	// will be used.  This configuration, in short, is to configure the
@@ -529,6 +708,10 @@ type deployConfig struct {
 	// Timestamp the built contaienr with the current date and time.
 	// This is currently only supported by the Pack builder.
 	Timestamp bool
+
+	// Canary is the percent of traffic routed to the new revision, which
+	// overrides the function's rollout when set.
+	Canary *int64
 }
 
 // newDeployConfig creates a buildConfig populated from command flags and
@@ -538,6 +721,7 @@ func newDeployConfig(cmd *cobra.Command) deployConfig {
 		buildConfig:        newBuildConfig(),
 		Build:              viper.GetString("build"),
 		Env:                viper.GetStringSlice("env"),
+		Environment:        viper.GetString("environment"),
 		Domain:             viper.GetString("domain"),
 		GitBranch:          viper.GetString("git-branch"),
 		GitDir:             viper.GetString("git-dir"),
@@ -556,10 +740,23 @@ func newDeployConfig(cmd *cobra.Command) deployConfig {
 	if cfg.Env, err = cmd.Flags().GetStringArray("env"); err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error reading envs: %v", err)
 	}
+	if viper.IsSet("canary") {
+		canary := viper.GetInt64("canary")
+		cfg.Canary = &canary
+	}
 
 	return cfg
 }
 
+// deployOptions returns the options with which to deploy the function.
+func (c deployConfig) deployOptions() []fn.DeployOption {
+	oo := []fn.DeployOption{fn.WithDeploySkipBuildCheck(c.Build == "false")}
+	if c.Canary != nil {
+		oo = append(oo, fn.WithDeployCanary(*c.Canary))
+	}
+	return oo
+}
+
 // Configure the given function.  Updates a function struct with all
 // configurable values.  Note that the config already includes function's
 // current values, as they were passed through via flag defaults.
@@ -726,6 +923,23 @@ func (c deployConfig) Validate(cmd *cobra.Command) (err error) {
 		return errors.New("git settings (--git-url --git-dir and --git-branch) are only applicable when triggering remote deployments (--remote)")
 	}
 
+	// Canary is a percent of traffic, and can not be applied to a remote
+	// deployment, which is deployed by the cluster
+	if c.Canary != nil {
+		if *c.Canary < 0 || *c.Canary > 99 {
+			return fmt.Errorf("invalid --canary %v, the percent must be between 0 and 99", *c.Canary)
+		}
+		if c.Remote {
+			return errors.New("--canary is not supported when triggering remote deployments (--remote)")
+		}
+	}
+
+	// The progress of a remote deployment is that of its pipeline, which is
+	// not reported as events
+	if Format(c.Output) == JSON && c.Remote {
+		return errors.New("--output json is not supported when triggering remote deployments (--remote)")
+	}
+
 	// Git URL can contain at maximum one '#'
 	urlParts := strings.Split(c.GitURL, "#")
 	if len(urlParts) > 2 {
@@ -4,14 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/ory/viper"
 	"github.com/spf13/cobra"
+	"knative.dev/pkg/ptr"
 
 	"knative.dev/func/pkg/builders"
 	fn "knative.dev/func/pkg/functions"
@@ -1052,6 +1056,79 @@ func TestDeploy_Namespace(t *testing.T) {
 	}
 }
 
+// TestDeploy_Environment ensures that deploying with a named environment
+// uses the environment's settings and records the deployed instance under
+// the environment rather than the function's defaults.
+func TestDeploy_Environment(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f := fn.Function{Root: root, Runtime: "go", Registry: TestRegistry}
+	f, err := fn.New().Init(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Environments = fn.Environments{
+		"staging": {
+			Namespace: "staging",
+			Envs:      fn.Envs{{Name: ptr.String("LOG_LEVEL"), Value: ptr.String("debug")}},
+		},
+	}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deploy the function to its default namespace (from the test kubeconfig)
+	cmd := NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deploy the function to the staging environment.
+	// The environment is also set as an environment variable because flag
+	// defaults are calculated from the targeted function prior to parsing.
+	t.Setenv("FUNC_ENVIRONMENT", "staging")
+	var deployed fn.Function
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		deployed = f
+		return fn.DeploymentResult{Namespace: f.Namespace}, nil
+	}
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--environment=staging"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if deployed.Namespace != "staging" {
+		t.Fatalf("expected deploy to namespace 'staging', got %q", deployed.Namespace)
+	}
+	if !strings.Contains(deployed.Run.Envs.String(), "LOG_LEVEL=debug") {
+		t.Fatalf("expected environment envs to be deployed, got %q", deployed.Run.Envs)
+	}
+
+	// Both instances are recorded separately
+	if f, err = fn.NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	if f.Deploy.Namespace != "func" {
+		t.Fatalf("expected default instance in namespace 'func', got %q", f.Deploy.Namespace)
+	}
+	if ns := f.Environments["staging"].Deploy.Namespace; ns != "staging" {
+		t.Fatalf("expected staging instance in namespace 'staging', got %q", ns)
+	}
+	if len(f.Run.Envs) != 0 {
+		t.Fatalf("environment envs were persisted to the function: %v", f.Run.Envs)
+	}
+
+	// Undefined environments are an error
+	t.Setenv("FUNC_ENVIRONMENT", "")
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--environment=prod"})
+	if err := cmd.Execute(); !errors.Is(err, fn.ErrEnvironmentNotFound) {
+		t.Fatalf("expected ErrEnvironmentNotFound, got %v", err)
+	}
+}
+
 // TestDeploy_NamespaceDefaultsToK8sContext ensures that when not specified, a
 // users's active kubernetes context is used for the namespace if available.
 func TestDeploy_NamespaceDefaultsToK8sContext(t *testing.T) {
@@ -2171,3 +2248,66 @@ func Test_isDigested(t *testing.T) {
 		t.Fatal("did not report image reference has digest")
 	}
 }
+
+// TestDeploy_All ensures that --all deploys every function within the path in
+// order of the dependencies declared by the workspace file, that a function
+// which fails does not prevent unrelated functions from deploying, that its
+// dependents are skipped, and that a summary of each is printed.
+func TestDeploy_All(t *testing.T) {
+	root := FromTempDirectory(t)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		if _, err := fn.New().Init(fn.Function{Root: filepath.Join(root, name), Name: name, Runtime: "go", Registry: TestRegistry}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	workspace := "functions:\n  - path: a\n    dependsOn: [b]\n  - path: b\n  - path: c\n  - path: d\n    dependsOn: [c]\n"
+	if err := os.WriteFile(fn.WorkspaceFile, []byte(workspace), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	builder := mock.NewBuilder()
+	builder.BuildFn = func(f fn.Function) error {
+		if f.Name == "c" {
+			return errors.New("build failed")
+		}
+		return nil
+	}
+	var (
+		mu       sync.Mutex
+		deployed []string
+	)
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		deployed = append(deployed, f.Name)
+		return fn.DeploymentResult{Namespace: f.Namespace}, nil
+	}
+
+	out := &strings.Builder{}
+	cmd := NewDeployCmd(NewTestClient(
+		fn.WithBuilder(builder),
+		fn.WithDeployer(deployer),
+		fn.WithPusher(mock.NewPusher()),
+	))
+	cmd.SetArgs([]string{"--all", "--namespace", "ns"})
+	cmd.SetOut(out)
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "2 of 4") {
+		t.Fatalf("expected two of four functions to fail, got %v", err)
+	}
+
+	if len(deployed) != 2 || deployed[0] != "b" || deployed[1] != "a" {
+		t.Fatalf("expected b then a to be deployed, got %v", deployed)
+	}
+	for _, row := range [][]string{{"a", "deployed"}, {"b", "deployed"}, {"c", "failed", "build failed"}, {"d", "skipped"}} {
+		if !regexp.MustCompile(`(?m)^` + strings.Join(row, `\s+.*`)).MatchString(out.String()) {
+			t.Errorf("expected a summary row %v, got:\n%v", row, out.String())
+		}
+	}
+
+	// Flags which configure a single function can not be used.
+	cmd.SetArgs([]string{"--all", "--image", "example.com/alice/f"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected --image with --all to error")
+	}
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewDiffCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show the differences between a function and its deployed instance",
+		Long: `
+NAME
+	{{rootCmdUse}} diff - Show the differences between a function and its deployed instance
+
+SYNOPSIS
+	{{rootCmdUse}} diff [--environment] [-o|--output] [-p|--path] [-v|--verbose]
+
+DESCRIPTION
+	Compares the function in the current directory, or in the directory
+	specified with --path, with its instance deployed to the cluster, and
+	prints each field which differs.
+
+	The fields compared are those defined by the function: its image, envs,
+	volumes, labels, annotations, scale and resource options, service
+	account and subscriptions.  Fields set by the cluster are ignored, such
+	that a function which has not changed since it was deployed shows no
+	differences.
+
+	The command exits with a non-zero status if there are differences, such
+	that it may be used to detect drift, for example in CI.
+
+	Note that the image compared is that of the last deployment recorded in
+	func.yaml.  Changes to the source of the function which have not been
+	built are not detected.
+`,
+		Example: `
+# Show the differences between the function in the current directory and
+# its deployed instance
+{{rootCmdUse}} diff
+
+# Show the differences with the function as deployed to its "staging"
+# environment, as JSON
+{{rootCmdUse}} diff --environment staging --output json
+`,
+		SuggestFor:   []string{"dif", "drift"},
+		SilenceUsage: true, // no usage dump on drift
+		PreRunE:      bindEnv("environment", "output", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runDiff(cmd, newClient)
+		},
+	}
+
+	// Config
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	// Flags
+	cmd.Flags().StringP("output", "o", "human", "Output format (human|json|yaml) ($FUNC_OUTPUT)")
+	addEnvironmentFlag(cmd)
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	if err := cmd.RegisterFlagCompletionFunc("environment", CompleteEnvironmentList); err != nil {
+		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
+	}
+
+	return cmd
+}
+
+func runDiff(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	cfg, err := newDiffConfig()
+	if err != nil {
+		return
+	}
+
+	f, err := fn.NewFunction(cfg.Path)
+	if err != nil {
+		return
+	}
+	if f, err = f.ForEnvironment(cfg.Environment); err != nil {
+		return
+	}
+
+	client, done := newClient(ClientConfig{Verbose: cfg.Verbose})
+	defer done()
+
+	dd, err := client.Diff(cmd.Context(), f)
+	if err != nil {
+		return
+	}
+	if err = writeDifferences(cmd.OutOrStdout(), dd, cfg.Output); err != nil {
+		return
+	}
+	if len(dd) > 0 {
+		return fmt.Errorf("function %q differs from its deployed instance in %d field(s)", f.Name, len(dd))
+	}
+	return
+}
+
+// writeDifferences to the output in the given format.
+func writeDifferences(w io.Writer, dd []fn.Difference, output string) error {
+	switch Format(output) {
+	case Human:
+		if len(dd) == 0 {
+			fmt.Fprintln(w, "No differences")
+			return nil
+		}
+		for _, d := range dd {
+			fmt.Fprintln(w, d.Field)
+			fmt.Fprintf(w, "  func.yaml: %v\n", orNone(d.Desired))
+			fmt.Fprintf(w, "  deployed:  %v\n", orNone(d.Live))
+		}
+		return nil
+	case JSON:
+		if dd == nil {
+			dd = []fn.Difference{}
+		}
+		return json.NewEncoder(w).Encode(dd)
+	case YAML:
+		if dd == nil {
+			dd = []fn.Difference{}
+		}
+		return yaml.NewEncoder(w).Encode(dd)
+	default:
+		return fmt.Errorf("format not recognized: %v", output)
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+type diffConfig struct {
+	Environment string
+	Output      string
+	Path        string
+	Verbose     bool
+}
+
+func newDiffConfig() (cfg diffConfig, err error) {
+	cfg = diffConfig{
+		Environment: viper.GetString("environment"),
+		Output:      viper.GetString("output"),
+		Path:        viper.GetString("path"),
+		Verbose:     viper.GetBool("verbose"),
+	}
+	switch Format(cfg.Output) {
+	case Human, JSON, YAML:
+	default:
+		err = fmt.Errorf("unsupported output format %q (supported: human, json, yaml)", cfg.Output)
+	}
+	return
+}
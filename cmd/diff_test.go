@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestDiff ensures that the differences between the function and its deployed
+// instance are printed, and that the command fails if there are any.
+func TestDiff(t *testing.T) {
+	root := FromTempDirectory(t)
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go", Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Deploy.Namespace = "prod"
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	// No differences
+	differ := mock.NewDiffer()
+	out := bytes.Buffer{}
+	cmd := NewDiffCmd(NewTestClient(fn.WithDiffer(differ)))
+	cmd.SetArgs([]string{})
+	cmd.SetOut(&out)
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !differ.DiffInvoked {
+		t.Fatal("differ was not invoked")
+	}
+	if !strings.Contains(out.String(), "No differences") {
+		t.Fatalf("unexpected output: %v", out.String())
+	}
+
+	// Differences
+	expected := []fn.Difference{{Field: "image", Desired: "example.com/alice/a:2", Live: "example.com/alice/a:1"}}
+	differ.DiffFn = func(_ context.Context, df fn.Function) ([]fn.Difference, error) {
+		if df.Name != f.Name {
+			t.Fatalf("expected the function %q to be diffed, got %q", f.Name, df.Name)
+		}
+		return expected, nil
+	}
+	out.Reset()
+	cmd = NewDiffCmd(NewTestClient(fn.WithDiffer(differ)))
+	cmd.SetArgs([]string{"--output", "json"})
+	cmd.SetOut(&out)
+	if err = cmd.Execute(); err == nil {
+		t.Fatal("expected an error when the function differs from its deployed instance")
+	}
+	var dd []fn.Difference
+	if err = json.Unmarshal(out.Bytes(), &dd); err != nil {
+		t.Fatal(err)
+	}
+	if len(dd) != 1 || dd[0] != expected[0] {
+		t.Fatalf("unexpected differences %v", dd)
+	}
+}
+
+// TestDiff_NotDeployed ensures that a function which has not been deployed
+// can not be diffed.
+func TestDiff_NotDeployed(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go", Registry: TestRegistry}); err != nil {
+		t.Fatal(err)
+	}
+	differ := mock.NewDiffer()
+	cmd := NewDiffCmd(NewTestClient(fn.WithDiffer(differ)))
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error diffing a function which has not been deployed")
+	}
+	if differ.DiffInvoked {
+		t.Fatal("differ should not be invoked for a function which has not been deployed")
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewEmitCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "emit",
+		Short: "Emit a stream of synthetic CloudEvents to a function",
+		Long: `
+NAME
+	{{rootCmdUse}} emit - Emit a stream of synthetic CloudEvents to a function
+
+SYNOPSIS
+	{{rootCmdUse}} emit [-t|--target] [--source] [--type] [--content-type]
+	             [--data] [--file] [--rate] [--count] [-p|--path]
+	             [-i|--insecure] [-v|--verbose]
+
+DESCRIPTION
+	Emits a stream of CloudEvents to the function, as would be produced by an
+	event source such as a PingSource, and reports the delivery of each
+	event and the function's response event, if any.
+
+	Events are emitted at the given --rate (per second) until --count events
+	have been emitted, or until interrupted if --count is 0.
+
+	Event Data
+	  The data of each event is rendered from the --data template, which may
+	  refer to the event's sequence number (starting at 1), ID and time using
+	  .Sequence, .ID and .Time in double braces.  Alternatively, the
+	  --file flag may be provided one or more times with fixture files whose
+	  contents are used as the data of each event in turn.
+
+	Emit Target
+	  By default events are emitted to the function running locally (see
+	  '{{rootCmdUse}} run').  As with '{{rootCmdUse}} invoke', the --target
+	  flag accepts the values "local", "remote", or a URL.
+
+EXAMPLES
+
+	o Emit a single event to the function running locally
+	  $ {{rootCmdUse}} emit
+
+	o Emit 100 events of a given type, 10 per second
+	  $ {{rootCmdUse}} emit --type com.example.order --rate 10 --count 100
+
+	o Emit events using the contents of two fixtures in turn until interrupted
+	  $ {{rootCmdUse}} emit --file first.json --file second.json --count 0
+`,
+		SuggestFor: []string{"emti", "emmit"},
+		PreRunE:    bindEnv("path", "target", "source", "type", "content-type", "data", "file", "rate", "count", "insecure", "verbose"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runEmit(cmd, newClient)
+		},
+	}
+
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	cmd.Flags().StringP("target", "t", fn.EnvironmentLocal, "Function instance to which events are emitted.  Can be 'local', 'remote' or a URL. ($FUNC_TARGET)")
+	cmd.Flags().String("source", fn.DefaultInvokeSource, "Source of the events. ($FUNC_SOURCE)")
+	cmd.Flags().String("type", fn.DefaultInvokeType, "Type of the events. ($FUNC_TYPE)")
+	cmd.Flags().String("content-type", fn.DefaultInvokeContentType, "Content Type of the data of the events. ($FUNC_CONTENT_TYPE)")
+	cmd.Flags().String("data", fn.DefaultInvokeData, "Template of the data of each event. ($FUNC_DATA)")
+	cmd.Flags().StringArray("file", []string{}, "Fixture file whose contents are the data of an event.  May be provided multiple times, to be used in turn.  Overrides --data. ($FUNC_FILE)")
+	cmd.Flags().Float64("rate", fn.DefaultEmitRate, "Events emitted per second. ($FUNC_RATE)")
+	cmd.Flags().Int("count", 1, "Number of events to emit, or 0 to emit until interrupted. ($FUNC_COUNT)")
+	cmd.Flags().BoolP("insecure", "i", false, "Allow insecure server connections when using SSL. ($FUNC_INSECURE)")
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	return cmd
+}
+
+func runEmit(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	cfg, err := newEmitConfig(cmd)
+	if err != nil {
+		return
+	}
+	client, done := newClient(ClientConfig{Verbose: cfg.Verbose, InsecureSkipVerify: cfg.Insecure})
+	defer done()
+
+	var (
+		out               = cmd.OutOrStdout()
+		delivered, failed int
+	)
+	report := func(r fn.EmitResult) {
+		if r.Err != nil {
+			failed++
+			fmt.Fprintf(out, "✗ event %v (%v) not delivered. %v\n", r.Sequence, r.ID, r.Err)
+			return
+		}
+		delivered++
+		fmt.Fprintf(out, "✓ event %v (%v) delivered\n", r.Sequence, r.ID)
+		if r.Response != "" {
+			fmt.Fprintf(out, "  Response:\n  %v\n", strings.ReplaceAll(strings.TrimSpace(r.Response), "\n", "\n  "))
+		}
+	}
+	if err = client.Emit(cmd.Context(), cfg.Path, cfg.Target, cfg.EmitSpec, report); err != nil {
+		return
+	}
+	fmt.Fprintf(out, "Emitted %v events: %v delivered, %v failed\n", delivered+failed, delivered, failed)
+	if failed > 0 {
+		return fmt.Errorf("%v of %v events were not delivered", failed, delivered+failed)
+	}
+	return
+}
+
+type emitConfig struct {
+	fn.EmitSpec
+	Path     string
+	Target   string
+	Insecure bool
+	Verbose  bool
+}
+
+func newEmitConfig(cmd *cobra.Command) (cfg emitConfig, err error) {
+	cfg = emitConfig{
+		EmitSpec: fn.EmitSpec{
+			Source:      viper.GetString("source"),
+			Type:        viper.GetString("type"),
+			ContentType: viper.GetString("content-type"),
+			Data:        viper.GetString("data"),
+			Rate:        viper.GetFloat64("rate"),
+			Count:       viper.GetInt("count"),
+		},
+		Path:     viper.GetString("path"),
+		Target:   viper.GetString("target"),
+		Insecure: viper.GetBool("insecure"),
+		Verbose:  viper.GetBool("verbose"),
+	}
+	// NOTE: viper.GetStringSlice does not parse string arrays (see runConfig)
+	cfg.Files, err = cmd.Flags().GetStringArray("file")
+	return
+}
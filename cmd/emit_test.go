@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestEmit ensures that the events emitted are delivered to the target, and
+// that their delivery is reported.
+func TestEmit(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+
+	received := make(chan cloudevents.Event, 10)
+	p, err := cloudevents.NewHTTP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := cloudevents.NewHTTPReceiveHandler(context.Background(), p, func(e cloudevents.Event) { received <- e })
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	out := bytes.Buffer{}
+	cmd := NewEmitCmd(NewTestClient())
+	cmd.SetArgs([]string{"--target", s.URL, "--type", "com.example.tick", "--count", "2", "--rate", "100"})
+	cmd.SetOut(&out)
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 2 {
+		t.Fatalf("expected 2 events, got %v", len(received))
+	}
+	if e := <-received; e.Type() != "com.example.tick" {
+		t.Fatalf("unexpected event type %q", e.Type())
+	}
+	if !strings.Contains(out.String(), "Emitted 2 events: 2 delivered, 0 failed") {
+		t.Fatalf("unexpected output:\n%v", out.String())
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"knative.dev/func/pkg/config"
 	"knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/k8s"
+	"knative.dev/func/pkg/oci"
 )
 
 var format string = "json"
@@ -121,6 +122,7 @@ func runEnvironment(cmd *cobra.Command, newClient ClientFactory, v *Version) (er
 	builderimagesdefault := make(map[string]map[string]string)
 	builderimagesdefault["s2i"] = s2i.DefaultBuilderImages
 	builderimagesdefault["buildpacks"] = buildpacks.DefaultBuilderImages
+	builderimagesdefault["host"] = oci.DefaultBaseImages
 
 	environment := Environment{
 		Version:              v.String(),
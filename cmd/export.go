@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/knative"
+)
+
+func NewExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the manifests with which a function is deployed",
+		Long: `
+NAME
+	{{rootCmdUse}} export - Export the manifests with which a function is deployed
+
+SYNOPSIS
+	{{rootCmdUse}} export [--format] [--output-dir] [-i|--image] [-n|--namespace]
+	             [--image-pull-secret] [--environment] [-p|--path] [-v|--verbose]
+
+DESCRIPTION
+	Writes the Kubernetes manifests with which the function would be deployed:
+	its Knative Service and the Triggers of its subscriptions, as YAML, a Helm
+	chart, or a kustomize base and overlays.  This allows the function to be
+	deployed by other means, such as by committing the manifests to a
+	repository applied by a GitOps tool.
+
+	The cluster is not contacted, and the function is not built.  The image
+	exported is that provided with --image, or otherwise that which was last
+	deployed, that which was last built, or that which would be built, in that
+	order.  An image with a digest is recommended: the manifests of a function
+	are the same each time they are exported, such that a new revision is
+	deployed only when they change, such as when the image does.
+
+	Formats
+	  The --format flag selects the format of the export:
+	  yaml       The manifests, written to stdout, or with --output-dir to a
+	             file for each in the given directory.
+	  helm       A Helm chart, written to --output-dir, whose values are the
+	             function's image, namespace, image pull secrets, envs,
+	             scale and resource options, volumes and subscriptions.
+	  kustomize  A kustomize base of the manifests, written to the "base"
+	             directory of --output-dir, and an overlay for each
+	             environment defined in func.yaml in "overlays/NAME", which
+	             sets the namespace and image of the environment and patches
+	             its envs, labels and options.  The image of the base is set
+	             by its kustomization, such that a new image digest may be
+	             set with 'kustomize edit set image'.
+
+	Referenced Resources
+	  Secrets, ConfigMaps and PersistentVolumeClaims referenced by the
+	  function's environment variables and volumes are not exported, but are
+	  listed in a comment, and must exist in the namespace.  The
+	  --image-pull-secret flag adds a Secret with which the function's image
+	  is pulled from a private registry, and may be provided multiple times.
+
+EXAMPLES
+
+	o Export the manifests of the function in the current directory
+	  $ {{rootCmdUse}} export
+
+	o Export the manifests of the function as deployed to its "prod"
+	  environment into a directory of a GitOps repository
+	  $ {{rootCmdUse}} export --environment prod --output-dir ../gitops/myfunc
+
+	o Export the manifests with an image pulled from a private registry
+	  $ {{rootCmdUse}} export --image-pull-secret regcred
+
+	o Export the function as a Helm chart
+	  $ {{rootCmdUse}} export --format helm --output-dir charts/myfunc
+
+	o Export the function as a kustomize base, with an overlay for each of
+	  its environments
+	  $ {{rootCmdUse}} export --format kustomize --output-dir deploy/myfunc
+`,
+		SuggestFor: []string{"exprot", "expotr"},
+		PreRunE:    bindEnv("format", "output-dir", "image", "namespace", "image-pull-secret", "environment", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runExport(cmd)
+		},
+	}
+
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	// Flag defaults are taken from the function as targeted at the effective
+	// environment (if any).
+	f := functionContext()
+
+	cmd.Flags().String("format", "yaml", "Format of the export (yaml|helm|kustomize). ($FUNC_FORMAT)")
+	cmd.Flags().String("output-dir", "", "Directory to which the export is written.  Required by the helm and kustomize formats. ($FUNC_OUTPUT_DIR)")
+	cmd.Flags().StringP("image", "i", f.Image, "Image of the function.  Defaults to the image last deployed or built. ($FUNC_IMAGE)")
+	cmd.Flags().StringP("namespace", "n", defaultNamespace(f, false), "Namespace of the exported resources. ($FUNC_NAMESPACE)")
+	cmd.Flags().StringArray("image-pull-secret", []string{}, "Secret with which the function's image is pulled.  May be provided multiple times. ($FUNC_IMAGE_PULL_SECRET)")
+	addEnvironmentFlag(cmd)
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	if err := cmd.RegisterFlagCompletionFunc("environment", CompleteEnvironmentList); err != nil {
+		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
+	}
+
+	return cmd
+}
+
+func runExport(cmd *cobra.Command) (err error) {
+	cfg, err := newExportConfig(cmd)
+	if err != nil {
+		return
+	}
+	if err = cfg.Validate(cmd); err != nil {
+		return
+	}
+	f, err := fn.NewFunction(cfg.Path)
+	if err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return fn.NewErrNotInitialized(f.Root)
+	}
+	if f, err = f.ForEnvironment(cfg.Environment); err != nil {
+		return
+	}
+	if f, err = cfg.Configure(f); err != nil {
+		return
+	}
+
+	m, err := knative.GenerateManifests(f, cfg.ImagePullSecrets)
+	if err != nil {
+		return
+	}
+	switch {
+	case cfg.Format == "helm":
+		err = m.WriteHelmChart(cfg.OutputDir, f)
+	case cfg.Format == "kustomize":
+		var overlays map[string]knative.Manifests
+		if overlays, err = cfg.overlays(f); err != nil {
+			return
+		}
+		err = m.WriteKustomization(cfg.OutputDir, overlays)
+	case cfg.OutputDir != "":
+		err = m.WriteDir(cfg.OutputDir)
+	default:
+		return m.Write(cmd.OutOrStdout())
+	}
+	if err == nil && cfg.Verbose {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Exported %v to %v\n", cfg.Format, cfg.OutputDir)
+	}
+	return
+}
+
+// overlays returns the manifests of each environment of the function, by
+// name, for the overlays of a kustomize export.  Each is exported with the
+// image of its environment, determined as is that of the function, and
+// within the namespace of its environment, if any.
+func (c exportConfig) overlays(f fn.Function) (overlays map[string]knative.Manifests, err error) {
+	base, err := fn.NewFunction(f.Root)
+	if err != nil {
+		return
+	}
+	overlays = make(map[string]knative.Manifests, len(base.Environments))
+	for _, name := range base.Environments.Names() {
+		var ef fn.Function
+		if ef, err = base.ForEnvironment(name); err != nil {
+			return
+		}
+		if ef.Namespace == "" {
+			ef.Namespace = ef.Deploy.Namespace
+		}
+		if ef.Namespace == "" {
+			ef.Namespace = f.Namespace
+		}
+		if ef, err = exportImage(ef, ""); err != nil {
+			return
+		}
+		if overlays[name], err = knative.GenerateManifests(ef, c.ImagePullSecrets); err != nil {
+			return
+		}
+	}
+	return
+}
+
+type exportConfig struct {
+	Format           string
+	OutputDir        string
+	Image            string
+	Namespace        string
+	ImagePullSecrets []string
+	Environment      string
+	Path             string
+	Verbose          bool
+}
+
+func newExportConfig(cmd *cobra.Command) (cfg exportConfig, err error) {
+	cfg = exportConfig{
+		Format:      viper.GetString("format"),
+		OutputDir:   viper.GetString("output-dir"),
+		Image:       viper.GetString("image"),
+		Namespace:   viper.GetString("namespace"),
+		Environment: viper.GetString("environment"),
+		Path:        viper.GetString("path"),
+		Verbose:     viper.GetBool("verbose"),
+	}
+	// NOTE: viper.GetStringSlice does not parse string arrays (see runConfig)
+	cfg.ImagePullSecrets, err = cmd.Flags().GetStringArray("image-pull-secret")
+	return
+}
+
+// Validate the config.
+func (c exportConfig) Validate(cmd *cobra.Command) error {
+	switch c.Format {
+	case "yaml":
+	case "helm", "kustomize":
+		if c.OutputDir == "" {
+			return fmt.Errorf("the %v format requires --output-dir", c.Format)
+		}
+	default:
+		return fmt.Errorf("unsupported format %q.  Supported formats are yaml, helm and kustomize", c.Format)
+	}
+	if c.Format == "kustomize" && cmd.Flags().Changed("environment") {
+		return errors.New("--environment can not be used with the kustomize format, whose overlays are the function's environments")
+	}
+	return nil
+}
+
+// Configure the function with the namespace and image to be exported.
+func (c exportConfig) Configure(f fn.Function) (fn.Function, error) {
+	f.Namespace = c.Namespace
+	return exportImage(f, c.Image)
+}
+
+// exportImage returns the function with the image to be exported: that
+// requested, or otherwise that last deployed, that last built, or that which
+// would be built, in that order.
+func exportImage(f fn.Function, image string) (fn.Function, error) {
+	switch {
+	case image != "":
+		f.Deploy.Image = image
+	case f.Deploy.Image != "":
+	case f.Build.Image != "":
+		f.Deploy.Image = f.Build.Image
+	default:
+		image, err := f.ImageName()
+		if err != nil {
+			return f, fmt.Errorf("unable to determine the image of the function.  Provide --image, or configure a registry. %w", err)
+		}
+		f.Deploy.Image = image
+	}
+	return f, nil
+}
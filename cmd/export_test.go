@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestExport ensures that the manifests of the function are written to stdout
+// or to a directory, using the image which would be built if none was
+// deployed or requested.
+func TestExport(t *testing.T) {
+	root := FromTempDirectory(t)
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go", Registry: "example.com/alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Deploy.Subscriptions = []fn.KnativeSubscription{{Source: "default"}}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := bytes.Buffer{}
+	cmd := NewExportCmd()
+	cmd.SetArgs([]string{"--namespace", "prod"})
+	cmd.SetOut(&out)
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"kind: Service\n",
+		"namespace: prod\n",
+		"image: example.com/alice/" + f.Name + ":latest\n",
+		"kind: Trigger\n",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("expected output to contain %q:\n%v", s, out.String())
+		}
+	}
+
+	// An explicit image, written to a directory
+	dir := filepath.Join(t.TempDir(), "manifests")
+	cmd = NewExportCmd()
+	cmd.SetArgs([]string{"--image", "example.com/alice/other@sha256:deadbeef", "--output-dir", dir})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	bb, err := os.ReadFile(filepath.Join(dir, "service-"+f.Name+".yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bb), "image: example.com/alice/other@sha256:deadbeef\n") {
+		t.Fatalf("unexpected service manifest:\n%s", bb)
+	}
+
+	// Unsupported format
+	cmd = NewExportCmd()
+	cmd.SetArgs([]string{"--format", "json"})
+	if err = cmd.Execute(); err == nil {
+		t.Fatal("expected an error exporting an unsupported format")
+	}
+}
+
+// TestExport_Formats ensures that a function is exported as a Helm chart, or
+// as a kustomize base with an overlay for each of its environments.
+func TestExport_Formats(t *testing.T) {
+	root := FromTempDirectory(t)
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go", Registry: "example.com/alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Environments = fn.Environments{"staging": fn.EnvironmentSpec{Namespace: "staging"}}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	// helm and kustomize require a directory
+	cmd := NewExportCmd()
+	cmd.SetArgs([]string{"--format", "helm"})
+	if err = cmd.Execute(); err == nil {
+		t.Fatal("expected an error exporting a chart without --output-dir")
+	}
+
+	dir := t.TempDir()
+	cmd = NewExportCmd()
+	cmd.SetArgs([]string{"--format", "helm", "--output-dir", filepath.Join(dir, "chart")})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	cmd = NewExportCmd()
+	cmd.SetArgs([]string{"--format", "kustomize", "--output-dir", filepath.Join(dir, "kustomize")})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{
+		"chart/Chart.yaml",
+		"chart/values.yaml",
+		"chart/templates/service.yaml",
+		"kustomize/base/kustomization.yaml",
+		"kustomize/overlays/staging/kustomization.yaml",
+	} {
+		if _, err = os.Stat(filepath.Join(dir, path)); err != nil {
+			t.Errorf("expected %v to be exported. %v", path, err)
+		}
+	}
+	bb, err := os.ReadFile(filepath.Join(dir, "kustomize/overlays/staging/kustomization.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bb), "namespace: staging\n") {
+		t.Fatalf("expected the overlay to set the namespace of the environment:\n%s", bb)
+	}
+
+	// Overlays are the environments, so one can not be selected
+	cmd = NewExportCmd()
+	cmd.SetArgs([]string{"--format", "kustomize", "--output-dir", dir, "--environment", "staging"})
+	if err = cmd.Execute(); err == nil {
+		t.Fatal("expected an error exporting a kustomization for an environment")
+	}
+}
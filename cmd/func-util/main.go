@@ -5,6 +5,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -86,8 +87,8 @@ func scaffold(ctx context.Context) error {
 		return fmt.Errorf("cannot load func project: %w", err)
 	}
 
-	if f.Runtime != "go" {
-		// Scaffolding is for now supported/needed only for Go.
+	if f.Runtime != "go" && f.Runtime != "python" {
+		// Scaffolding is for now supported/needed only for Go and Python.
 		return nil
 	}
 
@@ -100,6 +101,11 @@ func scaffold(ctx context.Context) error {
 	_ = os.RemoveAll(appRoot)
 
 	err = scaffolding.Write(appRoot, f.Root, f.Runtime, f.Invoke, embeddedRepo.FS())
+	if f.Runtime == "python" && errors.Is(err, scaffolding.ErrSignatureNotFound) {
+		// Python functions which implement none of the known signatures
+		// (such as those served by gunicorn) are run by the S2I image.
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("cannot write the scaffolding: %w", err)
 	}
@@ -108,6 +114,15 @@ func scaffold(ctx context.Context) error {
 		return fmt.Errorf("unable to create .s2i bin dir. %w", err)
 	}
 
+	// Go functions are built from the scaffolding by their assembler, and
+	// Python functions are started by it by their run script.
+	if f.Runtime == "python" {
+		if err := os.WriteFile(filepath.Join(f.Root, ".s2i", "bin", "run"), []byte(s2i.PythonRunner), 0755); err != nil {
+			return fmt.Errorf("unable to write python run script. %w", err)
+		}
+		return nil
+	}
+
 	if err := os.WriteFile(filepath.Join(f.Root, ".s2i", "bin", "assemble"), []byte(s2i.GoAssembler), 0755); err != nil {
 		return fmt.Errorf("unable to write go assembler. %w", err)
 	}
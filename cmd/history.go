@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List the deployments of a function",
+		Long: `
+NAME
+	{{rootCmdUse}} history - List the deployments of a function
+
+SYNOPSIS
+	{{rootCmdUse}} history [--environment] [-o|--output] [-p|--path] [-v|--verbose]
+
+DESCRIPTION
+	Lists the deployments of the function in the current directory, or in the
+	directory specified with --path, oldest first.
+
+	Each deployment is recorded with the image deployed, the time at which it
+	was deployed, the git revision of the function's source, and its
+	func.yaml.  Any of them may be redeployed with '{{rootCmdUse}} rollback'.
+
+	The history is kept in the .func directory of the function, and is
+	mirrored, without the func.yaml of each deployment, as the annotation
+	function.knative.dev/history of the deployed Knative Service.  Only the
+	latest 10 deployments are retained.  Functions deployed remotely
+	(--remote) are not recorded.
+
+	Each environment of the function has its own history, kept in
+	.func/environments/<name>, which is listed with --environment.
+`,
+		Example: `
+# List the deployments of the function in the current directory
+{{rootCmdUse}} history
+
+# List the deployments as JSON, including the func.yaml of each
+{{rootCmdUse}} history --output json
+
+# List the deployments of the function's "staging" environment
+{{rootCmdUse}} history --environment staging
+`,
+		SuggestFor: []string{"hist", "revisions"},
+		PreRunE:    bindEnv("environment", "output", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runHistory(cmd)
+		},
+	}
+
+	// Config
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	// Flags
+	addEnvironmentFlag(cmd)
+	cmd.Flags().StringP("output", "o", "human", "Output format (human|json|yaml) ($FUNC_OUTPUT)")
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	if err := cmd.RegisterFlagCompletionFunc("environment", CompleteEnvironmentList); err != nil {
+		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
+	}
+
+	return cmd
+}
+
+func runHistory(cmd *cobra.Command) (err error) {
+	var (
+		path   = viper.GetString("path")
+		output = viper.GetString("output")
+	)
+	f, err := fn.NewFunction(path)
+	if err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return fn.NewErrNotInitialized(f.Root)
+	}
+	if f, err = f.ForEnvironment(viper.GetString("environment")); err != nil {
+		return
+	}
+	h, err := f.History()
+	if err != nil {
+		return
+	}
+	return writeHistory(cmd.OutOrStdout(), h, output)
+}
+
+// writeHistory to the output in the given format.
+func writeHistory(w io.Writer, h fn.History, output string) error {
+	switch Format(output) {
+	case Human:
+		if len(h) == 0 {
+			fmt.Fprintln(w, "No deployments")
+			return nil
+		}
+		// minwidth, tabwidth, padding, padchar, flags
+		tabWriter := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		defer tabWriter.Flush()
+
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\n", "NUMBER", "DEPLOYED", "IMAGE", "REVISION", "DESCRIPTION")
+		for _, d := range h {
+			revision := d.Revision
+			if len(revision) > 7 {
+				revision = revision[:7]
+			}
+			description := "Deployed"
+			if d.RollbackTo != 0 {
+				description = fmt.Sprintf("Rolled back to %v", d.RollbackTo)
+			}
+			fmt.Fprintf(tabWriter, "%v\t%s\t%s\t%s\t%s\n", d.Number, d.Timestamp.Local().Format("2006-01-02 15:04:05"), d.Image, revision, description)
+		}
+		return nil
+	case JSON:
+		return json.NewEncoder(w).Encode(h)
+	case YAML:
+		return yaml.NewEncoder(w).Encode(h)
+	default:
+		return fmt.Errorf("format not recognized: %v", output)
+	}
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestHistory ensures that the deployments of a function are listed.
+func TestHistory(t *testing.T) {
+	root := FromTempDirectory(t)
+	client := fn.New(fn.WithDeployer(mock.NewDeployer()))
+	f, err := client.Init(fn.Function{Root: root, Runtime: "go", Namespace: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := bytes.Buffer{}
+	cmd := NewHistoryCmd()
+	cmd.SetArgs([]string{})
+	cmd.SetOut(&out)
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No deployments") {
+		t.Fatalf("unexpected output: %v", out.String())
+	}
+
+	f.Deploy.Image = "example.com/alice/f@sha256:deadbeef"
+	if _, err = client.Deploy(context.Background(), f, fn.WithDeploySkipBuildCheck(true)); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	cmd = NewHistoryCmd()
+	cmd.SetArgs([]string{})
+	cmd.SetOut(&out)
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "example.com/alice/f@sha256:deadbeef") {
+		t.Fatalf("expected the deployment to be listed:\n%v", out.String())
+	}
+}
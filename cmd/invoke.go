@@ -23,7 +23,7 @@ NAME
 	{{rootCmdUse}} invoke - test a function by invoking it with test data
 
 SYNOPSIS
-	{{rootCmdUse}} invoke [-t|--target] [-f|--format]
+	{{rootCmdUse}} invoke [-t|--target] [--environment] [-f|--format]
 	             [--id] [--source] [--type] [--data] [--file] [--content-type]
 	             [-s|--save] [-p|--path] [-i|--insecure] [-c|--confirm] [-v|--verbose]
 
@@ -52,6 +52,9 @@ DESCRIPTION
 	    {{rootCmdUse}} invoke --target=remote
 	  To target an arbitrary endpoint, provide a URL:
 	    {{rootCmdUse}} invoke --target=https://myfunction.example.com
+	  To target the function as deployed to a named environment defined in
+	  its func.yaml (see {{rootCmdUse}} deploy --environment):
+	    {{rootCmdUse}} invoke --environment=staging
 
 	Invocation Data
 	  Providing a filename in the --file flag will base64 encode its contents
@@ -80,6 +83,9 @@ EXAMPLES
 	  $ {{rootCmdUse}} deploy
 	  $ {{rootCmdUse}} invoke
 
+	o Invoke the function as deployed to its "staging" environment:
+	  $ {{rootCmdUse}} invoke --environment staging
+
 	o Invoke a remote (deployed) function when it is already running locally:
 	  (overrides the default behavior of preferring locally running instances)
 	  $ {{rootCmdUse}} invoke --target=remote
@@ -100,8 +106,8 @@ EXAMPLES
 		$ {{rootCmdUse}} invoke --insecure
 
 `,
-		SuggestFor: []string{"emit", "emti", "send", "emit", "exec", "nivoke", "onvoke", "unvoke", "knvoke", "imvoke", "ihvoke", "ibvoke"},
-		PreRunE:    bindEnv("path", "format", "target", "id", "source", "type", "data", "content-type", "file", "insecure", "confirm", "verbose"),
+		SuggestFor: []string{"send", "exec", "nivoke", "onvoke", "unvoke", "knvoke", "imvoke", "ihvoke", "ibvoke"},
+		PreRunE:    bindEnv("path", "format", "target", "environment", "id", "source", "type", "data", "content-type", "file", "insecure", "confirm", "verbose"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runInvoke(cmd, args, newClient)
 		},
@@ -124,9 +130,14 @@ EXAMPLES
 	cmd.Flags().StringP("file", "", "", "Path to a file to use as data. Overrides --data flag and should be sent with a correct --content-type. ($FUNC_FILE)")
 	cmd.Flags().BoolP("insecure", "i", false, "Allow insecure server connections when using SSL. ($FUNC_INSECURE)")
 	addConfirmFlag(cmd, cfg.Confirm)
+	addEnvironmentFlag(cmd)
 	addPathFlag(cmd)
 	addVerboseFlag(cmd, cfg.Verbose)
 
+	if err := cmd.RegisterFlagCompletionFunc("environment", CompleteEnvironmentList); err != nil {
+		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
+	}
+
 	return cmd
 }
 
@@ -151,6 +162,17 @@ func runInvoke(cmd *cobra.Command, _ []string, newClient ClientFactory) (err err
 		return fn.NewErrNotInitialized(f.Root)
 	}
 
+	// A named environment is invoked as the target instance.
+	if cfg.Environment != "" {
+		if cfg.Target != "" {
+			return fmt.Errorf("only one of --target and --environment should be provided")
+		}
+		if _, err = f.ForEnvironment(cfg.Environment); err != nil {
+			return err
+		}
+		cfg.Target = cfg.Environment
+	}
+
 	// Client instance from env vars, flags, args and user prompts (if --confirm)
 	client, done := newClient(ClientConfig{Verbose: cfg.Verbose, InsecureSkipVerify: cfg.Insecure})
 	defer done()
@@ -213,6 +235,7 @@ func runInvoke(cmd *cobra.Command, _ []string, newClient ClientFactory) (err err
 type invokeConfig struct {
 	Path        string
 	Target      string
+	Environment string
 	Format      string
 	ID          string
 	Source      string
@@ -229,6 +252,7 @@ func newInvokeConfig() (cfg invokeConfig, err error) {
 	cfg = invokeConfig{
 		Path:        viper.GetString("path"),
 		Target:      viper.GetString("target"),
+		Environment: viper.GetString("environment"),
 		Format:      viper.GetString("format"),
 		ID:          viper.GetString("id"),
 		Source:      viper.GetString("source"),
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/config"
+	"knative.dev/func/pkg/docker"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewLogsCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs [ID|NAME]",
+		Short: "Show the logs of a function",
+		Long: `
+NAME
+	{{rootCmdUse}} logs - Show the logs of a function
+
+SYNOPSIS
+	{{rootCmdUse}} logs --local [ID|NAME] [-f|--follow] [-p|--path]
+	             [-v|--verbose]
+
+DESCRIPTION
+	Shows the output of a function.
+
+	Local Instances
+	  The --local flag shows the output of an instance of a function running
+	  locally on this host.  By default, the instance of the function in the
+	  current directory (or that specified with --path) is shown.
+	  Alternatively, an instance can be chosen by the ID shown by 'func ps' or
+	  by function name.  The output of functions run in a container is that
+	  of the container.  The output of functions run on the host is retained
+	  only for those started using 'func run --detach'.
+
+	  The --follow flag continues to show output as it is written, until the
+	  function is stopped or the command is interrupted (^C).
+
+	Showing the logs of deployed functions is not yet supported.
+
+EXAMPLES
+
+	o Show the output of the function in the current directory, which was
+	  started using 'func run --detach'.
+	  $ {{rootCmdUse}} logs --local
+
+	o Follow the output of the instance with ID 4242 as listed by 'func ps'.
+	  $ {{rootCmdUse}} logs --local 4242 --follow
+`,
+		SuggestFor: []string{"log", "lgos"},
+		Args:       cobra.MaximumNArgs(1),
+		PreRunE:    bindEnv("follow", "local", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLogs(cmd, args, newClient)
+		},
+	}
+
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	cmd.Flags().BoolP("follow", "f", false, "Continue to show output as it is written. ($FUNC_FOLLOW)")
+	cmd.Flags().Bool("local", false, "Show the output of a function running locally. ($FUNC_LOCAL)")
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	return cmd
+}
+
+func runLogs(cmd *cobra.Command, args []string, newClient ClientFactory) (err error) {
+	var (
+		follow  = viper.GetBool("follow")
+		verbose = viper.GetBool("verbose")
+		root    string
+	)
+	if !viper.GetBool("local") {
+		return errors.New("showing the logs of deployed functions is coming soon.  Use --local to show the output of a function running locally")
+	}
+	if len(args) == 0 {
+		f, err := fn.NewFunction(viper.GetString("path"))
+		if err != nil {
+			return err
+		}
+		if !f.Initialized() {
+			return fn.NewErrNotInitialized(f.Root)
+		}
+		root = f.Root
+	}
+
+	// Logs of functions run in containers are read using the container runner.
+	client, done := newClient(ClientConfig{Verbose: verbose},
+		fn.WithRunner(docker.NewRunner(verbose, os.Stdout, os.Stderr)))
+	defer done()
+
+	jobs, err := client.Jobs()
+	if err != nil {
+		return
+	}
+	if jobs, err = selectJobs(jobs, args, root); err != nil {
+		return
+	}
+	switch len(jobs) {
+	case 0:
+		return errors.New("no matching function running.  See 'func ps'")
+	case 1:
+		return client.JobLogs(cmd.Context(), jobs[0], follow, cmd.OutOrStdout())
+	default:
+		ids := []string{}
+		for _, j := range jobs {
+			ids = append(ids, j.ID())
+		}
+		return fmt.Errorf("multiple instances running (%v).  Specify which by ID", strings.Join(ids, ", "))
+	}
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// jsonProgressListener writes each progress event as a line of JSON, such
+// that the progress of a build, deploy or run may be followed by tools.
+type jsonProgressListener struct {
+	mu  sync.Mutex // functions may be built and deployed concurrently
+	enc *json.Encoder
+}
+
+func newJSONProgressListener(w io.Writer) *jsonProgressListener {
+	return &jsonProgressListener{enc: json.NewEncoder(w)}
+}
+
+func (l *jsonProgressListener) Progress(e fn.ProgressEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_ = l.enc.Encode(e)
+}
+
+// progressOptions returns the client options with which the progress of
+// the command is streamed to w, if --output is json.
+func (c buildConfig) progressOptions(w io.Writer) []fn.Option {
+	if Format(c.Output) != JSON {
+		return nil
+	}
+	return []fn.Option{fn.WithProgressListener(newJSONProgressListener(w))}
+}
+
+// messages returns the writer of the informative messages of the command,
+// which is stdout unless it is reserved for progress events.
+func (c buildConfig) messages(cmd *cobra.Command) io.Writer {
+	if Format(c.Output) == JSON {
+		return cmd.ErrOrStderr()
+	}
+	return cmd.OutOrStdout()
+}
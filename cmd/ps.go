@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"knative.dev/func/pkg/config"
+	"knative.dev/func/pkg/docker"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewPsCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ps",
+		Short: "List functions running locally",
+		Long: `
+NAME
+	{{rootCmdUse}} ps - List functions running locally
+
+SYNOPSIS
+	{{rootCmdUse}} ps [-o|--output] [-v|--verbose]
+
+DESCRIPTION
+	Lists the instances of all functions running locally on this host, such
+	as those started using 'func run' (including with --detach).
+
+	For each instance is shown the function's name, the host port on which it
+	is listening, the image of its container (if run in a container), its ID
+	and how long it has been running.  The ID of an instance is the process ID
+	of functions run on the host, and the container ID of functions run in a
+	container.  Instances can be stopped by ID using 'func stop'.
+
+EXAMPLES
+
+	o List all functions running locally.
+	  $ {{rootCmdUse}} ps
+
+	o List all functions running locally with JSON output.
+	  $ {{rootCmdUse}} ps --output json
+`,
+		SuggestFor: []string{"sp"},
+		PreRunE:    bindEnv("output", "verbose"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runPs(cmd, newClient)
+		},
+	}
+
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	cmd.Flags().StringP("output", "o", "human", "Output format (human|plain|json|xml|yaml|url) ($FUNC_OUTPUT)")
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	if err := cmd.RegisterFlagCompletionFunc("output", CompleteOutputFormatList); err != nil {
+		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
+	}
+
+	return cmd
+}
+
+func runPs(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	var (
+		output  = viper.GetString("output")
+		verbose = viper.GetBool("verbose")
+	)
+	client, done := newClient(ClientConfig{Verbose: verbose},
+		fn.WithRunner(docker.NewRunner(verbose, os.Stdout, os.Stderr)))
+	defer done()
+
+	jobs, err := client.Jobs()
+	if err != nil {
+		return
+	}
+	if len(jobs) == 0 && Format(output) == Human {
+		fmt.Fprintln(cmd.OutOrStdout(), "no functions running")
+		return
+	}
+	write(cmd.OutOrStdout(), psItems(jobs), output)
+	return
+}
+
+// Output Formatting (serializers)
+// -------------------------------
+
+type psItems []fn.JobInfo
+
+func (items psItems) Human(w io.Writer) error {
+	return items.Plain(w)
+}
+
+func (items psItems) Plain(w io.Writer) error {
+	// minwidth, tabwidth, padding, padchar, flags
+	tabWriter := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tabWriter.Flush()
+
+	fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\t%s\n", "NAME", "PORT", "IMAGE", "ID", "UPTIME", "PATH")
+	for _, j := range items {
+		image := j.Image
+		if image == "" {
+			image = "-"
+		}
+		uptime := time.Since(j.Started).Round(time.Second)
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\t%s\n", j.Name, j.Port, image, j.ID(), uptime, j.Root)
+	}
+	return nil
+}
+
+func (items psItems) JSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(items)
+}
+
+func (items psItems) XML(w io.Writer) error {
+	return xml.NewEncoder(w).Encode(items)
+}
+
+func (items psItems) YAML(w io.Writer) error {
+	return yaml.NewEncoder(w).Encode(items)
+}
+
+func (items psItems) URL(w io.Writer) error {
+	for _, j := range items {
+		fmt.Fprintf(w, "http://%s\n", net.JoinHostPort(j.Host, j.Port))
+	}
+	return nil
+}
+
+// selectJobs returns those jobs which match any of the given IDs or function
+// names (container IDs may be abbreviated to any unique prefix).  If none are
+// given, the jobs of the function at root are returned.  An abbreviated ID
+// which matches the containers of several jobs is an error.
+func selectJobs(jobs []fn.JobInfo, ids []string, root string) (selected []fn.JobInfo, err error) {
+	selected = []fn.JobInfo{}
+	if len(ids) == 0 {
+		for _, j := range jobs {
+			if j.Root == root {
+				selected = append(selected, j)
+			}
+		}
+		return
+	}
+	matched := make(map[int]bool)
+	for _, id := range ids {
+		var prefixed []int
+		for i, j := range jobs {
+			if id == j.Name || id == j.ID() || id == j.Container {
+				matched[i] = true
+			} else if j.Container != "" && strings.HasPrefix(j.Container, id) {
+				prefixed = append(prefixed, i)
+			}
+		}
+		if len(prefixed) > 1 {
+			return nil, fmt.Errorf("ambiguous container ID %q matches %v instances", id, len(prefixed))
+		}
+		for _, i := range prefixed {
+			matched[i] = true
+		}
+	}
+	for i, j := range jobs {
+		if matched[i] {
+			selected = append(selected, j)
+		}
+	}
+	return
+}
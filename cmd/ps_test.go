@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestPs ensures that running functions are listed with their port, image
+// and ID.
+func TestPs(t *testing.T) {
+	root := FromTempDirectory(t)
+	jobs := t.TempDir()
+
+	port := "8089"
+
+	runner := &containerRunner{Runner: mock.NewRunner(), running: true}
+	runner.RunFn = func(_ context.Context, f fn.Function, opts fn.RunOptions) (*fn.Job, error) {
+		job, err := fn.NewJob(f, "127.0.0.1", opts.Port, nil, nil, false)
+		if job != nil {
+			job.Container = "0123456789abcdef"
+			job.Image = "example.com/ns/myfunc:latest"
+		}
+		return job, err
+	}
+	client := fn.New(fn.WithRunner(runner), fn.WithJobsPath(jobs))
+	f, err := client.Init(fn.Function{Root: root, Name: "myfunc", Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Run(context.Background(), f, fn.RunWithPort(port)); err != nil {
+		t.Fatal(err)
+	}
+
+	out := bytes.Buffer{}
+	cmd := NewPsCmd(NewTestClient(fn.WithRunner(runner), fn.WithJobsPath(jobs)))
+	cmd.SetArgs([]string{})
+	cmd.SetOut(&out)
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a header and one function, got:\n%v", out.String())
+	}
+	for _, s := range []string{"myfunc", port, "example.com/ns/myfunc:latest", "0123456789ab"} {
+		if !strings.Contains(lines[1], s) {
+			t.Errorf("expected %q in %q", s, lines[1])
+		}
+	}
+
+	// Once no longer running, it is not listed
+	runner.running = false
+	out.Reset()
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out.String()) != "no functions running" {
+		t.Fatalf("unexpected output:\n%v", out.String())
+	}
+}
+
+// containerRunner is a mock runner whose jobs run in containers, the
+// liveness of which it reports.
+type containerRunner struct {
+	*mock.Runner
+	running bool
+}
+
+func (r *containerRunner) StopJob(context.Context, fn.JobInfo) error { return nil }
+
+func (r *containerRunner) JobLogs(context.Context, fn.JobInfo, bool, io.Writer) error { return nil }
+
+func (r *containerRunner) JobRunning(context.Context, fn.JobInfo) (bool, error) {
+	return r.running, nil
+}
+
+// TestSelectJobs ensures that jobs are selected by ID, container ID prefix
+// and name, or by function root when none are given, and that an ambiguous
+// container ID prefix is an error.
+func TestSelectJobs(t *testing.T) {
+	jobs := []fn.JobInfo{
+		{Name: "a", Root: "/a", Port: "8080", PID: 100},
+		{Name: "b", Root: "/b", Port: "8081", Container: "0123456789abcdef"},
+		{Name: "b", Root: "/b", Port: "8082", PID: 102},
+		{Name: "c", Root: "/c", Port: "8083", Container: "0124456789abcdef"},
+	}
+	tests := []struct {
+		name  string
+		ids   []string
+		root  string
+		ports []string
+		err   bool
+	}{
+		{name: "by root", root: "/b", ports: []string{"8081", "8082"}},
+		{name: "by pid", ids: []string{"100"}, ports: []string{"8080"}},
+		{name: "by container prefix", ids: []string{"0123"}, ports: []string{"8081"}},
+		{name: "by name", ids: []string{"b"}, ports: []string{"8081", "8082"}},
+		{name: "by full container ID", ids: []string{"0124456789abcdef"}, ports: []string{"8083"}},
+		{name: "ambiguous container prefix", ids: []string{"012"}, err: true},
+		{name: "no match", ids: []string{"d"}, ports: []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selected, err := selectJobs(jobs, test.ids, test.root)
+			if test.err {
+				if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+					t.Fatalf("expected an ambiguous container ID error, got %v", err)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			ports := []string{}
+			for _, j := range selected {
+				ports = append(ports, j.Port)
+			}
+			if strings.Join(ports, ",") != strings.Join(test.ports, ",") {
+				t.Fatalf("expected ports %v, got %v", test.ports, ports)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewRollbackCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback [number]",
+		Short: "Redeploy a previous deployment of a function",
+		Long: `
+NAME
+	{{rootCmdUse}} rollback - Redeploy a previous deployment of a function
+
+SYNOPSIS
+	{{rootCmdUse}} rollback [number] [--environment] [-p|--path] [-v|--verbose]
+
+DESCRIPTION
+	Redeploys the function in the current directory, or in the directory
+	specified with --path, as it was deployed by the deployment with the
+	given number of its history.  Without a number, the deployment preceding
+	that currently deployed is redeployed, skipping previous rollbacks and
+	the deployments they replaced, such that successive rollbacks step back
+	through the history.  See '{{rootCmdUse}} history' for the numbers of the
+	deployments of the function.
+
+	Each environment of the function has its own history.  With
+	--environment, a deployment of the given environment is redeployed to
+	the namespace in which that environment is deployed.
+
+	The image of the deployment is redeployed with the func.yaml with which
+	it was deployed, to the namespace in which the function is currently
+	deployed.  The function is not rebuilt.
+
+	The rollback is recorded in the history as a new deployment.  The
+	function's func.yaml is not changed, such that the next deploy deploys
+	the function as defined by its source.
+`,
+		Example: `
+# Redeploy the deployment preceding the latest
+{{rootCmdUse}} rollback
+
+# Redeploy the third deployment of the function's history
+{{rootCmdUse}} rollback 3
+
+# Redeploy the previous deployment of the function's "prod" environment
+{{rootCmdUse}} rollback --environment prod
+`,
+		SuggestFor: []string{"rolback", "revert", "undo"},
+		Args:       cobra.MaximumNArgs(1),
+		PreRunE:    bindEnv("environment", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRollback(cmd, args, newClient)
+		},
+	}
+
+	// Config
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	// Flags
+	addEnvironmentFlag(cmd)
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	if err := cmd.RegisterFlagCompletionFunc("environment", CompleteEnvironmentList); err != nil {
+		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
+	}
+
+	return cmd
+}
+
+func runRollback(cmd *cobra.Command, args []string, newClient ClientFactory) (err error) {
+	var (
+		path    = viper.GetString("path")
+		verbose = viper.GetBool("verbose")
+		number  int
+	)
+	if len(args) > 0 {
+		if number, err = strconv.Atoi(args[0]); err != nil || number < 1 {
+			return fmt.Errorf("invalid deployment number %q", args[0])
+		}
+	}
+
+	f, err := fn.NewFunction(path)
+	if err != nil {
+		return
+	}
+	if f, err = f.ForEnvironment(viper.GetString("environment")); err != nil {
+		return
+	}
+
+	client, done := newClient(ClientConfig{Verbose: verbose})
+	defer done()
+
+	if _, err = client.Rollback(cmd.Context(), f, number); err != nil {
+		return
+	}
+	h, err := f.History()
+	if err != nil {
+		return
+	}
+	if len(h) > 0 {
+		d := h[len(h)-1]
+		fmt.Fprintf(cmd.OutOrStdout(), "Rolled back to deployment %v (%v)\n", d.RollbackTo, d.Image)
+	}
+	return
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestRollback ensures that the deployment preceding the latest, or that
+// with the given number, is redeployed.
+func TestRollback(t *testing.T) {
+	root := FromTempDirectory(t)
+	deployer := mock.NewDeployer()
+	client := fn.New(fn.WithDeployer(deployer))
+	f, err := client.Init(fn.Function{Root: root, Runtime: "go", Namespace: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, image := range []string{"example.com/alice/f@sha256:1", "example.com/alice/f@sha256:2"} {
+		f.Deploy.Image = image
+		if f, err = client.Deploy(context.Background(), f, fn.WithDeploySkipBuildCheck(true)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	var deployed string
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		deployed = f.Deploy.Image
+		return fn.DeploymentResult{Namespace: f.Namespace}, nil
+	}
+	// In order, as each rollback is recorded as the latest deployment
+	for _, test := range []struct {
+		args     []string
+		expected string
+	}{
+		{args: []string{}, expected: "example.com/alice/f@sha256:1"},
+		{args: []string{"2"}, expected: "example.com/alice/f@sha256:2"},
+	} {
+		cmd := NewRollbackCmd(NewTestClient(fn.WithDeployer(deployer)))
+		cmd.SetArgs(test.args)
+		if err = cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		if deployed != test.expected {
+			t.Fatalf("rollback %v: expected %v to be redeployed, got %v", test.args, test.expected, deployed)
+		}
+	}
+
+	// Invalid deployment number
+	cmd := NewRollbackCmd(NewTestClient(fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"latest"})
+	if err = cmd.Execute(); err == nil {
+		t.Fatal("expected an error rolling back to an invalid deployment number")
+	}
+}
+
+// TestRollback_Environment ensures that a rollback of an environment
+// redeploys a previous deployment of that environment, regardless of the
+// deployments of other environments.
+func TestRollback_Environment(t *testing.T) {
+	root := FromTempDirectory(t)
+	deployer := mock.NewDeployer()
+	client := fn.New(fn.WithDeployer(deployer))
+	f, err := client.Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Environments = fn.Environments{"staging": {}, "prod": {}}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []struct{ env, image string }{
+		{"staging", "example.com/alice/f@sha256:1"},
+		{"prod", "example.com/alice/f@sha256:1"},
+		{"staging", "example.com/alice/f@sha256:2"},
+		{"prod", "example.com/alice/f@sha256:2"},
+		{"staging", "example.com/alice/f@sha256:3"},
+	} {
+		if f, err = fn.NewFunction(root); err != nil {
+			t.Fatal(err)
+		}
+		ef, err := f.ForEnvironment(d.env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ef.Namespace = d.env
+		ef.Deploy.Image = d.image
+		if ef, err = client.Deploy(context.Background(), ef, fn.WithDeploySkipBuildCheck(true)); err != nil {
+			t.Fatal(err)
+		}
+		if err = ef.Write(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var deployed fn.Function
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		deployed = f
+		return fn.DeploymentResult{Namespace: f.Namespace}, nil
+	}
+	cmd := NewRollbackCmd(NewTestClient(fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--environment", "prod"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if deployed.Deploy.Image != "example.com/alice/f@sha256:1" || deployed.Namespace != "prod" {
+		t.Fatalf("expected the first prod deployment to be redeployed to prod, got %v to %v", deployed.Deploy.Image, deployed.Namespace)
+	}
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewRolloutCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollout",
+		Short: "Manage the rollout of a new revision of a function",
+		Long: `
+NAME
+	{{rootCmdUse}} rollout - Manage the rollout of a new revision of a function
+
+SYNOPSIS
+	{{rootCmdUse}} rollout abort|promote [--environment] [-p|--path] [-v|--verbose]
+
+DESCRIPTION
+	By default a new revision of a deployed function receives all traffic
+	once ready.  A function whose func.yaml defines a rollout is instead
+	updated with a canary: the new revision receives a percent of traffic,
+	and the revision which received it before (the stable revision) the
+	remainder.  For example, to route 10% of traffic to each new revision:
+	  deploy:
+	    rollout:
+	      percent: 10
+	The percent may also be set for a single deployment with
+	'{{rootCmdUse}} deploy --canary'.  The canary and stable revisions are
+	tagged, such that each may be invoked directly at the URL of its tag.
+
+	A canary is promoted, routing all traffic to it, with
+	'{{rootCmdUse}} rollout promote' (or '{{rootCmdUse}} promote').  A rollout is
+	aborted, routing all traffic back to the stable revision, with
+	'{{rootCmdUse}} rollout abort'.  Deploying again replaces the canary.
+
+	The traffic may instead be stepped up automatically by setting a step
+	and interval, such that the canary is promoted once it receives all
+	traffic.  The deploy command waits for the canary to be promoted:
+	interrupting it leaves the rollout in progress.
+	  deploy:
+	    rollout:
+	      percent: 10
+	      step: 30
+	      interval: 5m
+`,
+		Example: `
+# Promote the canary of the function in the current directory
+{{rootCmdUse}} rollout promote
+
+# Abort the rollout of the function deployed to its "prod" environment
+{{rootCmdUse}} rollout abort --environment prod
+`,
+		SuggestFor: []string{"rolout", "canary"},
+	}
+	cmd.AddCommand(newRolloutActionCmd(newClient, "abort",
+		"Abort the rollout of a function, routing all traffic to its stable revision",
+		"Aborts the rollout of the function in the current directory, or in the\ndirectory specified with --path, routing all traffic to its stable\nrevision.  The canary remains the latest revision of the function until\nit is next deployed."))
+	cmd.AddCommand(newRolloutActionCmd(newClient, "promote",
+		"Promote the canary of a function, routing all traffic to it",
+		promoteDescription))
+	return cmd
+}
+
+const promoteDescription = "Promotes the canary of the function in the current directory, or in the\ndirectory specified with --path, routing all traffic to it and ending its\nrollout."
+
+func NewPromoteCmd(newClient ClientFactory) *cobra.Command {
+	cmd := newRolloutActionCmd(newClient, "promote",
+		"Promote the canary of a function, routing all traffic to it",
+		promoteDescription+"  Equivalent to '{{rootCmdUse}} rollout promote'.")
+	cmd.SuggestFor = []string{"promte", "promot"}
+	return cmd
+}
+
+// newRolloutActionCmd returns the command which promotes or aborts the
+// rollout of a function.
+func newRolloutActionCmd(newClient ClientFactory, action, short, description string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     action,
+		Short:   short,
+		Long:    short + "\n\n" + description + "\n",
+		PreRunE: bindEnv("environment", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runRolloutAction(cmd, newClient, action)
+		},
+	}
+
+	// Config
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	// Flags
+	addEnvironmentFlag(cmd)
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	if err := cmd.RegisterFlagCompletionFunc("environment", CompleteEnvironmentList); err != nil {
+		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
+	}
+
+	return cmd
+}
+
+func runRolloutAction(cmd *cobra.Command, newClient ClientFactory, action string) (err error) {
+	f, err := fn.NewFunction(viper.GetString("path"))
+	if err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return fn.NewErrNotInitialized(f.Root)
+	}
+	if f, err = f.ForEnvironment(viper.GetString("environment")); err != nil {
+		return
+	}
+
+	client, done := newClient(ClientConfig{Verbose: viper.GetBool("verbose")})
+	defer done()
+
+	if action == "abort" {
+		err = client.AbortRollout(cmd.Context(), f)
+	} else {
+		err = client.Promote(cmd.Context(), f)
+	}
+	if errors.Is(err, fn.ErrRolloutNotInProgress) {
+		return fmt.Errorf("function %q has no rollout in progress", f.Name)
+	} else if err != nil {
+		return
+	}
+	if action == "abort" {
+		fmt.Fprintln(cmd.OutOrStdout(), "Rollout aborted: all traffic is routed to the stable revision")
+	} else {
+		fmt.Fprintln(cmd.OutOrStdout(), "Canary promoted: all traffic is routed to the latest revision")
+	}
+	return
+}
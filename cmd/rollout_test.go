@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestRollout ensures that promoting a canary routes all traffic to it, and
+// that aborting a rollout routes none.
+func TestRollout(t *testing.T) {
+	root := FromTempDirectory(t)
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go", Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Deploy.Namespace = "prod"
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	var routed int64 = -1
+	router := mock.NewRouter()
+	router.RouteFn = func(_ context.Context, _ fn.Function, percent int64) error {
+		routed = percent
+		return nil
+	}
+	for _, test := range []struct {
+		newCmd   func(ClientFactory) *cobra.Command
+		args     []string
+		expected int64
+	}{
+		{NewPromoteCmd, []string{}, 100},
+		{NewRolloutCmd, []string{"abort"}, 0},
+		{NewRolloutCmd, []string{"promote"}, 100},
+	} {
+		cmd := test.newCmd(NewTestClient(fn.WithRouter(router)))
+		cmd.SetArgs(test.args)
+		routed = -1
+		if err = cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		if routed != test.expected {
+			t.Fatalf("%v: expected %v%% of traffic to be routed to the canary, got %v", test.args, test.expected, routed)
+		}
+	}
+
+	// No rollout in progress
+	router.RouteFn = func(context.Context, fn.Function, int64) error { return fn.ErrRolloutNotInProgress }
+	cmd := NewPromoteCmd(NewTestClient(fn.WithRouter(router)))
+	cmd.SetArgs([]string{})
+	if err = cmd.Execute(); err == nil || !strings.Contains(err.Error(), "no rollout in progress") {
+		t.Fatalf("expected an error without a rollout in progress, got %v", err)
+	}
+}
+
+// TestDeploy_Canary ensures that --canary overrides the rollout of the
+// function for the deployment only.
+func TestDeploy_Canary(t *testing.T) {
+	root := FromTempDirectory(t)
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go", Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rollout *fn.RolloutSpec
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		rollout = f.Deploy.Rollout
+		return fn.DeploymentResult{Namespace: "prod"}, nil
+	}
+	cmd := NewDeployCmd(NewTestClient(fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--canary", "25"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if rollout == nil || rollout.Percent != 25 {
+		t.Fatalf("expected a canary of 25%%, got %v", rollout)
+	}
+	if f, err = fn.NewFunction(f.Root); err != nil {
+		t.Fatal(err)
+	}
+	if f.Deploy.Rollout != nil {
+		t.Fatalf("expected the canary not to be persisted, got %v", f.Deploy.Rollout)
+	}
+
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--canary", "100"})
+	if err = cmd.Execute(); err == nil {
+		t.Fatal("expected an error deploying a canary of all traffic")
+	}
+}
@@ -84,6 +84,12 @@ Learn more about Knative at: https://knative.dev`, cfg.Name),
 				NewCreateCmd(newClient),
 				NewDescribeCmd(newClient),
 				NewDeployCmd(newClient),
+				NewExportCmd(),
+				NewDiffCmd(newClient),
+				NewHistoryCmd(),
+				NewRollbackCmd(newClient),
+				NewPromoteCmd(newClient),
+				NewRolloutCmd(newClient),
 				NewDeleteCmd(newClient),
 				NewListCmd(newClient),
 				NewSubscribeCmd(),
@@ -94,7 +100,11 @@ Learn more about Knative at: https://knative.dev`, cfg.Name),
 			Commands: []*cobra.Command{
 				NewRunCmd(newClient),
 				NewInvokeCmd(newClient),
+				NewEmitCmd(newClient),
 				NewBuildCmd(newClient),
+				NewPsCmd(newClient),
+				NewLogsCmd(newClient),
+				NewStopCmd(newClient),
 			},
 		},
 		{
@@ -161,6 +171,43 @@ func effectivePath() (path string) {
 	return path
 }
 
+// effectiveEnvironment to use is that which was provided by --environment
+// or FUNC_ENVIRONMENT.  Like effectivePath, flags are manually parsed such
+// that this can be used during flag definition to calculate defaults from
+// the function as targeted at the named environment.
+func effectiveEnvironment() (environment string) {
+	var (
+		env = os.Getenv("FUNC_ENVIRONMENT")
+		fs  = pflag.NewFlagSet("", pflag.ContinueOnError)
+		e   = fs.String("environment", "", "")
+	)
+	fs.SetOutput(io.Discard)
+	fs.ParseErrorsWhitelist.UnknownFlags = true // wokeignore:rule=whitelist
+	_ = fs.Parse(os.Args[1:])
+	if env != "" {
+		environment = env
+	}
+	if *e != "" {
+		environment = *e
+	}
+	return environment
+}
+
+// functionContext returns the function at the effective path as targeted
+// at the effective environment for use when calculating flag defaults.
+// Errors are ignored, as the function is loaded and validated again when
+// the command is run.
+func functionContext() fn.Function {
+	f, _ := fn.NewFunction(effectivePath())
+	if !f.Initialized() {
+		return f
+	}
+	if ef, err := f.ForEnvironment(effectiveEnvironment()); err == nil {
+		return ef
+	}
+	return f
+}
+
 // defaultNamespace to use when none is provided explicitly.
 // This requires a bit more logic than normal flag defaults, which rely
 // on the order of precedence Static Config -> Global Config -> Current Func ->
@@ -314,6 +361,18 @@ func addConfirmFlag(cmd *cobra.Command, dflt bool) {
 	cmd.Flags().BoolP("confirm", "c", dflt, "Prompt to confirm options interactively ($FUNC_CONFIRM)")
 }
 
+// addEnvironmentFlag ensures common text/wording when the --environment
+// flag is used
+func addEnvironmentFlag(cmd *cobra.Command) {
+	cmd.Flags().String("environment", "", "Named environment defined in func.yaml whose settings are to be used. ($FUNC_ENVIRONMENT)")
+}
+
+// addProgressOutputFlag ensures common text/wording when the --output flag
+// selects the format of the progress of a build, deploy or run.
+func addProgressOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", "human", "Output format of progress (human|json).  With json, progress events are streamed to stdout as JSON lines ($FUNC_OUTPUT)")
+}
+
 // addPathFlag ensures common text/wording when the --path flag is used
 func addPathFlag(cmd *cobra.Command) {
 	cmd.Flags().StringP("path", "p", "", "Path to the function.  Default is current directory ($FUNC_PATH)")
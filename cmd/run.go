@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ory/viper"
@@ -14,6 +16,7 @@ import (
 	"knative.dev/func/pkg/config"
 	"knative.dev/func/pkg/docker"
 	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
 )
 
 func NewRunCmd(newClient ClientFactory) *cobra.Command {
@@ -26,8 +29,10 @@ NAME
 
 SYNOPSIS
 	{{rootCmdUse}} run [-t|--container] [-r|--registry] [-i|--image] [-e|--env]
-	             [--build] [-b|--builder] [--builder-image] [-c|--confirm]
-	             [-v|--verbose]
+	             [--build] [-b|--builder] [--builder-image] [-w|--watch]
+	             [-d|--detach] [--fetch-resources] [--broker] [--broker-port]
+	             [--broker-sink] [--debug] [--debug-port] [--workspace]
+	             [-o|--output] [-c|--confirm] [-v|--verbose]
 
 DESCRIPTION
 	Run the function locally.
@@ -42,14 +47,119 @@ DESCRIPTION
 	  indicates the system should automatically build the container only if
 	  necessary.
 
+	Secrets, ConfigMaps and Volumes
+	  When running in a container, environment variables which reference
+	  Secrets and ConfigMaps (such as those added using 'func config envs')
+	  are set from the function's local resources, and its volumes are mounted at
+	  their paths: Secrets and ConfigMaps read-only from the local resources,
+	  persistent volume claims from a directory of the local resources, and
+	  empty dirs as tmpfs.  The local resources are held in .func/resources,
+	  with each Secret and ConfigMap a directory of files, one per key:
+	    .func/resources/secrets/{name}/{key}
+	    .func/resources/configmaps/{name}/{key}
+	    .func/resources/volumes/{claimName}/
+	  The --fetch-resources flag copies the Secrets and ConfigMaps referenced
+	  by the function from the function's namespace of the current cluster
+	  into its local resources before running.  The cluster is only read.
+
+	Resource Limits
+	  When running in a container, the CPU and memory limits of the function
+	  (the resources options of its func.yaml) are applied to the container, which is killed if it exceeds its memory limit.  A
+	  concurrency limit is enforced by a proxy on the host port which, like
+	  the Knative queue-proxy, queues requests beyond the limit, and rejects
+	  those beyond ten times the limit.  The concurrency limit is not enforced
+	  for detached runs.
+
+	Local Broker
+	  The --broker flag starts a local CloudEvents broker alongside the
+	  function, which delivers the events it receives to the function per the
+	  function's subscriptions (see 'func subscribe'), as the Knative
+	  Triggers created for them on deploy would.  An event is delivered once
+	  for each subscription to the broker whose filters match it, and whether
+	  each event matched is printed.  The broker's name defaults to "default",
+	  and it listens on the --broker-port of the host.  Events with which the
+	  function replies are sent to the --broker-sink URL, if provided.
+
+	Debugging
+	  The --debug flag runs the function such that a debugger may be attached
+	  to it on the --debug-port of the host's loopback interface.  When run on
+	  the host, Go functions are built with optimizations disabled and run
+	  under Delve (dlv) in headless mode, Python functions are run using
+	  debugpy (installed into the virtual environment if necessary), and
+	  Node.js and TypeScript functions with the Node inspector enabled.  When
+	  run in a container, the debug agent of the runtime is enabled within the
+	  container and its port published: Go functions run the command of their
+	  image under the host's Delve, copied into the container, Python
+	  functions run it using debugpy (installed into the container if
+	  necessary), Node.js and TypeScript functions enable the Node inspector,
+	  and Quarkus and Spring Boot functions enable JDWP.  Debugging Python
+	  functions in a container requires an image whose command runs python,
+	  such as those of the host builder.
+	  The debug port defaults to that conventional for the runtime: 2345
+	  (Delve), 5678 (debugpy), 9229 (Node) and 5005 (JDWP).
+
+	Workspaces
+	  The --workspace flag runs together all functions of the workspace whose
+	  workspace file (func-workspace.yaml) is in the --path directory, such as
+	  the functions of a monorepo which call one another.  The workspace file
+	  lists the path of each function's root relative to it:
+	    functions:
+	      - path: orders
+	      - path: payments
+	  Each function is built (if necessary) and run on its own host port,
+	  starting at 8080, and is provided the URL of each of its siblings in an
+	  environment variable named for the sibling; for example ORDERS_URL for
+	  a function named "orders".  When run in containers, the functions share
+	  a container network on which they reach one another by name.  Their
+	  output is combined, each line prefixed with the function's name, and
+	  all are stopped on Ctrl-C or when any one of them exits.  The global
+	  --registry and --builder apply to functions which do not configure
+	  their own, and --env to all functions.
+
+	Watching for Changes
+	  The --watch flag indicates that the function should be restarted each
+	  time its source code changes.  Changes are detected in the function's
+	  directory, excluding files matched by its .funcignore and those written
+	  by running it (such as Python bytecode, node_modules and the npm
+	  lockfile).  When running in a container, the function is first rebuilt
+	  using the configured builder.  The restarted function listens on the
+	  same host port, such that it remains available at the same address (for
+	  example to 'func invoke').
+
+	Detached Runs
+	  The --detach flag indicates that the function should be run in the
+	  background, such that the command returns once the function has started
+	  and the function continues to run after it exits.  Running instances of
+	  all functions can be listed using 'func ps', their output shown using
+	  'func logs --local', and they can be stopped using 'func stop'.
+
+	Progress
+	  By default progress is printed as messages to stderr.  With --output
+	  json, events are instead streamed to stdout as JSON lines as the
+	  function is built and started, and once it is accepting requests at
+	  its URL (including each restart when watching), or an error occurs.
+	  The output of the function is then written to stderr.  The --output
+	  json flag can not be used with --verbose or --workspace.
+
 	Process Scaffolding
-	  This is an Experimental Feature currently available only to Go projects.
+	  This is an Experimental Feature currently available only to Go, Python,
+	  Node.js and TypeScript projects.
 	  When running a function with --container=false (host-based runs), the
 	  function is first wrapped code which presents it as a process.
 	  This "scaffolding" is transient, written for each build or run, and should
 	  in most cases be transparent to a function author.  However, to customize,
 	  or even completely replace this scafolding code, see the 'scaffold'
 	  subcommand.
+	  Python functions are run within a virtual environment held in .func,
+	  into which the function's requirements.txt is installed.  It is reused
+	  by subsequent runs until the requirements change.  A Python 3
+	  interpreter is required on the host.
+	  Node.js and TypeScript functions have their dependencies installed
+	  using npm into .func, where they are reused by subsequent runs until
+	  the package manifests change.  Functions are run from a copy of their
+	  source, such that their directory is unaltered, using the
+	  faas-js-runtime.  TypeScript is first compiled into the run's job
+	  directory.  Node.js and npm are required on the host.
 
 EXAMPLES
 
@@ -60,11 +170,35 @@ EXAMPLES
 	  of the container even if no filesysem changes are detected
 	  $ {{rootCmdUse}} run --build
 
-	o Run the function locally on the host with no containerization (Go only).
+	o Run the function locally from within its container, with the Secrets
+	  and ConfigMaps it references copied from the current cluster.
+	  $ {{rootCmdUse}} run --fetch-resources
+
+	o Run the function locally with a local broker, sending the function's
+	  replies to another local service.
+	  $ {{rootCmdUse}} run --broker --broker-sink http://localhost:9090
+
+	o Run the function locally on the host with no containerization (Go,
+	  Python, Node.js and TypeScript only).
 	  $ {{rootCmdUse}} run --container=false
+
+	o Run the function locally on the host with Delve listening on port
+	  2345 for a debugger to attach (Go).
+	  $ {{rootCmdUse}} run --container=false --debug
+
+	o Run the function locally on the host, restarting it whenever its
+	  source code changes.
+	  $ {{rootCmdUse}} run --container=false --watch
+
+	o Run all functions of the workspace defined in the current directory.
+	  $ {{rootCmdUse}} run --workspace
+
+	o Run the function locally in the background, and later stop it.
+	  $ {{rootCmdUse}} run --detach
+	  $ {{rootCmdUse}} stop
 `,
 		SuggestFor: []string{"rnu"},
-		PreRunE:    bindEnv("build", "builder", "builder-image", "confirm", "container", "env", "image", "path", "registry", "start-timeout", "verbose"),
+		PreRunE:    bindEnv("build", "builder", "builder-image", "confirm", "container", "env", "image", "path", "registry", "start-timeout", "verbose", "watch", "detach", "fetch-resources", "broker", "broker-port", "broker-sink", "debug", "debug-port", "workspace", "output"),
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			return runRun(cmd, newClient)
 		},
@@ -118,10 +252,30 @@ EXAMPLES
 	cmd.Flags().Lookup("build").NoOptDefVal = "true" // register `--build` as equivalient to `--build=true`
 	cmd.Flags().BoolP("container", "t", true,
 		"Run the function in a container. ($FUNC_CONTAINER)")
+	cmd.Flags().BoolP("watch", "w", false,
+		"Restart the function when its source code changes, rebuilding it first if running in a container. ($FUNC_WATCH)")
+	cmd.Flags().BoolP("detach", "d", false,
+		"Run the function in the background.  See the ps, logs and stop commands. ($FUNC_DETACH)")
+	cmd.Flags().Bool("fetch-resources", false,
+		"Copy the Secrets and ConfigMaps referenced by the function from the cluster into its local resources before running in a container. ($FUNC_FETCH_RESOURCES)")
+	cmd.Flags().String("broker", "",
+		"Start a local broker of the given name which delivers events to the function per its subscriptions. ($FUNC_BROKER)")
+	cmd.Flags().Lookup("broker").NoOptDefVal = fn.DefaultBroker // register `--broker` as equivalent to `--broker=default`
+	cmd.Flags().String("broker-port", "8081",
+		"Host port on which the local broker accepts events. ($FUNC_BROKER_PORT)")
+	cmd.Flags().String("broker-sink", "",
+		"URL to which the local broker sends the events with which the function replies. ($FUNC_BROKER_SINK)")
+	cmd.Flags().Bool("debug", false,
+		"Run the function such that a debugger may be attached. ($FUNC_DEBUG)")
+	cmd.Flags().String("debug-port", "",
+		"Host port on which the debugger listens.  Defaults to that conventional for the runtime. ($FUNC_DEBUG_PORT)")
+	cmd.Flags().Bool("workspace", false,
+		"Run all functions of the workspace whose workspace file is in the path. ($FUNC_WORKSPACE)")
 
 	// Oft-shared flags:
 	addConfirmFlag(cmd, cfg.Confirm)
 	addPathFlag(cmd)
+	addProgressOutputFlag(cmd)
 	addVerboseFlag(cmd, cfg.Verbose)
 
 	// Tab Completion
@@ -141,7 +295,10 @@ func runRun(cmd *cobra.Command, newClient ClientFactory) (err error) {
 		cfg runConfig
 		f   fn.Function
 	)
-	if cfg, err = newRunConfig(cmd).Prompt(); err != nil {
+	if cfg = newRunConfig(cmd); cfg.Workspace {
+		return runWorkspace(cmd, cfg, newClient)
+	}
+	if cfg, err = cfg.Prompt(); err != nil {
 		return
 	}
 	if f, err = fn.NewFunction(cfg.Path); err != nil {
@@ -168,6 +325,7 @@ func runRun(cmd *cobra.Command, newClient ClientFactory) (err error) {
 	if cfg.StartTimeout != 0 {
 		clientOptions = append(clientOptions, fn.WithStartTimeout(cfg.StartTimeout))
 	}
+	clientOptions = append(clientOptions, cfg.progressOptions(cmd.OutOrStdout())...)
 
 	client, done := newClient(ClientConfig{Verbose: cfg.Verbose}, clientOptions...)
 	defer done()
@@ -176,19 +334,107 @@ func runRun(cmd *cobra.Command, newClient ClientFactory) (err error) {
 	//
 	// If requesting to run via the container, build the container if it is
 	// either out-of-date or a build was explicitly requested.
+	if f, err = buildForRun(cmd, cfg, f, client); err != nil {
+		return
+	}
+
+	// Local Resources
+	//
+	// Copy the Secrets and ConfigMaps the function references from the
+	// cluster, for use by the container runner.
+	if cfg.FetchResources {
+		namespace := f.Deploy.Namespace
+		if namespace == "" {
+			namespace = f.Namespace
+		}
+		if err = k8s.FetchLocalResources(cmd.Context(), f, namespace); err != nil {
+			return
+		}
+	}
+
+	// Run
+	//
+	// Runs the code either via a container or the default host-based runner.
+	// For the former, build is required and a container runtime.  For the
+	// latter, scaffolding is first applied and the local host must be
+	// configured to build/run the language of the function.
+	runOptions := []fn.RunOption{}
+	if cfg.Detach {
+		runOptions = append(runOptions, fn.RunDetached())
+	}
+	if cfg.Debug {
+		runOptions = append(runOptions, fn.RunWithDebug(cfg.DebugPort))
+	}
+	if Format(cfg.Output) == JSON { // stdout is reserved for progress events
+		runOptions = append(runOptions, fn.RunWithOutput(cmd.ErrOrStderr(), cmd.ErrOrStderr()))
+	}
+	job, err := client.Run(cmd.Context(), f, runOptions...)
+	if err != nil {
+		return
+	}
+	if job.DebugPort != "" {
+		fmt.Fprintf(cmd.OutOrStderr(), "Debugger listening on host port %v\n", job.DebugPort)
+	}
+	if cfg.Detach {
+		fmt.Fprintf(cmd.OutOrStderr(), "Running in the background on host port %v\n", job.Port)
+		return
+	}
+	fmt.Fprintf(cmd.OutOrStderr(), "Running on host port %v\n", job.Port)
+
+	if cfg.Broker != "" {
+		if err = runBroker(cmd, cfg, f, job); err != nil {
+			_ = job.Stop()
+			return
+		}
+	}
+
+	if cfg.Watch {
+		return runWatch(cmd, cfg, f, client, job)
+	}
+	defer func() {
+		if err = job.Stop(); err != nil {
+			fmt.Fprintf(cmd.OutOrStderr(), "Job stop error. %v", err)
+		}
+	}()
+
+	select {
+	case <-cmd.Context().Done():
+		if !errors.Is(cmd.Context().Err(), context.Canceled) {
+			err = cmd.Context().Err()
+		}
+	case err = <-job.Errors:
+		return
+		// Bubble up runtime errors on the optional channel used for async job
+		// such as docker containers.
+	}
+
+	// NOTE: we do not f.Write() here unlike deploy (and build).
+	// running is ephemeral: a run is not affecting the function itself,
+	// as opposed to deploy commands, which are actually mutating the current
+	// state of the function as it exists on the network.
+	// Another way to think of this is that runs are development-centric tests,
+	// and thus most likely values changed such as environment variables,
+	// builder, etc. would not be expected to persist and affect the next deploy.
+	// Run is ephemeral, deploy is persistent.
+	return
+}
+
+// buildForRun builds the function's container if running in a container and
+// it is either out-of-date or a build was explicitly requested.
+func buildForRun(cmd *cobra.Command, cfg runConfig, f fn.Function, client *fn.Client) (fn.Function, error) {
 	if cfg.Container {
 		var digested bool
 
 		buildOptions, err := cfg.buildOptions()
 		if err != nil {
-			return err
+			return f, err
 		}
 
 		// if image was specified, check if its digested and do basic validation
 		if cfg.Image != "" {
 			digested, err = isDigested(cfg.Image)
 			if err != nil {
-				return err
+				return f, err
 			}
 			if !digested {
 				// assign valid undigested image
@@ -203,7 +449,7 @@ func runRun(cmd *cobra.Command, newClient ClientFactory) (err error) {
 		} else {
 
 			if f, _, err = build(cmd, cfg.Build, f, client, buildOptions); err != nil {
-				return err
+				return f, err
 			}
 		}
 	} else {
@@ -211,52 +457,118 @@ func runRun(cmd *cobra.Command, newClient ClientFactory) (err error) {
 		if cfg.Image != "" {
 			digested, err := isDigested(cfg.Image)
 			if err != nil {
-				return err
+				return f, err
 			}
 			if digested {
-				return fmt.Errorf("cannot use digested image with --container=false")
+				return f, fmt.Errorf("cannot use digested image with --container=false")
 			}
 		}
 	}
+	return f, nil
+}
 
-	// Run
-	//
-	// Runs the code either via a container or the default host-based runner.
-	// For the former, build is required and a container runtime.  For the
-	// latter, scaffolding is first applied and the local host must be
-	// configured to build/run the language of the function.
-	job, err := client.Run(cmd.Context(), f)
+// runBroker starts a local broker which delivers events to the running
+// function per its subscriptions until the command's context is canceled.
+func runBroker(cmd *cobra.Command, cfg runConfig, f fn.Function, job *fn.Job) error {
+	out := cmd.OutOrStderr()
+	ln, err := net.Listen("tcp", net.JoinHostPort(job.Host, cfg.BrokerPort))
 	if err != nil {
-		return
+		return fmt.Errorf("unable to start the broker. %w", err)
 	}
-	defer func() {
-		if err = job.Stop(); err != nil {
-			fmt.Fprintf(cmd.OutOrStderr(), "Job stop error. %v", err)
+	b := fn.NewBroker(f, cfg.Broker, "http://"+net.JoinHostPort(job.Host, job.Port), cfg.BrokerSink, out)
+	if len(b.Subscriptions()) == 0 {
+		fmt.Fprintf(out, "Warning: the function has no subscriptions to the broker %q, so no events will be delivered\n", cfg.Broker)
+	}
+	go func() {
+		if err := b.Serve(cmd.Context(), ln); err != nil {
+			fmt.Fprintf(out, "Broker error. %v\n", err)
 		}
 	}()
+	fmt.Fprintf(out, "Broker %q accepting events at http://%v\n", cfg.Broker, ln.Addr())
+	return nil
+}
 
-	fmt.Fprintf(cmd.OutOrStderr(), "Running on host port %v\n", job.Port)
-
-	select {
-	case <-cmd.Context().Done():
-		if !errors.Is(cmd.Context().Err(), context.Canceled) {
-			err = cmd.Context().Err()
+// runWatch restarts the function each time its source code changes until
+// the command's context is canceled.  The function is reloaded (such that
+// changes to its func.yaml are also applied) and, if running in a container,
+// rebuilt.  The restarted job is bound to the host port of the first, such
+// that the function remains available at the same address.  A function which
+// fails to build or exits is restarted on the next change.
+func runWatch(cmd *cobra.Command, cfg runConfig, f fn.Function, client *fn.Client, job *fn.Job) (err error) {
+	var (
+		ctx  = cmd.Context()
+		out  = cmd.OutOrStderr()
+		port = job.Port
+		errs = job.Errors
+	)
+	stop := func() {
+		if job == nil {
+			return
 		}
-	case err = <-job.Errors:
+		if err := job.Stop(); err != nil {
+			fmt.Fprintf(out, "Job stop error. %v\n", err)
+		}
+		job, errs = nil, nil
+	}
+	defer stop()
+
+	changes, err := fn.Watch(ctx, f.Root, fn.DefaultWatchDebounce)
+	if err != nil {
 		return
-		// Bubble up runtime errors on the optional channel used for async job
-		// such as docker containers.
 	}
+	fmt.Fprintf(out, "Watching %v for changes\n", f.Root)
 
-	// NOTE: we do not f.Write() here unlike deploy (and build).
-	// running is ephemeral: a run is not affecting the function itself,
-	// as opposed to deploy commands, which are actually mutating the current
-	// state of the function as it exists on the network.
-	// Another way to think of this is that runs are development-centric tests,
-	// and thus most likely values changed such as environment variables,
-	// builder, etc. would not be expected to persist and affect the next deploy.
-	// Run is ephemeral, deploy is persistent.
-	return
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return nil
+			}
+			return ctx.Err()
+		case err := <-errs:
+			fmt.Fprintf(out, "Function exited. %v\nWaiting for changes\n", err)
+			stop()
+		case paths, ok := <-changes:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(out, "Changed: %v\nRestarting\n", strings.Join(paths, ", "))
+			stop()
+			if f, err = runReload(cmd, cfg, client); err != nil {
+				fmt.Fprintf(out, "%v\nWaiting for changes\n", err)
+				continue
+			}
+			runOptions := []fn.RunOption{fn.RunWithPort(port)}
+			if cfg.Debug {
+				runOptions = append(runOptions, fn.RunWithDebug(cfg.DebugPort))
+			}
+			if Format(cfg.Output) == JSON {
+				runOptions = append(runOptions, fn.RunWithOutput(cmd.ErrOrStderr(), cmd.ErrOrStderr()))
+			}
+			if job, err = client.Run(ctx, f, runOptions...); err != nil {
+				fmt.Fprintf(out, "Unable to restart the function. %v\nWaiting for changes\n", err)
+				job = nil
+				continue
+			}
+			errs = job.Errors
+			if job.Port != port {
+				fmt.Fprintf(out, "Warning: host port %v is no longer available\n", port)
+			}
+			fmt.Fprintf(out, "Running on host port %v\n", job.Port)
+		}
+	}
+}
+
+// runReload loads, configures and (if necessary) rebuilds the function
+// for a restart.
+func runReload(cmd *cobra.Command, cfg runConfig, client *fn.Client) (f fn.Function, err error) {
+	if f, err = fn.NewFunction(cfg.Path); err != nil {
+		return
+	}
+	if f, err = cfg.Configure(f); err != nil {
+		return
+	}
+	return buildForRun(cmd, cfg, f, client)
 }
 
 type runConfig struct {
@@ -276,6 +588,35 @@ type runConfig struct {
 	// StartTimeout optionally adjusts the startup timeout from the client's
 	// default of fn.DefaultStartTimeout.
 	StartTimeout time.Duration
+
+	// Watch the function's source code for changes, restarting on change.
+	Watch bool
+
+	// Detach runs the function in the background.
+	Detach bool
+
+	// FetchResources copies the Secrets and ConfigMaps referenced by the
+	// function from the cluster into its local resources before running.
+	FetchResources bool
+
+	// Broker is the name of the local broker to start, if any.
+	Broker string
+
+	// BrokerPort is the host port on which the local broker listens.
+	BrokerPort string
+
+	// BrokerSink is the URL to which the local broker sends replies.
+	BrokerSink string
+
+	// Debug runs the function such that a debugger may be attached.
+	Debug bool
+
+	// DebugPort is the host port on which the debugger listens, or empty
+	// for the runtime's default.
+	DebugPort string
+
+	// Workspace runs all functions of the workspace at Path.
+	Workspace bool
 }
 
 func newRunConfig(cmd *cobra.Command) (c runConfig) {
@@ -285,6 +626,16 @@ func newRunConfig(cmd *cobra.Command) (c runConfig) {
 		Env:          viper.GetStringSlice("env"),
 		Container:    viper.GetBool("container"),
 		StartTimeout: viper.GetDuration("start-timeout"),
+		Watch:        viper.GetBool("watch"),
+		Detach:       viper.GetBool("detach"),
+
+		FetchResources: viper.GetBool("fetch-resources"),
+		Broker:         viper.GetString("broker"),
+		BrokerPort:     viper.GetString("broker-port"),
+		BrokerSink:     viper.GetString("broker-sink"),
+		Debug:          viper.GetBool("debug"),
+		DebugPort:      viper.GetString("debug-port"),
+		Workspace:      viper.GetBool("workspace"),
 	}
 	// NOTE: .Env should be viper.GetStringSlice, but this returns unparsed
 	// results and appears to be an open issue since 2017:
@@ -312,6 +663,26 @@ func (c runConfig) Configure(f fn.Function) (fn.Function, error) {
 	return f, err
 }
 
+// ConfigureWorkspace configures a function of a workspace.  Unlike
+// Configure, the flags whose defaults are taken from the function in the
+// current directory (such as --image) are not applied, and the global
+// --registry and --builder apply only to a function which does not configure
+// its own.
+func (c runConfig) ConfigureWorkspace(f fn.Function) (fn.Function, error) {
+	var err error
+	if f.Registry == "" {
+		f.Registry = c.Registry
+	}
+	if f.Build.Builder == "" {
+		f.Build.Builder = c.Builder
+	}
+	if c.StartTimeout != 0 {
+		f.Run.StartTimeout = c.StartTimeout
+	}
+	f.Run.Envs, err = applyEnvs(f.Run.Envs, c.Env)
+	return f, err
+}
+
 func (c runConfig) Prompt() (runConfig, error) {
 	var err error
 
@@ -327,6 +698,42 @@ func (c runConfig) Prompt() (runConfig, error) {
 	return c, nil
 }
 
+// hostRunnable returns true if the host runner is implemented for the runtime.
+func hostRunnable(runtime string) bool {
+	switch runtime {
+	case "go", "python", "node", "typescript":
+		return true
+	default:
+		return false
+	}
+}
+
+// ValidateWorkspace validates the config for running a function of a
+// workspace.  Options which apply to a single function, or which require
+// that the function be attached to or run alone, are not supported.
+func (c runConfig) ValidateWorkspace(cmd *cobra.Command, f fn.Function) (err error) {
+	if err = c.Validate(cmd, f); err != nil {
+		return
+	}
+	for _, o := range []struct {
+		flag string
+		set  bool
+	}{
+		{"--image", c.Image != ""},
+		{"--builder-image", c.BuilderImage != ""},
+		{"--watch", c.Watch},
+		{"--detach", c.Detach},
+		{"--broker", c.Broker != ""},
+		{"--debug", c.Debug},
+		{"--output json", Format(c.Output) == JSON},
+	} {
+		if o.set {
+			return fmt.Errorf("%v can not be used with --workspace", o.flag)
+		}
+	}
+	return
+}
+
 func (c runConfig) Validate(cmd *cobra.Command, f fn.Function) (err error) {
 	// Bubble
 	if err = c.buildConfig.Validate(); err != nil {
@@ -340,15 +747,39 @@ func (c runConfig) Validate(cmd *cobra.Command, f fn.Function) (err error) {
 		}
 	}
 
-	// There is currently no local host runner implemented, so specifying
-	// --container=false should return an informative error for runtimes other
-	// than Go  that is more helpful than the cryptic, though correct, error
-	// from the Client that it was instantated without a runner.
-	// TODO: modify this check when the local host runner is available to
-	// only generate this error when --container==false && the --language is
-	// not yet implemented.
-	if !c.Container && f.Runtime != "go" {
-		return errors.New("the ability to run functions outside of a container via 'func run' is coming soon.")
+	// The local host runner is not yet implemented for all runtimes, so
+	// specifying --container=false should return an informative error for
+	// those runtimes that is more helpful than the cryptic, though correct,
+	// error from the Client that it was instantated without a runner.
+	if !c.Container && !hostRunnable(f.Runtime) {
+		return fmt.Errorf("the ability to run %v functions outside of a container via 'func run' is coming soon.", f.Runtime)
+	}
+
+	// Watching a containerized function requires it be rebuilt on change.
+	if c.Watch && c.Container {
+		if build, err := strconv.ParseBool(c.Build); err == nil && !build {
+			return errors.New("--watch requires that the container be built on change, so can not be used with --build=false")
+		}
+	}
+
+	// A detached function is not attached to, so can not be restarted.
+	if c.Watch && c.Detach {
+		return errors.New("--watch can not be used with --detach")
+	}
+
+	// The local broker runs in this process, so can not outlive it.
+	if c.Broker != "" && c.Detach {
+		return errors.New("--broker can not be used with --detach")
+	}
+
+	// The debug port is only meaningful when debugging.
+	if c.DebugPort != "" && !c.Debug {
+		return errors.New("--debug-port requires --debug")
+	}
+
+	// Local resources are only used by containerized runs.
+	if c.FetchResources && !c.Container {
+		return errors.New("--fetch-resources is only supported when running in a container")
 	}
 
 	// When the docker runner respects the StartTimeout, this validation check
@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -107,7 +112,7 @@ func TestRun_Run(t *testing.T) {
 
 			runner := mock.NewRunner()
 			if tt.runError != nil {
-				runner.RunFn = func(context.Context, fn.Function, time.Duration) (*fn.Job, error) { return nil, tt.runError }
+				runner.RunFn = func(context.Context, fn.Function, fn.RunOptions) (*fn.Job, error) { return nil, tt.runError }
 			}
 
 			builder := mock.NewBuilder()
@@ -220,7 +225,7 @@ func TestRun_Images(t *testing.T) {
 			runner := mock.NewRunner()
 
 			if tt.runError != nil {
-				runner.RunFn = func(context.Context, fn.Function, time.Duration) (*fn.Job, error) { return nil, tt.runError }
+				runner.RunFn = func(context.Context, fn.Function, fn.RunOptions) (*fn.Job, error) { return nil, tt.runError }
 			}
 
 			builder := mock.NewBuilder()
@@ -324,7 +329,7 @@ func TestRun_CorrectImage(t *testing.T) {
 			root := FromTempDirectory(t)
 			runner := mock.NewRunner()
 
-			runner.RunFn = func(_ context.Context, f fn.Function, _ time.Duration) (*fn.Job, error) {
+			runner.RunFn = func(_ context.Context, f fn.Function, _ fn.RunOptions) (*fn.Job, error) {
 				// TODO: add if for empty image? -- should fail beforehand
 				if f.Build.Image != tt.image {
 					return nil, fmt.Errorf("Expected image: %v but got: %v", tt.image, f.Build.Image)
@@ -394,7 +399,7 @@ func TestRun_DirectOverride(t *testing.T) {
 	root := FromTempDirectory(t)
 	runner := mock.NewRunner()
 
-	runner.RunFn = func(_ context.Context, f fn.Function, _ time.Duration) (*fn.Job, error) {
+	runner.RunFn = func(_ context.Context, f fn.Function, _ fn.RunOptions) (*fn.Job, error) {
 		if f.Build.Image != overrideImage {
 			return nil, fmt.Errorf("Expected image to be overridden with '%v' but got: '%v'", overrideImage, f.Build.Image)
 		}
@@ -462,3 +467,246 @@ func TestRun_DirectOverride(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestRun_Watch ensures that running with --watch rebuilds and restarts the
+// function when its source changes, requesting the same host port.
+func TestRun_Watch(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	ports := make(chan string, 10)
+	runner := mock.NewRunner()
+	runner.RunFn = func(_ context.Context, f fn.Function, opts fn.RunOptions) (*fn.Job, error) {
+		port := opts.Port
+		ports <- port
+		if port == "" {
+			port = "8081"
+		}
+		return fn.NewJob(f, "127.0.0.1", port, nil, nil, false)
+	}
+	builds := make(chan bool, 10)
+	builder := mock.NewBuilder()
+	builder.BuildFn = func(fn.Function) error {
+		builds <- true
+		return nil
+	}
+
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewRunCmd(NewTestClient(
+		fn.WithRunner(runner),
+		fn.WithBuilder(builder),
+		fn.WithRegistry("ghcr.com/reg"),
+	))
+	cmd.SetArgs([]string{"--watch"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		_, err := cmd.ExecuteContextC(ctx)
+		errs <- err
+	}()
+
+	receive := func() string {
+		select {
+		case port := <-ports:
+			return port
+		case err := <-errs:
+			t.Fatalf("run exited. %v", err)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for the function to run")
+		}
+		return ""
+	}
+
+	// Initial run chooses a port
+	if port := receive(); port != "" {
+		t.Fatalf("expected no preferred port on first run, got %q", port)
+	}
+
+	// A change rebuilds and restarts on the same port.  The watcher is
+	// started after the first run, so allow it time to do so.
+	time.Sleep(100 * time.Millisecond)
+	if err := os.WriteFile("handle.go", []byte("package function\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if port := receive(); port != "8081" {
+		t.Fatalf("expected restart on port 8081, got %q", port)
+	}
+	if len(builds) != 2 {
+		t.Fatalf("expected the function to be built twice, got %v", len(builds))
+	}
+
+	cancel()
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestRun_Detach ensures that running with --detach requests a detached job
+// and returns once it has started, leaving it running.
+func TestRun_Detach(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	var stopped bool
+	runner := mock.NewRunner()
+	runner.RunFn = func(_ context.Context, f fn.Function, opts fn.RunOptions) (*fn.Job, error) {
+		if !opts.Detach {
+			return nil, fmt.Errorf("expected a detached run")
+		}
+		stop := func() error { stopped = true; return nil }
+		return fn.NewJob(f, "127.0.0.1", "8080", nil, stop, false)
+	}
+
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewRunCmd(NewTestClient(
+		fn.WithRunner(runner),
+		fn.WithBuilder(mock.NewBuilder()),
+		fn.WithRegistry("ghcr.com/reg"),
+	))
+	cmd.SetArgs([]string{"--detach"})
+
+	// The command returns without its context being canceled.
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !runner.RunInvoked {
+		t.Fatal("run was not invoked")
+	}
+	if stopped {
+		t.Fatal("detached job was stopped on return")
+	}
+
+	// --watch can not be used with --detach
+	cmd.SetArgs([]string{"--detach", "--watch"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected --detach with --watch to error")
+	}
+
+	// --broker can not be used with --detach
+	cmd.SetArgs([]string{"--detach", "--broker"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected --detach with --broker to error")
+	}
+}
+
+// TestRun_Debug ensures that running with --debug requests that the function
+// be debuggable on the given port, and that --debug-port requires --debug.
+func TestRun_Debug(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	runner := mock.NewRunner()
+	runner.RunFn = func(_ context.Context, f fn.Function, opts fn.RunOptions) (*fn.Job, error) {
+		if !opts.Debug || opts.DebugPort != "40000" {
+			return nil, fmt.Errorf("expected a debug run on port 40000, got %+v", opts)
+		}
+		return fn.NewJob(f, "127.0.0.1", "8080", nil, func() error { return nil }, false)
+	}
+
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewRunCmd(NewTestClient(
+		fn.WithRunner(runner),
+		fn.WithBuilder(mock.NewBuilder()),
+		fn.WithRegistry("ghcr.com/reg"),
+	))
+	cmd.SetArgs([]string{"--debug", "--debug-port", "40000", "--detach"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd.SetArgs([]string{"--debug=false", "--debug-port", "40000", "--detach"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected --debug-port without --debug to error")
+	}
+}
+
+// TestRun_Workspace ensures that running with --workspace runs each function
+// of the workspace on its own port, on a shared network, with the URLs of its
+// siblings, with their output prefixed by name, and that all are stopped on
+// cancel.
+func TestRun_Workspace(t *testing.T) {
+	root := FromTempDirectory(t)
+	for _, name := range []string{"orders", "payments"} {
+		if _, err := fn.New().Init(fn.Function{Root: filepath.Join(root, name), Name: name, Runtime: "go"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(fn.WorkspaceFile, []byte("functions:\n  - path: orders\n  - path: payments\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu      sync.Mutex
+		ports   = map[string]string{}
+		envs    = map[string]map[string]string{}
+		stopped = map[string]bool{}
+		started = make(chan bool, 2)
+	)
+	runner := mock.NewRunner()
+	runner.RunFn = func(_ context.Context, f fn.Function, opts fn.RunOptions) (*fn.Job, error) {
+		if opts.Network == "" {
+			return nil, fmt.Errorf("expected a network")
+		}
+		ee, err := fn.Interpolate(f.Run.Envs)
+		if err != nil {
+			return nil, err
+		}
+		mu.Lock()
+		ports[f.Name], envs[f.Name] = opts.Port, ee
+		mu.Unlock()
+		fmt.Fprintf(opts.Stdout, "hello from %v\n", f.Name)
+		stop := func() error { mu.Lock(); stopped[f.Name] = true; mu.Unlock(); return nil }
+		started <- true
+		return fn.NewJob(f, "127.0.0.1", opts.Port, nil, stop, false)
+	}
+
+	out := &bytes.Buffer{}
+	cmd := NewRunCmd(NewTestClient(
+		fn.WithRunner(runner),
+		fn.WithBuilder(mock.NewBuilder()),
+		fn.WithRegistry("ghcr.com/reg"),
+	))
+	cmd.SetArgs([]string{"--workspace"})
+	cmd.SetOut(out)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { _, err := cmd.ExecuteContextC(ctx); errs <- err }()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case err := <-errs:
+			t.Fatal(err)
+		}
+	}
+	cancel()
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+
+	if ports["orders"] == "" || ports["orders"] == ports["payments"] {
+		t.Fatalf("expected distinct ports, got %v", ports)
+	}
+	if envs["orders"]["PAYMENTS_URL"] != "http://payments:8080" || envs["payments"]["ORDERS_URL"] != "http://orders:8080" {
+		t.Fatalf("expected the URLs of siblings, got %v", envs)
+	}
+	if _, ok := envs["orders"]["ORDERS_URL"]; ok {
+		t.Fatal("expected a function not to be provided its own URL")
+	}
+	if !stopped["orders"] || !stopped["payments"] {
+		t.Fatalf("expected all jobs to be stopped, got %v", stopped)
+	}
+	for _, line := range []string{"orders   | hello from orders\n", "payments | hello from payments\n"} {
+		if !strings.Contains(out.String(), line) {
+			t.Fatalf("expected output %q, got %q", line, out.String())
+		}
+	}
+}
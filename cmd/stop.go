@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/config"
+	"knative.dev/func/pkg/docker"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewStopCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stop [ID|NAME...]",
+		Short: "Stop functions running locally",
+		Long: `
+NAME
+	{{rootCmdUse}} stop - Stop functions running locally
+
+SYNOPSIS
+	{{rootCmdUse}} stop [ID|NAME...] [-a|--all] [-p|--path] [-v|--verbose]
+
+DESCRIPTION
+	Stops instances of functions running locally on this host, such as those
+	started using 'func run --detach'.
+
+	By default, all running instances of the function in the current
+	directory (or that specified with --path) are stopped.  Alternatively,
+	instances can be stopped by the IDs shown by 'func ps' (or any unique
+	prefix of a container ID), or by function name.  The --all flag stops all
+	functions running locally.
+
+EXAMPLES
+
+	o Stop the function in the current directory.
+	  $ {{rootCmdUse}} stop
+
+	o Stop the instance with ID 4242 as listed by 'func ps'.
+	  $ {{rootCmdUse}} stop 4242
+
+	o Stop all functions running locally.
+	  $ {{rootCmdUse}} stop --all
+`,
+		SuggestFor: []string{"stpo", "kill"},
+		PreRunE:    bindEnv("all", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStop(cmd, args, newClient)
+		},
+	}
+
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	cmd.Flags().BoolP("all", "a", false, "Stop all functions running locally. ($FUNC_ALL)")
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	return cmd
+}
+
+func runStop(cmd *cobra.Command, args []string, newClient ClientFactory) (err error) {
+	var (
+		all     = viper.GetBool("all")
+		verbose = viper.GetBool("verbose")
+		root    string
+	)
+	if all && len(args) > 0 {
+		return errors.New("both --all and instances to stop specified")
+	}
+	if !all && len(args) == 0 {
+		f, err := fn.NewFunction(viper.GetString("path"))
+		if err != nil {
+			return err
+		}
+		if !f.Initialized() {
+			return fn.NewErrNotInitialized(f.Root)
+		}
+		root = f.Root
+	}
+
+	// Functions run in containers are stopped using the container runner.
+	client, done := newClient(ClientConfig{Verbose: verbose},
+		fn.WithRunner(docker.NewRunner(verbose, os.Stdout, os.Stderr)))
+	defer done()
+
+	jobs, err := client.Jobs()
+	if err != nil {
+		return
+	}
+	if !all {
+		if jobs, err = selectJobs(jobs, args, root); err != nil {
+			return
+		}
+	}
+	if len(jobs) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "no matching functions running")
+		return
+	}
+
+	var errs []error
+	for _, j := range jobs {
+		if err := client.StopJob(cmd.Context(), j); err != nil {
+			errs = append(errs, fmt.Errorf("unable to stop %v (%v). %w", j.Name, j.ID(), err))
+			continue
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Stopped %v (%v) on port %v\n", j.Name, j.ID(), j.Port)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/docker"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+)
+
+// workspaceHost is the host interface on which the functions of a workspace
+// are run.
+const workspaceHost = "127.0.0.1"
+
+// runWorkspace builds and runs each function of the workspace at cfg.Path
+// on its own host port, providing each with the URLs of its siblings (see
+// fn.WithSiblingURLs).  Their output is combined, each line prefixed with
+// the name of its function.  All are stopped when the command's context is
+// canceled (such as on Ctrl-C) or when any one of them exits.
+func runWorkspace(cmd *cobra.Command, cfg runConfig, newClient ClientFactory) (err error) {
+	w, err := fn.LoadWorkspace(cfg.Path)
+	if err != nil {
+		return
+	}
+	ff, err := w.Load()
+	if err != nil {
+		return
+	}
+	for i := range ff {
+		if err = cfg.ValidateWorkspace(cmd, ff[i]); err != nil {
+			return fmt.Errorf("%v: %w", ff[i].Name, err)
+		}
+		if ff[i], err = cfg.ConfigureWorkspace(ff[i]); err != nil {
+			return
+		}
+	}
+
+	// Client
+	clientOptions, err := cfg.clientOptions()
+	if err != nil {
+		return
+	}
+	if cfg.Container {
+		clientOptions = append(clientOptions, fn.WithRunner(docker.NewRunner(cfg.Verbose, os.Stdout, os.Stderr)))
+	}
+	if cfg.StartTimeout != 0 {
+		clientOptions = append(clientOptions, fn.WithStartTimeout(cfg.StartTimeout))
+	}
+	client, done := newClient(ClientConfig{Verbose: cfg.Verbose}, clientOptions...)
+	defer done()
+
+	// Build
+	for i := range ff {
+		if ff[i], err = buildForRun(cmd, cfg, ff[i], client); err != nil {
+			return fmt.Errorf("%v: %w", ff[i].Name, err)
+		}
+		if cfg.FetchResources {
+			namespace := ff[i].Deploy.Namespace
+			if namespace == "" {
+				namespace = ff[i].Namespace
+			}
+			if err = k8s.FetchLocalResources(cmd.Context(), ff[i], namespace); err != nil {
+				return fmt.Errorf("%v: %w", ff[i].Name, err)
+			}
+		}
+	}
+
+	// Addresses
+	//
+	// Each function is run on its own host port.  When run in containers, the
+	// containers share a network on which each function is reached by its
+	// siblings at its name, rather than at the host port.
+	ports, err := workspacePorts(len(ff))
+	if err != nil {
+		return
+	}
+	var (
+		network string
+		urls    = make(map[string]string, len(ff))
+	)
+	if cfg.Container {
+		network = workspaceNetwork(w)
+	}
+	for i, f := range ff {
+		if network != "" {
+			urls[f.Name] = "http://" + net.JoinHostPort(f.Name, "8080") // the port within the container
+		} else {
+			urls[f.Name] = "http://" + net.JoinHostPort(workspaceHost, ports[i])
+		}
+	}
+
+	// Run
+	var (
+		out    = newPrefixedOutput(cmd.OutOrStdout(), ff)
+		jobs   = make([]*fn.Job, 0, len(ff))
+		exited = make(chan error, len(ff))
+	)
+	defer func() {
+		for i := len(jobs) - 1; i >= 0; i-- {
+			if err := jobs[i].Stop(); err != nil {
+				fmt.Fprintf(cmd.OutOrStderr(), "Job stop error. %v\n", err)
+			}
+		}
+	}()
+	for i, f := range ff {
+		runOptions := []fn.RunOption{
+			fn.RunWithPort(ports[i]),
+			fn.RunWithOutput(out.writer(f.Name), out.writer(f.Name)),
+		}
+		if network != "" {
+			runOptions = append(runOptions, fn.RunWithNetwork(network))
+		}
+		job, err := client.Run(cmd.Context(), fn.WithSiblingURLs(f, urls), runOptions...)
+		if err != nil {
+			return fmt.Errorf("%v: %w", f.Name, err)
+		}
+		jobs = append(jobs, job)
+		if job.Port != ports[i] {
+			return fmt.Errorf("%v: host port %v is no longer available", f.Name, ports[i])
+		}
+		fmt.Fprintf(cmd.OutOrStderr(), "%v running on host port %v\n", f.Name, job.Port)
+		go func(name string, errs chan error) {
+			exited <- fmt.Errorf("%v exited. %w", name, <-errs)
+		}(f.Name, job.Errors)
+	}
+
+	select {
+	case <-cmd.Context().Done():
+		if !errors.Is(cmd.Context().Err(), context.Canceled) {
+			err = cmd.Context().Err()
+		}
+	case err = <-exited:
+	}
+	return
+}
+
+// workspacePorts returns n distinct available host ports, starting with the
+// default port of the runner.
+func workspacePorts(n int) ([]string, error) {
+	ports := make([]string, 0, n)
+	for p := 8080; len(ports) < n; p++ {
+		if p > 65535 {
+			return ports, errors.New("unable to find available host ports")
+		}
+		port := strconv.Itoa(p)
+		l, err := net.Listen("tcp", net.JoinHostPort(workspaceHost, port))
+		if err != nil {
+			continue
+		}
+		l.Close()
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+// workspaceNetwork returns the name of the container network shared by the
+// functions of the workspace.
+func workspaceNetwork(w fn.Workspace) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(filepath.Base(w.Root)))
+	return "func-workspace-" + name
+}
+
+// prefixedOutput combines the output of the functions of a workspace, with
+// each line prefixed by the name of the function which wrote it.  Lines are
+// written whole, such that those of different functions are not interleaved.
+type prefixedOutput struct {
+	mu    sync.Mutex
+	out   io.Writer
+	width int
+}
+
+func newPrefixedOutput(out io.Writer, ff []fn.Function) *prefixedOutput {
+	o := &prefixedOutput{out: out}
+	for _, f := range ff {
+		if len(f.Name) > o.width {
+			o.width = len(f.Name)
+		}
+	}
+	return o
+}
+
+// writer returns a writer of the output of the named function.
+func (o *prefixedOutput) writer(name string) io.Writer {
+	return &prefixedWriter{o: o, prefix: fmt.Sprintf("%-*v | ", o.width, name)}
+}
+
+type prefixedWriter struct {
+	o      *prefixedOutput
+	prefix string
+	buf    []byte // incomplete line
+}
+
+func (w *prefixedWriter) Write(p []byte) (int, error) {
+	w.o.mu.Lock()
+	defer w.o.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if _, err := fmt.Fprintf(w.o.out, "%v%s", w.prefix, w.buf[:i+1]); err != nil {
+			return len(p), err
+		}
+		w.buf = w.buf[i+1:]
+	}
+}
@@ -6,8 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"regexp"
 	"runtime"
 	"strings"
 	"time"
@@ -21,6 +21,7 @@ import (
 
 	"knative.dev/func/pkg/builders"
 	"knative.dev/func/pkg/docker"
+	"knative.dev/func/pkg/funcignore"
 	fn "knative.dev/func/pkg/functions"
 )
 
@@ -133,22 +134,13 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 		buildpacks = defaultBuildpacks[f.Runtime]
 	}
 
-	// Reading .funcignore file
-	var excludes []string
-	filePath := filepath.Join(f.Root, ".funcignore")
-	file, err := os.Open(filePath)
+	// Exclude the paths ignored by the function's .funcignore files, which
+	// include the runtime data directory.  This holds local state, such as
+	// the local resources (Secrets) with which the function is run, which
+	// must not be included in the image.
+	excludes, err := excludedPaths(f.Root)
 	if err != nil {
-		if !os.IsNotExist(err) {
-			return fmt.Errorf("\nfailed to open file: %s", err)
-		}
-	} else {
-		defer file.Close()
-		buf := new(bytes.Buffer)
-		_, err := io.Copy(buf, file)
-		if err != nil {
-			return fmt.Errorf("\nfailed to read file: %s", err)
-		}
-		excludes = strings.Split(buf.String(), "\n")
+		return
 	}
 	// Pack build options
 	opts := pack.BuildOptions{
@@ -274,3 +266,21 @@ type ErrRuntimeNotSupported struct {
 func (e ErrRuntimeNotSupported) Error() string {
 	return fmt.Sprintf("Pack builder has no default builder image for the '%v' language runtime.  Please provide one.", e.Runtime)
 }
+
+// excludedPaths returns the paths of the function at root which are ignored
+// by its .funcignore files as pack exclusions.  Pack compiles exclusions
+// into regular expressions, so each path is rooted (such that it does not
+// match elsewhere) and quoted.  Pack itself escapes "." and "?", so these
+// are left as is rather than escaped twice.
+func excludedPaths(root string) ([]string, error) {
+	paths, err := funcignore.Ignored(root)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("cannot read %v. %w", funcignore.File, err)
+	}
+	unquote := strings.NewReplacer(`\.`, ".", `\?`, "?")
+	excludes := make([]string, len(paths))
+	for i, p := range paths {
+		excludes[i] = "/" + unquote.Replace(regexp.QuoteMeta(p))
+	}
+	return excludes, nil
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	pack "github.com/buildpacks/pack/pkg/client"
+	gitignore "github.com/sabhiram/go-gitignore"
 	"knative.dev/func/pkg/builders"
 	fn "knative.dev/func/pkg/functions"
 )
@@ -118,7 +119,8 @@ func TestBuild_BuilderImageConfigurable(t *testing.T) {
 }
 
 // TestBuild_BuilderImageExclude ensures that ignored files are not added to the func
-// image
+// image, including the runtime data directory, and that those re-included
+// are.
 func TestBuild_BuilderImageExclude(t *testing.T) {
 	var (
 		i = &mockImpl{} // mock underlying implementation
@@ -129,8 +131,10 @@ func TestBuild_BuilderImageExclude(t *testing.T) {
 		}
 	)
 	funcIgnoreContent := []byte(`#testing comments
-hello.txt`)
-	expected := []string{"hello.txt"}
+*.txt
+!keep.txt
+a.b`)
+	expected := []string{"/" + fn.RunDataDir, "/.funcignore", "/a.b", `/hello \(1\).txt`, "/hello.txt"}
 
 	tempdir := t.TempDir()
 	f.Root = tempdir
@@ -140,13 +144,31 @@ hello.txt`)
 	if err != nil {
 		t.Fatal(err)
 	}
+	for _, name := range []string{"hello.txt", "hello (1).txt", "keep.txt", "helloxtxt", "a.b", "axb"} {
+		if err = os.WriteFile(filepath.Join(f.Root, name), []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = os.Mkdir(filepath.Join(f.Root, fn.RunDataDir), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
 
 	i.BuildFn = func(ctx context.Context, opts pack.BuildOptions) error {
-		if len(opts.ProjectDescriptor.Build.Exclude) != 2 {
-			t.Fatalf("expected 2 lines of exclusions , got %v", len(opts.ProjectDescriptor.Build.Exclude))
+		excludes := opts.ProjectDescriptor.Build.Exclude
+		if !reflect.DeepEqual(excludes, expected) {
+			t.Fatalf("expected exclusions %v, got %v", expected, excludes)
 		}
-		if opts.ProjectDescriptor.Build.Exclude[1] != expected[0] {
-			t.Fatalf("expected excluded file to be '%v', got '%v'", expected[1], opts.ProjectDescriptor.Build.Exclude[1])
+		// The exclusions match exactly the ignored files when compiled by pack
+		gi := gitignore.CompileIgnoreLines(excludes...)
+		for _, name := range []string{"hello.txt", "hello (1).txt", "a.b", fn.RunDataDir + "/local.yaml"} {
+			if !gi.MatchesPath(name) {
+				t.Errorf("expected %v to be excluded", name)
+			}
+		}
+		for _, name := range []string{"keep.txt", "helloxtxt", "axb"} {
+			if gi.MatchesPath(name) {
+				t.Errorf("expected %v not to be excluded", name)
+			}
 		}
 		return nil
 	}
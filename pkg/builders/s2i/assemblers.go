@@ -55,11 +55,33 @@ else
 fi
 `
 
+// PythonRunner
+//
+// Replaces /usr/libexec/s2i/run within the UBI-8 python image such that the
+// function is started by its scaffolding in .s2i/builds/last, using the
+// virtual environment into which the image's assemble script installed the
+// function's requirements.
+const PythonRunner = `#!/bin/bash
+set -e
+exec python .s2i/builds/last/main.py
+`
+
 func assembler(f fn.Function) (string, error) {
 	switch f.Runtime {
 	case "go":
 		return GoAssembler, nil
+	case "python":
+		return "", nil // that of the S2I image
 	default:
 		return "", fmt.Errorf("no assembler defined for runtime %q", f.Runtime)
 	}
 }
+
+// runner returns the S2I run script of the runtime, or an empty string if
+// that provided in the S2I image is used.
+func runner(f fn.Function) string {
+	if f.Runtime == "python" {
+		return PythonRunner
+	}
+	return ""
+}
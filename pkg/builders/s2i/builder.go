@@ -34,6 +34,7 @@ import (
 
 	"knative.dev/func/pkg/builders"
 	"knative.dev/func/pkg/docker"
+	"knative.dev/func/pkg/funcignore"
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/scaffolding"
 )
@@ -151,18 +152,14 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 		client = c
 	}
 
-	// Link .s2iignore -> .funcignore
-	funcignorePath := filepath.Join(f.Root, ".funcignore")
+	// Write the paths ignored by the .funcignore files to .s2iignore
 	s2iignorePath := filepath.Join(f.Root, ".s2iignore")
-	if _, err := os.Stat(funcignorePath); err == nil {
-		if _, err := os.Stat(s2iignorePath); err == nil {
-			fmt.Fprintln(os.Stderr, "Warning: an existing .s2iignore was detected.  Using this with preference over .funcignore")
-		} else {
-			if err = os.Symlink("./.funcignore", s2iignorePath); err != nil {
-				return err
-			}
-			defer os.Remove(s2iignorePath)
-		}
+	if _, err := os.Stat(s2iignorePath); err == nil {
+		fmt.Fprintln(os.Stderr, "Warning: an existing .s2iignore was detected.  Using this with preference over .funcignore")
+	} else if ok, err := writeS2IIgnore(f.Root, s2iignorePath); err != nil {
+		return err
+	} else if ok {
+		defer os.Remove(s2iignorePath)
 	}
 
 	// Build directory
@@ -442,8 +439,8 @@ func BuilderImage(f fn.Function, builderName string) (string, error) {
 // Returns a config with settings suitable for building runtimes which
 // support scaffolding.
 func scaffold(cfg *api.Config, f fn.Function) (*api.Config, error) {
-	// Scafffolding is currently only supported by the Go runtime
-	if f.Runtime != "go" {
+	// Scafffolding is currently only supported by the Go and Python runtimes
+	if f.Runtime != "go" && f.Runtime != "python" {
 		return cfg, nil
 	}
 
@@ -460,6 +457,11 @@ func scaffold(cfg *api.Config, f fn.Function) (*api.Config, error) {
 
 	// Write scaffolding to .s2i/builds/last
 	err = scaffolding.Write(appRoot, f.Root, f.Runtime, f.Invoke, embeddedRepo.FS())
+	if f.Runtime == "python" && errors.Is(err, scaffolding.ErrSignatureNotFound) {
+		// Python functions which implement none of the known signatures
+		// (such as those served by gunicorn) are run by the S2I image.
+		return cfg, nil
+	}
 	if err != nil {
 		return cfg, fmt.Errorf("unable to build due to a scaffold error. %w", err)
 	}
@@ -479,6 +481,16 @@ func scaffold(cfg *api.Config, f fn.Function) (*api.Config, error) {
 		}
 	}
 
+	// Likewise the run script, for runtimes started by their scaffolding.
+	if run := runner(f); run != "" {
+		if err := os.MkdirAll(filepath.Join(f.Root, ".s2i", "bin"), 0755); err != nil {
+			return nil, fmt.Errorf("unable to create .s2i bin dir. %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(f.Root, ".s2i", "bin", "run"), []byte(run), 0700); err != nil {
+			return nil, fmt.Errorf("unable to write %v run script. %w", f.Runtime, err)
+		}
+	}
+
 	cfg.KeepSymlinks = true // Don't infinite loop on the symlink to root.
 
 	// We want to force that the system use the (copy via filesystem)
@@ -490,3 +502,25 @@ func scaffold(cfg *api.Config, f fn.Function) (*api.Config, error) {
 
 	return cfg, nil
 }
+
+// writeS2IIgnore writes the paths of the function at root which are ignored
+// by its .funcignore files to the .s2iignore at path, returning false if no
+// paths are ignored (such as when the function has no source), in which case
+// nothing is written.  An .s2iignore is a list of globs (without the
+// semantics of a .gitignore), so each path is written rooted (such that a
+// leading "#" or "!" is not special) with its glob metacharacters quoted.
+func writeS2IIgnore(root, path string) (bool, error) {
+	paths, err := funcignore.Ignored(root)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, fmt.Errorf("cannot read %v. %w", funcignore.File, err)
+	}
+	if len(paths) == 0 {
+		return false, nil
+	}
+	quote := strings.NewReplacer("*", "[*]", "?", "[?]", "[", "[[]")
+	b := strings.Builder{}
+	for _, p := range paths {
+		fmt.Fprintf(&b, "/%v\n", quote.Replace(p))
+	}
+	return true, os.WriteFile(path, []byte(b.String()), 0644)
+}
@@ -229,6 +229,48 @@ hello.txt
 	}
 }
 
+// Test_BuildPythonScaffolding ensures that Python functions are built with
+// their scaffolding, which is started by the run script, unless they
+// implement none of the known signatures.
+func Test_BuildPythonScaffolding(t *testing.T) {
+	root, done := Mktemp(t)
+	defer done()
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "python"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := &mockImpl{BuildFn: func(cfg *api.Config) (*api.Result, error) {
+		return &api.Result{}, nil
+	}}
+	b := s2i.NewBuilder(s2i.WithImpl(i), s2i.WithDockerClient(mockDocker{}))
+	if err = b.Build(context.Background(), f, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(root, ".s2i", "builds", "last", "main.py")); err != nil {
+		t.Fatalf("expected the scaffolding to be written. %v", err)
+	}
+	run, err := os.ReadFile(filepath.Join(root, ".s2i", "bin", "run"))
+	if err != nil || string(run) != s2i.PythonRunner {
+		t.Fatalf("expected the python run script, got %q (%v)", run, err)
+	}
+
+	// Functions which implement no known signature are run by the image.
+	root, done = Mktemp(t)
+	defer done()
+	if f, err = fn.New().Init(fn.Function{Root: root, Runtime: "python"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, "func.py"), []byte("app = None\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = b.Build(context.Background(), f, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(root, ".s2i", "bin", "run")); !os.IsNotExist(err) {
+		t.Fatalf("expected no run script without a known signature. %v", err)
+	}
+}
+
 // Test_Verbose ensures that the verbosity flag is propagated to the
 // S2I builder implementation.
 func Test_BuilderVerbose(t *testing.T) {
@@ -22,6 +22,9 @@ const (
 	// Repositories is the default directory for repositoires.
 	Repositories = "repositories"
 
+	// Jobs is the directory in which functions running locally are recorded.
+	Jobs = "jobs"
+
 	// DefaultLanguage is intentionaly undefined.
 	DefaultLanguage = ""
 
@@ -201,6 +204,12 @@ func RepositoriesPath() string {
 	return path
 }
 
+// JobsPath returns the full path at which functions running locally on this
+// host are recorded.
+func JobsPath() string {
+	return filepath.Join(Dir(), Jobs)
+}
+
 // CreatePaths is a convenience function for creating the on-disk func config
 // structure.  All operations should be tolerant of nonexistant disk
 // footprint where possible (for example listing repositories should not
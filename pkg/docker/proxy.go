@@ -0,0 +1,61 @@
+package docker
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// queueDepthFactor is the number of requests per concurrent request which
+// may be queued awaiting the function, as by the Knative queue-proxy.
+const queueDepthFactor = 10
+
+// concurrencyLimit returns the function's container concurrency limit, or
+// zero if unlimited.
+func concurrencyLimit(f fn.Function) int64 {
+	r := f.Deploy.Options.Resources
+	if r == nil || r.Limits == nil || r.Limits.Concurrency == nil {
+		return 0
+	}
+	return *r.Limits.Concurrency
+}
+
+// concurrencyProxy forwards requests to a function's container, limiting the
+// number in flight to the function's container concurrency as does the
+// Knative queue-proxy: requests beyond the limit are queued until one in
+// flight completes, and those beyond the depth of the queue are rejected
+// with 503 Service Unavailable.
+type concurrencyProxy struct {
+	proxy    *httputil.ReverseProxy
+	inflight chan struct{} // requests being handled by the function
+	pending  chan struct{} // requests in flight or queued
+}
+
+// newConcurrencyProxy returns a proxy to target which limits requests in
+// flight to limit.
+func newConcurrencyProxy(target *url.URL, limit int64) *concurrencyProxy {
+	return &concurrencyProxy{
+		proxy:    httputil.NewSingleHostReverseProxy(target),
+		inflight: make(chan struct{}, limit),
+		pending:  make(chan struct{}, limit+limit*queueDepthFactor),
+	}
+}
+
+func (p *concurrencyProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	select {
+	case p.pending <- struct{}{}:
+		defer func() { <-p.pending }()
+	default:
+		http.Error(w, "pending request queue full", http.StatusServiceUnavailable)
+		return
+	}
+	select {
+	case p.inflight <- struct{}{}:
+		defer func() { <-p.inflight }()
+	case <-r.Context().Done():
+		return // abandoned while queued
+	}
+	p.proxy.ServeHTTP(w, r)
+}
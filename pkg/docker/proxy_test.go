@@ -0,0 +1,86 @@
+//go:build !integration
+// +build !integration
+
+package docker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestConcurrencyProxy ensures that requests in flight to the function are
+// limited, that requests beyond the limit are queued, and that requests
+// beyond the depth of the queue are rejected.
+func TestConcurrencyProxy(t *testing.T) {
+	var (
+		release  = make(chan struct{})
+		inflight atomic.Int64
+		max      atomic.Int64
+	)
+	function := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inflight.Add(1)
+		defer inflight.Add(-1)
+		if n > max.Load() {
+			max.Store(n)
+		}
+		<-release
+	}))
+	defer function.Close()
+	target, _ := url.Parse(function.URL)
+
+	p := newConcurrencyProxy(target, 1)
+	proxy := httptest.NewServer(p)
+	defer proxy.Close()
+
+	// Fill the queue: one request in flight and the remainder queued.
+	var wg sync.WaitGroup
+	codes := make(chan int, cap(p.pending))
+	for i := 0; i < cap(p.pending); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Get(proxy.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+			codes <- resp.StatusCode
+		}()
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(p.pending) < cap(p.pending) {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %v pending requests, got %v", cap(p.pending), len(p.pending))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Rejected once full
+	resp, err := http.Get(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 when the queue is full, got %v", resp.StatusCode)
+	}
+
+	// The queued requests are each eventually handled, one at a time.
+	close(release)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		if code != http.StatusOK {
+			t.Fatalf("expected 200, got %v", code)
+		}
+	}
+	if max.Load() != 1 {
+		t.Fatalf("expected at most 1 request in flight, got %v", max.Load())
+	}
+}
@@ -244,7 +244,16 @@ func (n *Pusher) daemonPush(ctx context.Context, f fn.Function, credentials Cred
 		isTerminal = term.IsTerminal(int(outF.Fd()))
 	}
 
-	err = jsonmessage.DisplayJSONMessagesStream(r, output, fd, isTerminal, nil)
+	// The messages are also decoded to report the layers pushed.
+	pr, pw := io.Pipe()
+	reported := make(chan struct{})
+	go func() {
+		reportLayers(ctx, f, pr)
+		close(reported)
+	}()
+	err = jsonmessage.DisplayJSONMessagesStream(io.TeeReader(r, pw), output, fd, isTerminal, nil)
+	_ = pw.Close()
+	<-reported
 	if err != nil {
 		return "", err
 	}
@@ -252,6 +261,23 @@ func (n *Pusher) daemonPush(ctx context.Context, f fn.Function, credentials Cred
 	return ParseDigest(outBuff.String()), nil
 }
 
+// reportLayers decodes the messages of a push by the daemon, reporting the
+// layers which are in the registry once pushed.  The messages are consumed
+// in full regardless of decoding errors.
+func reportLayers(ctx context.Context, f fn.Function, r io.Reader) {
+	defer func() { _, _ = io.Copy(io.Discard, r) }()
+	dec := json.NewDecoder(r)
+	for {
+		var m jsonmessage.JSONMessage
+		if err := dec.Decode(&m); err != nil {
+			return
+		}
+		if m.ID != "" && (m.Status == "Pushed" || m.Status == "Layer already exists" || strings.HasPrefix(m.Status, "Mounted from")) {
+			fn.ReportProgress(ctx, fn.ProgressEvent{Type: fn.ProgressLayerPushed, Function: f.Name, Layer: m.ID})
+		}
+	}
+}
+
 var digestRE = regexp.MustCompile(`digest:\s+(sha256:\w{64})`)
 
 // ParseDigest tries to parse the last line from the output, which holds the pushed image digest
@@ -294,12 +320,19 @@ func (n *Pusher) push(ctx context.Context, f fn.Function, credentials Credential
 	go func() {
 		defer fmt.Fprint(output, "\n")
 
+		reported := int64(-1)
 		for progress := range progressChannel {
 			if progress.Error != nil {
 				errChan <- progress.Error
 				return
 			}
-			fmt.Fprintf(output, "\rprogress: %d%%", progress.Complete*100/progress.Total)
+			percent := progress.Complete * 100 / progress.Total
+			fmt.Fprintf(output, "\rprogress: %d%%", percent)
+			if percent != reported { // reported each percent rather than each update
+				reported = percent
+				fn.ReportProgress(ctx, fn.ProgressEvent{Type: fn.ProgressPushUpdated,
+					Function: f.Name, Complete: progress.Complete, Total: progress.Total})
+			}
 		}
 
 		errChan <- nil
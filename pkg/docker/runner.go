@@ -1,19 +1,32 @@
 package docker
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	fn "knative.dev/func/pkg/functions"
 )
@@ -49,14 +62,20 @@ func NewRunner(verbose bool, out, errOut io.Writer) *Runner {
 	}
 }
 
-// Run the function.
-func (n *Runner) Run(ctx context.Context, f fn.Function, startTimeout time.Duration) (job *fn.Job, err error) {
+// Run the function.  Detached containers are not attached to, and continue
+// to run after this process exits.
+func (n *Runner) Run(ctx context.Context, f fn.Function, opts fn.RunOptions) (job *fn.Job, err error) {
+
+	port := opts.Port
+	if port == "" {
+		port = DefaultPort
+	}
+	port = choosePort(DefaultHost, port, DefaultDialTimeout)
 
 	var (
-		port = choosePort(DefaultHost, DefaultPort, DefaultDialTimeout)
 		c    client.CommonAPIClient // Docker client
 		id   string                 // ID of running container
-		conn net.Conn               // Connection to container's stdio
+		conn net.Conn               // Connection to container's stdio (if attached)
 
 		// Channels for gathering runtime errors from the container instance
 		copyErrCh  = make(chan error, 10)
@@ -70,14 +89,76 @@ func (n *Runner) Run(ctx context.Context, f fn.Function, startTimeout time.Durat
 	if f.Build.Image == "" {
 		return job, errors.New("Function has no associated image. Has it been built?")
 	}
+
 	if c, _, err = NewClient(client.DefaultDockerHost); err != nil {
 		return job, errors.Wrap(err, "failed to create Docker API client")
 	}
-	if id, err = newContainer(ctx, c, f, port, n.verbose); err != nil {
+
+	// The debug agent of the runtime is enabled within the container, and
+	// its port published on the host.
+	var (
+		agent     *debugAgent
+		debugPort string
+	)
+	if opts.Debug {
+		if agent, err = newImageDebugAgent(ctx, c, f); err != nil {
+			return
+		}
+		if debugPort = opts.DebugPort; debugPort == "" {
+			debugPort = fn.DefaultDebugPort(f.Runtime)
+		}
+	}
+
+	// Output is written to the runner's writers unless otherwise requested.
+	out, errOut := n.out, n.errOut
+	if opts.Stdout != nil {
+		out = opts.Stdout
+	}
+	if opts.Stderr != nil {
+		errOut = opts.Stderr
+	}
+
+	// The container joins the requested network, if any, on which it is
+	// reachable by other containers at the function's name.
+	if opts.Network != "" {
+		if err = ensureNetwork(ctx, c, opts.Network); err != nil {
+			return job, errors.Wrap(err, "runner unable to create network")
+		}
+	}
+
+	// Requests to a function with a container concurrency limit are forwarded
+	// to its container by a proxy on the job's port which enforces the limit.
+	// The proxy runs in this process, so the limit is not enforced for
+	// detached runs.
+	var (
+		containerPort = port
+		limit         = concurrencyLimit(f)
+		proxyLn       net.Listener
+		proxy         *http.Server
+	)
+	if limit > 0 && opts.Detach {
+		fmt.Fprintf(errOut, "Warning: the concurrency limit of %v is not enforced for detached runs\n", limit)
+		limit = 0
+	}
+	if limit > 0 {
+		if proxyLn, err = net.Listen("tcp", net.JoinHostPort(DefaultHost, port)); err != nil {
+			return job, errors.Wrap(err, "runner unable to listen for requests")
+		}
+		defer func() {
+			if err != nil {
+				proxyLn.Close()
+			}
+		}()
+		containerPort = choosePort(DefaultHost, port, DefaultDialTimeout) // port is bound by the proxy
+	}
+
+	if id, err = newContainer(ctx, c, f, containerPort, debugPort, agent, opts.Network, n.verbose); err != nil {
 		return job, errors.Wrap(err, "runner unable to create container")
 	}
-	if conn, err = copyStdio(ctx, c, id, copyErrCh, n.out, n.errOut); err != nil {
-		return
+	if !opts.Detach {
+		if conn, err = copyStdio(ctx, c, id, copyErrCh, out, errOut); err != nil {
+			return
+		}
 	}
 
 	// Wait for errors premature exits
@@ -94,7 +175,7 @@ func (n *Runner) Run(ctx context.Context, f fn.Function, startTimeout time.Durat
 				// of their own accord when run locally.  Should this expectation
 				// change in the future, this channel-based wait may need to be
 				// expanded to accept the case of a voluntary, successful exit.
-				runtimeErrCh <- fmt.Errorf("exited code %v", body.StatusCode)
+				runtimeErrCh <- exitError(f, body.StatusCode)
 			case err = <-contErrCh:
 				runtimeErrCh <- err
 			}
@@ -110,24 +191,33 @@ func (n *Runner) Run(ctx context.Context, f fn.Function, startTimeout time.Durat
 		return job, errors.Wrap(err, "runner unable to start container")
 	}
 
+	// Proxy
+	if limit > 0 {
+		target := &url.URL{Scheme: "http", Host: net.JoinHostPort(DefaultHost, containerPort)}
+		proxy = &http.Server{Handler: newConcurrencyProxy(target, limit)}
+		go func() {
+			if err := proxy.Serve(proxyLn); err != http.ErrServerClosed {
+				runtimeErrCh <- err
+			}
+		}()
+	}
+
 	// Stopper
 	stop := func() error {
-		var (
-			timeout = DefaultStopTimeout
-			ctx     = context.Background()
-		)
-		timeoutSecs := int(timeout.Seconds())
-		ctrStopOpts := container.StopOptions{
-			Timeout: &timeoutSecs,
+		if proxy != nil {
+			_ = proxy.Close()
 		}
-		if err = c.ContainerStop(ctx, id, ctrStopOpts); err != nil {
-			return fmt.Errorf("error stopping container %v: %v\n", id, err)
+		if err = stopContainer(context.Background(), c, id); err != nil {
+			return err
 		}
-		if err = c.ContainerRemove(ctx, id, container.RemoveOptions{}); err != nil {
-			return fmt.Errorf("error removing container %v: %v\n", id, err)
+		if opts.Network != "" {
+			// Removal fails while other containers remain on the network.
+			_ = c.NetworkRemove(context.Background(), opts.Network)
 		}
-		if err = conn.Close(); err != nil {
-			return fmt.Errorf("error closing connection to container: %v\n", err)
+		if conn != nil {
+			if err = conn.Close(); err != nil {
+				return fmt.Errorf("error closing connection to container: %v\n", err)
+			}
 		}
 		if err = c.Close(); err != nil {
 			return fmt.Errorf("error closing daemon client: %v\n", err)
@@ -136,7 +226,83 @@ func (n *Runner) Run(ctx context.Context, f fn.Function, startTimeout time.Durat
 	}
 
 	// Job reporting port, runtime errors and provides a mechanism for stopping.
-	return fn.NewJob(f, DefaultHost, port, runtimeErrCh, stop, n.verbose)
+	if job, err = fn.NewJob(f, DefaultHost, port, runtimeErrCh, stop, n.verbose); err != nil {
+		return
+	}
+	job.Detached = opts.Detach
+	job.DebugPort = debugPort
+	job.Container = id
+	job.Image = f.Build.Image
+	return
+}
+
+// StopJob stops and removes the container of a job, which may have been
+// started by another process.
+func (n *Runner) StopJob(ctx context.Context, job fn.JobInfo) (err error) {
+	c, _, err := NewClient(client.DefaultDockerHost)
+	if err != nil {
+		return errors.Wrap(err, "failed to create Docker API client")
+	}
+	defer c.Close()
+	if n.verbose {
+		fmt.Fprintf(n.out, "Stopping container %v\n", job.ID())
+	}
+	return stopContainer(ctx, c, job.Container)
+}
+
+// JobLogs writes the output of the container of a job to w, following it
+// until the container exits or the context is canceled if requested.
+func (n *Runner) JobLogs(ctx context.Context, job fn.JobInfo, follow bool, w io.Writer) (err error) {
+	c, _, err := NewClient(client.DefaultDockerHost)
+	if err != nil {
+		return errors.Wrap(err, "failed to create Docker API client")
+	}
+	defer c.Close()
+	r, err := c.ContainerLogs(ctx, job.Container, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     follow,
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to read container logs")
+	}
+	defer r.Close()
+	if _, err = stdcopy.StdCopy(w, w, r); err != nil && ctx.Err() != nil {
+		err = nil // following was canceled
+	}
+	return
+}
+
+// JobRunning returns true if the container of a job is running.  A container
+// which no longer exists is not running.
+func (n *Runner) JobRunning(ctx context.Context, job fn.JobInfo) (bool, error) {
+	c, _, err := NewClient(client.DefaultDockerHost)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create Docker API client")
+	}
+	defer c.Close()
+	info, err := c.ContainerInspect(ctx, job.Container)
+	if client.IsErrNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return info.State != nil && info.State.Running, nil
+}
+
+// stopContainer stops and removes the container of the given ID.
+func stopContainer(ctx context.Context, c client.CommonAPIClient, id string) (err error) {
+	timeoutSecs := int(DefaultStopTimeout.Seconds())
+	ctrStopOpts := container.StopOptions{
+		Timeout: &timeoutSecs,
+	}
+	if err = c.ContainerStop(ctx, id, ctrStopOpts); err != nil {
+		return fmt.Errorf("error stopping container %v: %v\n", id, err)
+	}
+	if err = c.ContainerRemove(ctx, id, container.RemoveOptions{}); err != nil {
+		return fmt.Errorf("error removing container %v: %v\n", id, err)
+	}
+	return nil
 }
 
 // Dial the given (tcp) port on the given interface, returning an error if it is
@@ -178,25 +344,49 @@ func choosePort(host, preferredPort string, dialTimeout time.Duration) string {
 
 }
 
-func newContainer(ctx context.Context, c client.CommonAPIClient, f fn.Function, port string, verbose bool) (id string, err error) {
+func newContainer(ctx context.Context, c client.CommonAPIClient, f fn.Function, port, debugPort string, agent *debugAgent, networkName string, verbose bool) (id string, err error) {
 	var (
 		containerCfg container.Config
 		hostCfg      container.HostConfig
+		networkCfg   *network.NetworkingConfig
 	)
-	if containerCfg, err = newContainerConfig(f, port, verbose); err != nil {
+	if containerCfg, err = newContainerConfig(f, agent, verbose); err != nil {
 		return
 	}
-	if hostCfg, err = newHostConfig(port); err != nil {
+	if hostCfg, err = newHostConfig(f, port, debugPort, agent); err != nil {
 		return
 	}
-	t, err := c.ContainerCreate(ctx, &containerCfg, &hostCfg, nil, nil, "")
+	if networkName != "" {
+		networkCfg = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				networkName: {Aliases: []string{f.Name}},
+			},
+		}
+	}
+	t, err := c.ContainerCreate(ctx, &containerCfg, &hostCfg, networkCfg, nil, "")
 	if err != nil {
 		return
 	}
+	if agent != nil && agent.delve != "" {
+		if err = copyDelve(ctx, c, t.ID, agent.delve); err != nil {
+			_ = c.ContainerRemove(ctx, t.ID, container.RemoveOptions{Force: true})
+			return
+		}
+	}
 	return t.ID, nil
 }
 
-func newContainerConfig(f fn.Function, _ string, verbose bool) (c container.Config, err error) {
+// ensureNetwork creates the named network if it does not already exist.
+func ensureNetwork(ctx context.Context, c client.CommonAPIClient, name string) error {
+	_, err := c.NetworkInspect(ctx, name, network.InspectOptions{})
+	if err == nil || !errdefs.IsNotFound(err) {
+		return err
+	}
+	_, err = c.NetworkCreate(ctx, name, network.CreateOptions{Driver: "bridge"})
+	return err
+}
+
+func newContainerConfig(f fn.Function, agent *debugAgent, verbose bool) (c container.Config, err error) {
 	// httpPort := nat.Port(fmt.Sprintf("%v/tcp", port))
 	httpPort := nat.Port("8080/tcp")
 	c = container.Config{
@@ -209,9 +399,10 @@ func newContainerConfig(f fn.Function, _ string, verbose bool) (c container.Conf
 	}
 
 	// Environment Variables
-	// Interpolate references to local environment variables and convert to a
+	// Interpolate references to local environment variables and to Secrets
+	// and ConfigMaps in the function's local resources, and convert to a
 	// simple string slice for use with container.Config
-	envs, err := fn.Interpolate(f.Run.Envs)
+	envs, err := fn.NewLocalResources(f).Interpolate(f.Run.Envs)
 	if err != nil {
 		return
 	}
@@ -222,10 +413,134 @@ func newContainerConfig(f fn.Function, _ string, verbose bool) (c container.Conf
 		c.Env = append(c.Env, "VERBOSE=true")
 	}
 
+	// Debug Agent
+	if agent != nil {
+		if agent.env != "" {
+			c.Env = append(c.Env, agent.env)
+		}
+		if len(agent.cmd) > 0 {
+			c.Entrypoint = agent.cmd // runs the image's command
+		}
+		c.ExposedPorts[agent.port] = struct{}{}
+	}
+
 	return
 }
 
-func newHostConfig(port string) (c container.HostConfig, err error) {
+// delvePath is the path within the container of a function to which Delve
+// is copied when debugging.
+const delvePath = "/func-debug/dlv"
+
+// debugpyScript installs debugpy into the container of a Python function if
+// its environment does not already include it, and runs the function's
+// script ($0 being the function's python) under it.
+const debugpyScript = `if ! "$0" -c 'import debugpy' 2>/dev/null; then
+  "$0" -m pip install --quiet --disable-pip-version-check --target /tmp/debugpy debugpy
+  export PYTHONPATH="/tmp/debugpy${PYTHONPATH:+:$PYTHONPATH}"
+fi
+exec "$0" -m debugpy --listen 0.0.0.0:5678 "$@"`
+
+// debugAgent of a function's runtime within its container.  The agent is
+// either enabled by an environment variable of the runtime, or is the
+// command of the container, which runs the command of the function's image
+// under it.
+type debugAgent struct {
+	env   string   // environment variable which enables the agent, if any
+	cmd   []string // command which runs the function under the agent, if any
+	delve string   // path of the Delve to copy into the container, if any
+	port  nat.Port // container port on which the agent listens
+}
+
+// newImageDebugAgent returns the debug agent of the function's runtime for
+// the command of its built image.
+func newImageDebugAgent(ctx context.Context, c client.CommonAPIClient, f fn.Function) (*debugAgent, error) {
+	switch f.Runtime {
+	case "go", "python":
+		img, _, err := c.ImageInspectWithRaw(ctx, f.Build.Image)
+		if err != nil {
+			return nil, errors.Wrap(err, "runner unable to inspect image")
+		}
+		var command []string
+		if img.Config != nil {
+			command = append(append(command, img.Config.Entrypoint...), img.Config.Cmd...)
+		}
+		return newDebugAgent(f.Runtime, command)
+	default:
+		return newDebugAgent(f.Runtime, nil)
+	}
+}
+
+// newDebugAgent returns the debug agent of the runtime for a container whose
+// image runs the given command.  The Node inspector and JDWP are enabled by
+// the runtime itself.  Go functions are run under Delve, copied into the
+// container from the host, and Python functions under debugpy, installed
+// into the container if necessary.
+func newDebugAgent(runtime string, command []string) (*debugAgent, error) {
+	switch runtime {
+	case "node", "typescript":
+		return &debugAgent{env: "NODE_OPTIONS=--inspect=0.0.0.0:9229", port: "9229/tcp"}, nil
+	case "quarkus", "springboot":
+		return &debugAgent{env: "JAVA_TOOL_OPTIONS=-agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=*:5005", port: "5005/tcp"}, nil
+	case "go":
+		if len(command) == 0 {
+			return nil, errors.New("unable to debug the function: its image has no command")
+		}
+		dlv, err := hostDelve()
+		if err != nil {
+			return nil, err
+		}
+		cmd := []string{delvePath, "exec", "--headless", "--listen=:2345", "--api-version=2", "--accept-multiclient", "--continue", command[0], "--"}
+		return &debugAgent{cmd: append(cmd, command[1:]...), delve: dlv, port: "2345/tcp"}, nil
+	case "python":
+		if len(command) == 0 || !strings.HasPrefix(path.Base(command[0]), "python") {
+			return nil, fmt.Errorf("unable to debug the function: the command of its image %q does not run python", command)
+		}
+		cmd := []string{"/bin/sh", "-c", debugpyScript}
+		return &debugAgent{cmd: append(cmd, command...), port: "5678/tcp"}, nil
+	default:
+		return nil, fn.ErrDebugNotSupported{Runtime: runtime, Container: true}
+	}
+}
+
+// hostDelve returns the path of the Delve of the host, which must be a linux
+// host for Delve to run within the container.
+func hostDelve() (string, error) {
+	if runtime.GOOS != "linux" {
+		return "", fmt.Errorf("debugging go functions in a container requires Delve (dlv) for linux, but the host is %v", runtime.GOOS)
+	}
+	dlv, err := exec.LookPath("dlv")
+	if err != nil {
+		return "", errors.New("dlv not found.  Delve is required to debug Go functions.  See https://github.com/go-delve/delve")
+	}
+	return dlv, nil
+}
+
+// copyDelve copies the Delve at the given path into the container, at the
+// delvePath.  It is copied rather than mounted such that it is available in
+// containers of a remote daemon.
+func copyDelve(ctx context.Context, c client.CommonAPIClient, id, dlv string) error {
+	bb, err := os.ReadFile(dlv)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err = tw.WriteHeader(&tar.Header{Name: strings.TrimPrefix(delvePath, "/"), Mode: 0755, Size: int64(len(bb))}); err != nil {
+		return err
+	}
+	if _, err = tw.Write(bb); err != nil {
+		return err
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	if err = c.CopyToContainer(ctx, id, "/", &buf, container.CopyToContainerOptions{}); err != nil {
+		return errors.Wrap(err, "runner unable to copy dlv into the container")
+	}
+	return nil
+}
+
+func newHostConfig(f fn.Function, port, debugPort string, agent *debugAgent) (c container.HostConfig, err error) {
 	// httpPort := nat.Port(fmt.Sprintf("%v/tcp", port))
 	httpPort := nat.Port("8080/tcp")
 	ports := map[nat.Port][]nat.PortBinding{
@@ -236,7 +551,108 @@ func newHostConfig(port string) (c container.HostConfig, err error) {
 			},
 		},
 	}
-	return container.HostConfig{PortBindings: ports}, nil
+	var capabilities []string
+	if agent != nil {
+		ports[agent.port] = []nat.PortBinding{{HostPort: debugPort, HostIP: "127.0.0.1"}}
+		if agent.delve != "" {
+			capabilities = append(capabilities, "SYS_PTRACE") // Delve traces the function
+		}
+	}
+	mounts, err := newMounts(f)
+	if err != nil {
+		return
+	}
+	resources, err := newResources(f)
+	if err != nil {
+		return
+	}
+	return container.HostConfig{PortBindings: ports, Mounts: mounts, Resources: resources, CapAdd: capabilities}, nil
+}
+
+// newResources returns the container resources which enforce the function's
+// CPU and memory limits locally as they are in the cluster.  As in the
+// cluster, a container exceeding its memory limit is killed rather than
+// swapped.
+func newResources(f fn.Function) (r container.Resources, err error) {
+	o := f.Deploy.Options.Resources
+	if o == nil || o.Limits == nil {
+		return
+	}
+	if o.Limits.CPU != nil {
+		q, err := resource.ParseQuantity(*o.Limits.CPU)
+		if err != nil {
+			return r, fmt.Errorf("invalid CPU limit %q. %w", *o.Limits.CPU, err)
+		}
+		r.NanoCPUs = q.MilliValue() * 1e6
+	}
+	if o.Limits.Memory != nil {
+		q, err := resource.ParseQuantity(*o.Limits.Memory)
+		if err != nil {
+			return r, fmt.Errorf("invalid memory limit %q. %w", *o.Limits.Memory, err)
+		}
+		r.Memory = q.Value()
+		r.MemorySwap = r.Memory
+	}
+	return
+}
+
+// exitError describes the exit of a function's container, noting when it was
+// likely killed for exceeding its memory limit.
+func exitError(f fn.Function, code int64) error {
+	r := f.Deploy.Options.Resources
+	if code == 137 && r != nil && r.Limits != nil && r.Limits.Memory != nil {
+		return fmt.Errorf("exited code %v (killed, possibly for exceeding its memory limit of %v)", code, *r.Limits.Memory)
+	}
+	return fmt.Errorf("exited code %v", code)
+}
+
+// newMounts returns the mounts which emulate the function's volumes locally.
+// Secrets and ConfigMaps are bound read-only from the function's local
+// resources, persistent volume claims are bound to a directory of the local
+// resources (created if necessary), and empty dirs are tmpfs mounts.
+func newMounts(f fn.Function) (mounts []mount.Mount, err error) {
+	resources := fn.NewLocalResources(f)
+	for _, v := range f.Run.Volumes {
+		if v.Path == nil {
+			continue
+		}
+		m := mount.Mount{Type: mount.TypeBind, Target: *v.Path}
+		switch {
+		case v.Secret != nil:
+			m.Source, m.ReadOnly = resources.SecretPath(*v.Secret), true
+			if _, err = resources.Secret(*v.Secret); err != nil {
+				return
+			}
+		case v.ConfigMap != nil:
+			m.Source, m.ReadOnly = resources.ConfigMapPath(*v.ConfigMap), true
+			if _, err = resources.ConfigMap(*v.ConfigMap); err != nil {
+				return
+			}
+		case v.PersistentVolumeClaim != nil && v.PersistentVolumeClaim.ClaimName != nil:
+			m.Source, m.ReadOnly = resources.ClaimPath(*v.PersistentVolumeClaim.ClaimName), v.PersistentVolumeClaim.ReadOnly
+			if err = os.MkdirAll(m.Source, os.ModePerm); err != nil {
+				return
+			}
+		case v.EmptyDir != nil:
+			m = mount.Mount{Type: mount.TypeTmpfs, Target: *v.Path}
+			if v.EmptyDir.SizeLimit != nil {
+				var q resource.Quantity
+				if q, err = resource.ParseQuantity(*v.EmptyDir.SizeLimit); err != nil {
+					return nil, fmt.Errorf("invalid size limit of volume %v. %w", v, err)
+				}
+				m.TmpfsOptions = &mount.TmpfsOptions{SizeBytes: q.Value()}
+			}
+		default:
+			continue
+		}
+		if m.Type == mount.TypeBind {
+			if m.Source, err = filepath.Abs(m.Source); err != nil {
+				return
+			}
+		}
+		mounts = append(mounts, m)
+	}
+	return
 }
 
 // copy stdin and stdout from the container of the given ID.  Errors encountered
@@ -0,0 +1,218 @@
+//go:build !integration
+// +build !integration
+
+package docker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+	"knative.dev/pkg/ptr"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestNewMounts ensures that the function's volumes are emulated locally:
+// Secrets and ConfigMaps bound read-only from its local resources, persistent
+// volume claims bound to a directory of its local resources, and empty dirs
+// as tmpfs.
+func TestNewMounts(t *testing.T) {
+	f := fn.Function{Root: t.TempDir()}
+	f.Run.Volumes = []fn.Volume{
+		{Secret: ptr.String("s"), Path: ptr.String("/secret")},
+		{ConfigMap: ptr.String("c"), Path: ptr.String("/config")},
+		{PersistentVolumeClaim: &fn.PersistentVolumeClaim{ClaimName: ptr.String("p"), ReadOnly: true}, Path: ptr.String("/data")},
+		{EmptyDir: &fn.EmptyDir{SizeLimit: ptr.String("1Mi")}, Path: ptr.String("/tmp")},
+	}
+	r := fn.NewLocalResources(f)
+
+	// A referenced Secret which is not available locally is an error.
+	if _, err := newMounts(f); err == nil {
+		t.Fatal("expected an error for a missing Secret")
+	}
+
+	if err := r.WriteSecret("s", map[string][]byte{"k": []byte("v")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.WriteConfigMap("c", map[string]string{"k": "v"}); err != nil {
+		t.Fatal(err)
+	}
+	mounts, err := newMounts(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []mount.Mount{
+		{Type: mount.TypeBind, Source: r.SecretPath("s"), Target: "/secret", ReadOnly: true},
+		{Type: mount.TypeBind, Source: r.ConfigMapPath("c"), Target: "/config", ReadOnly: true},
+		{Type: mount.TypeBind, Source: r.ClaimPath("p"), Target: "/data", ReadOnly: true},
+		{Type: mount.TypeTmpfs, Target: "/tmp", TmpfsOptions: &mount.TmpfsOptions{SizeBytes: 1024 * 1024}},
+	}
+	if len(mounts) != len(expected) {
+		t.Fatalf("expected %v mounts, got %v", len(expected), len(mounts))
+	}
+	for i, m := range mounts {
+		e := expected[i]
+		if m.Type != e.Type || filepath.Clean(m.Source) != filepath.Clean(e.Source) || m.Target != e.Target || m.ReadOnly != e.ReadOnly {
+			t.Errorf("expected mount %+v, got %+v", e, m)
+		}
+		if e.TmpfsOptions != nil && (m.TmpfsOptions == nil || m.TmpfsOptions.SizeBytes != e.TmpfsOptions.SizeBytes) {
+			t.Errorf("expected tmpfs options %+v, got %+v", e.TmpfsOptions, m.TmpfsOptions)
+		}
+	}
+
+	// The directory of the claim is created.
+	if _, err = os.Stat(r.ClaimPath("p")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestNewResources ensures that the function's CPU and memory limits are
+// translated into container resources, without swap.
+func TestNewResources(t *testing.T) {
+	f := fn.Function{}
+	f.Deploy.Options.Resources = &fn.ResourcesOptions{
+		Limits: &fn.ResourcesLimitsOptions{CPU: ptr.String("500m"), Memory: ptr.String("128Mi")},
+	}
+	r, err := newResources(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.NanoCPUs != 500000000 {
+		t.Errorf("expected 500000000 NanoCPUs, got %v", r.NanoCPUs)
+	}
+	if r.Memory != 128*1024*1024 || r.MemorySwap != r.Memory {
+		t.Errorf("expected memory and memory+swap of 128Mi, got %v and %v", r.Memory, r.MemorySwap)
+	}
+
+	f.Deploy.Options.Resources.Limits.CPU = ptr.String("lots")
+	if _, err = newResources(f); err == nil {
+		t.Fatal("expected an error for an invalid CPU limit")
+	}
+}
+
+// TestNewContainerConfig_Debug ensures that when debugging, the runtime's
+// debug agent is enabled within the container and its port published on the
+// host's loopback interface, and that runtimes whose debug agent can not be
+// enabled in a container are an error.
+func TestNewContainerConfig_Debug(t *testing.T) {
+	f := fn.Function{Root: t.TempDir(), Runtime: "node"}
+
+	agent, err := newDebugAgent(f.Runtime, []string{"npm", "start"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := newContainerConfig(f, agent, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !contains(c.Env, "NODE_OPTIONS=--inspect=0.0.0.0:9229") {
+		t.Errorf("expected the node inspector to be enabled, got envs %v", c.Env)
+	}
+	if _, ok := c.ExposedPorts["9229/tcp"]; !ok {
+		t.Errorf("expected the inspector port to be exposed, got %v", c.ExposedPorts)
+	}
+	if len(c.Entrypoint) != 0 {
+		t.Errorf("expected the image's command to be run, got %v", c.Entrypoint)
+	}
+
+	h, err := newHostConfig(f, "8080", "9230", agent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bb := h.PortBindings["9229/tcp"]
+	if len(bb) != 1 || bb[0].HostPort != "9230" || bb[0].HostIP != "127.0.0.1" {
+		t.Errorf("expected the inspector bound to 127.0.0.1:9230, got %v", bb)
+	}
+
+	if _, err = newDebugAgent("rust", []string{"/func/f"}); !errors.As(err, &fn.ErrDebugNotSupported{}) {
+		t.Errorf("expected ErrDebugNotSupported, got %v", err)
+	}
+}
+
+// TestNewContainerConfig_DebugGo ensures that Go functions are run under the
+// Delve of the host, which may trace the function.
+func TestNewContainerConfig_DebugGo(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Delve is copied into containers from linux hosts only")
+	}
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "dlv"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	f := fn.Function{Root: t.TempDir(), Runtime: "go"}
+	agent, err := newDebugAgent(f.Runtime, []string{"/func/f", "--verbose"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if agent.delve != filepath.Join(bin, "dlv") {
+		t.Errorf("expected the host's dlv to be copied, got %q", agent.delve)
+	}
+	c, err := newContainerConfig(f, agent, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{delvePath, "exec", "--headless", "--listen=:2345", "--api-version=2", "--accept-multiclient", "--continue", "/func/f", "--", "--verbose"}
+	if !reflect.DeepEqual([]string(c.Entrypoint), expected) {
+		t.Errorf("expected the function to run under Delve, got %v", c.Entrypoint)
+	}
+	h, err := newHostConfig(f, "8080", "2346", agent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bb := h.PortBindings["2345/tcp"]; len(bb) != 1 || bb[0].HostPort != "2346" {
+		t.Errorf("expected Delve bound to port 2346, got %v", bb)
+	}
+	if !contains(h.CapAdd, "SYS_PTRACE") {
+		t.Errorf("expected the container to be able to trace, got capabilities %v", h.CapAdd)
+	}
+
+	if _, err = newDebugAgent(f.Runtime, nil); err == nil {
+		t.Error("expected an error debugging an image without a command")
+	}
+}
+
+// TestNewContainerConfig_DebugPython ensures that Python functions are run
+// using debugpy, and that images whose command does not run python are an
+// error.
+func TestNewContainerConfig_DebugPython(t *testing.T) {
+	f := fn.Function{Root: t.TempDir(), Runtime: "python"}
+	agent, err := newDebugAgent(f.Runtime, []string{"python", "/scaffolding/main.py"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := newContainerConfig(f, agent, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"/bin/sh", "-c", debugpyScript, "python", "/scaffolding/main.py"}
+	if !reflect.DeepEqual([]string(c.Entrypoint), expected) {
+		t.Errorf("expected the function to run using debugpy, got %v", c.Entrypoint)
+	}
+	if !strings.Contains(debugpyScript, "-m debugpy --listen 0.0.0.0:5678") {
+		t.Errorf("expected debugpy to listen on 5678, got script %v", debugpyScript)
+	}
+	if _, ok := c.ExposedPorts["5678/tcp"]; !ok {
+		t.Errorf("expected the debugpy port to be exposed, got %v", c.ExposedPorts)
+	}
+
+	if _, err = newDebugAgent(f.Runtime, []string{"/cnb/process/web"}); err == nil {
+		t.Error("expected an error debugging an image whose command does not run python")
+	}
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -54,7 +54,7 @@ func TestRun(t *testing.T) {
 	// Run the function using a docker runner
 	var out, errOut bytes.Buffer
 	runner := docker.NewRunner(true, &out, &errOut)
-	j, err := runner.Run(ctx, f, fn.DefaultStartTimeout)
+	j, err := runner.Run(ctx, f, fn.RunOptions{StartTimeout: fn.DefaultStartTimeout})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -36,7 +36,7 @@ func TestDockerRun(t *testing.T) {
 	// NOTE: test requires that the image be built already.
 
 	runner := docker.NewRunner(true, os.Stdout, os.Stdout)
-	if _, err = runner.Run(context.Background(), f, fn.DefaultStartTimeout); err != nil {
+	if _, err = runner.Run(context.Background(), f, fn.RunOptions{StartTimeout: fn.DefaultStartTimeout}); err != nil {
 		t.Fatal(err)
 	}
 	/* TODO
@@ -50,7 +50,7 @@ func TestDockerRunImagelessError(t *testing.T) {
 	runner := docker.NewRunner(true, os.Stdout, os.Stderr)
 	f := fn.NewFunctionWith(fn.Function{})
 
-	_, err := runner.Run(context.Background(), f, fn.DefaultStartTimeout)
+	_, err := runner.Run(context.Background(), f, fn.RunOptions{StartTimeout: fn.DefaultStartTimeout})
 	// TODO: switch to typed error:
 	expectedErrorMessage := "Function has no associated image. Has it been built?"
 	if err == nil || err.Error() != expectedErrorMessage {
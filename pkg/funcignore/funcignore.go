@@ -0,0 +1,189 @@
+// Package funcignore implements the .funcignore files of a function, which
+// exclude files from its fingerprint, its builds and the source uploaded
+// for remote builds, such that the same files are used everywhere.
+//
+// A .funcignore has the syntax and semantics of a .gitignore: patterns are
+// anchored to the directory of the file which contains them if they contain
+// a slash, a trailing slash matches only directories, "**" matches any
+// number of directories, and a leading "!" re-includes a path excluded by a
+// previous pattern.  A .funcignore in a subdirectory takes precedence over
+// those of its parents.  As with git, a path within an excluded directory
+// can not be re-included.  The function's .func and .git directories, and
+// the ignore files themselves, are ignored unless re-included.
+//
+// Functions created before .funcignore files were honored everywhere may
+// rely upon their .gitignore files instead, which is how the source of
+// remote builds was filtered.  Therefore if a function has no .funcignore
+// at its root, its .gitignore files are used in their place.
+package funcignore
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+
+	"knative.dev/func/pkg/filesystem"
+)
+
+// File is the name of the files which list the ignored paths of a function.
+const File = ".funcignore"
+
+// Fallback is the name of the files which list the ignored paths of a
+// function which has no .funcignore at its root.
+const Fallback = ".gitignore"
+
+// always are the patterns of paths which are ignored unless re-included by
+// a .funcignore: the repository and the ignore files themselves, and the
+// runtime data directory of the function (functions.RunDataDir, which can
+// not be imported here), which holds local state such as the credentials
+// with which it is run.
+var always = []string{".git", Fallback, File, "/.func"}
+
+// Matcher of the paths of a function which are ignored.
+type Matcher struct {
+	root     string
+	file     string // File, or Fallback if there is no File at root
+	patterns []gitignore.Pattern
+}
+
+// Load the .funcignore files of the function at root, including those of
+// its subdirectories which are not themselves ignored.
+func Load(root string) (*Matcher, error) {
+	m := newMatcher(root)
+	if err := m.walk(noop, nil); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Walk the files of the function at root which are not ignored, including
+// root itself, in the manner of filepath.WalkDir.  Ignored directories are
+// not descended into.
+func Walk(root string, fn fs.WalkDirFunc) error {
+	return newMatcher(root).walk(fn, nil)
+}
+
+// Ignored returns the paths of the function at root which are ignored,
+// relative to root and slash-separated, in lexical order.  Only the
+// top-most path of an ignored directory is returned, not its contents.
+func Ignored(root string) (paths []string, err error) {
+	err = newMatcher(root).walk(noop, func(rel string) {
+		paths = append(paths, rel)
+	})
+	return
+}
+
+// FS returns the filesystem of the function at root, in which the paths
+// which are ignored do not exist, such as for copying the function's source.
+func FS(root string) (filesystem.Filesystem, error) {
+	m, err := Load(root)
+	if err != nil {
+		return nil, err
+	}
+	return filesystem.NewMaskingFS(func(name string) bool {
+		fi, err := os.Lstat(filepath.Join(root, filepath.FromSlash(name)))
+		return m.Ignored(name, err == nil && fi.IsDir())
+	}, filesystem.NewOsFilesystem(root)), nil
+}
+
+// Ignored returns true if the path, relative to the function's root, is
+// ignored, either itself or by being within an ignored directory.
+func (m *Matcher) Ignored(path string, isDir bool) bool {
+	path = filepath.ToSlash(filepath.Clean(path))
+	if path == "." {
+		return false
+	}
+	elements := strings.Split(path, "/")
+	for i := 1; i < len(elements); i++ {
+		if m.match(elements[:i], true) {
+			return true
+		}
+	}
+	return m.match(elements, isDir)
+}
+
+func newMatcher(root string) *Matcher {
+	m := &Matcher{root: root, file: File}
+	if _, err := os.Stat(filepath.Join(root, File)); os.IsNotExist(err) {
+		m.file = Fallback
+	}
+	for _, p := range always {
+		m.patterns = append(m.patterns, gitignore.ParsePattern(p, nil))
+	}
+	return m
+}
+
+// match returns true if the path is excluded by the last pattern which
+// matches it.  Parent directories are not considered.
+func (m *Matcher) match(path []string, isDir bool) bool {
+	for i := len(m.patterns) - 1; i >= 0; i-- {
+		if r := m.patterns[i].Match(path, isDir); r != gitignore.NoMatch {
+			return r == gitignore.Exclude
+		}
+	}
+	return false
+}
+
+// walk the function's root, reading the .funcignore of each directory
+// before descending into it, and calling fn with the paths which are not
+// ignored, and ignored (if not nil) with those which are.
+func (m *Matcher) walk(fn fs.WalkDirFunc, ignored func(rel string)) error {
+	return filepath.WalkDir(m.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fn(path, d, err)
+		}
+		rel, err := filepath.Rel(m.root, path)
+		if err != nil {
+			return err
+		}
+		if rel != "." && m.match(strings.Split(filepath.ToSlash(rel), "/"), d.IsDir()) {
+			if ignored != nil {
+				ignored(filepath.ToSlash(rel))
+			}
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if err = m.read(rel); err != nil {
+				return err
+			}
+		}
+		return fn(path, d, nil)
+	})
+}
+
+// read the patterns of the .funcignore (or .gitignore), if any, of the
+// directory dir (relative to root).  Patterns are appended in order of increasing
+// precedence: those of a directory following those of its parents.
+func (m *Matcher) read(dir string) error {
+	file, err := os.Open(filepath.Join(m.root, dir, m.file))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var domain []string
+	if dir != "." {
+		domain = strings.Split(filepath.ToSlash(dir), "/")
+	}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		m.patterns = append(m.patterns, gitignore.ParsePattern(line, domain))
+	}
+	return scanner.Err()
+}
+
+// noop visits each path which is not ignored, failing only on errors.
+func noop(_ string, _ fs.DirEntry, err error) error { return err }
@@ -0,0 +1,188 @@
+//go:build !integration
+// +build !integration
+
+package funcignore_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"knative.dev/func/pkg/filesystem"
+	"knative.dev/func/pkg/funcignore"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestFuncignore ensures that the paths of a function are ignored with the
+// semantics of a .gitignore: negation, anchoring, directory-only patterns,
+// and nested files which take precedence over those of their parents.
+func TestFuncignore(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	files := map[string]string{
+		".funcignore":          "# comment\n*.log\n!keep.log\n/build\ndocs/\n/secret.txt\nvendor\n!vendor/keep.txt\n",
+		".func/local.yaml":     "",
+		".git/HEAD":            "",
+		"handle.go":            "",
+		"debug.log":            "",
+		"keep.log":             "",
+		"build/out":            "",
+		"src/build/out":        "",
+		"docs":                 "", // a file, not a directory
+		"src/docs/index.md":    "",
+		"secret.txt":           "",
+		"src/secret.txt":       "",
+		"vendor/keep.txt":      "",
+		"sub/.funcignore":      "!debug.log\n*.tmp\n",
+		"sub/debug.log":        "",
+		"sub/a.tmp":            "",
+		"other/a.tmp":          "",
+		"sub/.func/local.yaml": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The files walked are those which are not ignored
+	var walked []string
+	err := funcignore.Walk(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			rel, _ := filepath.Rel(root, path)
+			walked = append(walked, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"docs",
+		"handle.go",
+		"keep.log",
+		"other/a.tmp",
+		"src/build/out",
+		"src/secret.txt",
+		"sub/.func/local.yaml",
+		"sub/debug.log",
+	}
+	if !reflect.DeepEqual(walked, expected) {
+		t.Fatalf("expected files\n%v\ngot\n%v", expected, walked)
+	}
+
+	// A copy of the function's filesystem has the same files
+	fsys, err := funcignore.FS(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest := t.TempDir()
+	if err = filesystem.CopyFromFS(".", dest, fsys); err != nil {
+		t.Fatal(err)
+	}
+	var copied []string
+	err = filepath.WalkDir(dest, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(dest, path)
+			copied = append(copied, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(copied, expected) {
+		t.Fatalf("expected copied files\n%v\ngot\n%v", expected, copied)
+	}
+
+	// The ignored paths are the top-most of each which is ignored
+	ignored, err := funcignore.Ignored(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{
+		".func",
+		".funcignore",
+		".git",
+		"build",
+		"debug.log",
+		"secret.txt",
+		"src/docs",
+		"sub/.funcignore",
+		"sub/a.tmp",
+		"vendor",
+	}
+	if !reflect.DeepEqual(ignored, expected) {
+		t.Fatalf("expected ignored paths\n%v\ngot\n%v", expected, ignored)
+	}
+
+	// A path is ignored if within an ignored directory, and can not be
+	// re-included
+	m, err := funcignore.Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"handle.go", false, false},
+		{"new.log", false, true},
+		{"sub/new.log", false, true},
+		{"sub/dir/debug.log", false, false},
+		{"build/new", false, true},
+		{"vendor/keep.txt", false, true},
+		{"src/docs", true, true},
+		{"src/docs", false, false},
+		{".git/config", false, true},
+		{"src/.git", true, true},
+	}
+	for _, test := range tests {
+		if m.Ignored(filepath.FromSlash(test.path), test.isDir) != test.ignored {
+			t.Errorf("expected %v (dir: %v) ignored to be %v", test.path, test.isDir, test.ignored)
+		}
+	}
+}
+
+// TestFuncignore_Fallback ensures that the .gitignore files of a function
+// are used if it has no .funcignore, but not otherwise.
+func TestFuncignore_Fallback(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("*.out\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a.out"), []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ignored, err := funcignore.Ignored(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{".gitignore", "a.out"}; !reflect.DeepEqual(ignored, expected) {
+		t.Fatalf("expected ignored paths %v without a .funcignore, got %v", expected, ignored)
+	}
+
+	if err = os.WriteFile(filepath.Join(root, ".funcignore"), []byte("# nothing\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ignored, err = funcignore.Ignored(root); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{".funcignore", ".gitignore"}; !reflect.DeepEqual(ignored, expected) {
+		t.Fatalf("expected ignored paths %v with a .funcignore, got %v", expected, ignored)
+	}
+}
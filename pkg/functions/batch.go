@@ -0,0 +1,165 @@
+package functions
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultBatchConcurrency is the default number of functions of a batch which
+// are operated upon concurrently.
+const DefaultBatchConcurrency = 4
+
+// DiscoverFunctions returns the initialized functions within root (including
+// root itself), in lexical order of their paths.  The directories of
+// functions are not searched for further functions, nor are hidden
+// directories (such as .git) or node_modules.
+func DiscoverFunctions(root string) (ff []Function, err error) {
+	if root, err = filepath.Abs(root); err != nil {
+		return
+	}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, FunctionFile)); err != nil {
+			return nil
+		}
+		f, err := NewFunction(path)
+		if err != nil {
+			return err
+		}
+		if f.Initialized() {
+			ff = append(ff, f)
+		}
+		return filepath.SkipDir
+	})
+	return
+}
+
+// BatchResult is the outcome of an operation upon a function of a batch.
+type BatchResult struct {
+	// Function as returned by the operation.
+	Function Function
+	// Err of the operation, if any.
+	Err error
+}
+
+// Batch performs the operation upon each of the functions concurrently, with
+// at most limit in progress at once.  An operation upon a function does not
+// begin until those upon the functions on which it depends have completed,
+// and fails with ErrDependencyFailed if any of them failed.  Dependencies are
+// given as the roots of the functions on which each depends by the root of
+// each; those which are not in the batch are ignored.  The failure of an
+// operation does not affect those upon unrelated functions.  The results are
+// in the order of the functions.
+func Batch(ctx context.Context, ff []Function, dependencies map[string][]string, limit int, op func(context.Context, Function) (Function, error)) ([]BatchResult, error) {
+	if err := checkDependencies(dependencies); err != nil {
+		return nil, err
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	var (
+		results = make([]BatchResult, len(ff))
+		index   = make(map[string]int, len(ff))
+		done    = make([]chan struct{}, len(ff))
+		slots   = make(chan struct{}, limit)
+		wg      sync.WaitGroup
+	)
+	for i, f := range ff {
+		index[f.Root] = i
+		done[i] = make(chan struct{})
+	}
+	for i, f := range ff {
+		wg.Add(1)
+		go func(i int, f Function) {
+			defer wg.Done()
+			defer close(done[i])
+			for _, d := range dependencies[f.Root] {
+				j, ok := index[d]
+				if !ok {
+					continue
+				}
+				<-done[j]
+				if results[j].Err != nil {
+					results[i] = BatchResult{Function: f, Err: ErrDependencyFailed{Function: f.Name, Dependency: ff[j].Name}}
+					return
+				}
+			}
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				results[i] = BatchResult{Function: f, Err: ctx.Err()}
+				return
+			}
+			g, err := op(ctx, f)
+			results[i] = BatchResult{Function: g, Err: err}
+		}(i, f)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// ReverseDependencies returns the dependencies with each reversed, such as for
+// operating upon the dependents of a function before the function itself.
+func ReverseDependencies(dependencies map[string][]string) map[string][]string {
+	reversed := make(map[string][]string, len(dependencies))
+	for f, dd := range dependencies {
+		for _, d := range dd {
+			reversed[d] = append(reversed[d], f)
+		}
+	}
+	return reversed
+}
+
+// checkDependencies returns an error if the dependencies are circular.
+func checkDependencies(dependencies map[string][]string) error {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var visit func(root string, path []string) error
+	visit = func(root string, path []string) error {
+		switch state[root] {
+		case visiting:
+			return fmt.Errorf("circular dependency: %v", strings.Join(append(path, root), " -> "))
+		case visited:
+			return nil
+		}
+		state[root] = visiting
+		for _, d := range dependencies[root] {
+			if err := visit(d, append(path, root)); err != nil {
+				return err
+			}
+		}
+		state[root] = visited
+		return nil
+	}
+	for _, root := range sortedDependents(dependencies) {
+		if err := visit(root, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedDependents(dependencies map[string][]string) []string {
+	roots := make(map[string]bool, len(dependencies))
+	for root := range dependencies {
+		roots[root] = true
+	}
+	return sortedKeys(roots)
+}
@@ -0,0 +1,99 @@
+//go:build !integration
+// +build !integration
+
+package functions
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestDiscoverFunctions ensures that the initialized functions within a
+// directory are found, excluding hidden directories and node_modules.
+func TestDiscoverFunctions(t *testing.T) {
+	root := t.TempDir()
+	for _, path := range []string{"a", "services/b", ".hidden/c", "a/node_modules/d"} {
+		if _, err := New().Init(Function{Root: filepath.Join(root, path), Runtime: "go"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ff, err := DiscoverFunctions(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ff) != 2 || ff[0].Root != filepath.Join(root, "a") || ff[1].Root != filepath.Join(root, "services", "b") {
+		t.Fatalf("unexpected functions %v", ff)
+	}
+}
+
+// TestBatch ensures that operations upon functions await those upon their
+// dependencies, that the dependents of a failure are skipped while unrelated
+// functions are unaffected, and that concurrency is limited.
+func TestBatch(t *testing.T) {
+	ff := []Function{
+		{Root: "/a", Name: "a"},
+		{Root: "/b", Name: "b"},
+		{Root: "/c", Name: "c"},
+		{Root: "/d", Name: "d"},
+		{Root: "/e", Name: "e"},
+	}
+	dependencies := map[string][]string{
+		"/a": {"/b"},
+		"/d": {"/c"},
+		"/e": {"/d"},
+	}
+	var (
+		mu                    sync.Mutex
+		completed             = map[string]bool{}
+		inflight, maxInflight int32
+	)
+	results, err := Batch(context.Background(), ff, dependencies, 2, func(_ context.Context, f Function) (Function, error) {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		mu.Lock()
+		defer mu.Unlock()
+		if n > maxInflight {
+			maxInflight = n
+		}
+		for _, d := range dependencies[f.Root] {
+			if !completed[d] {
+				t.Errorf("%v started before its dependency %v completed", f.Root, d)
+			}
+		}
+		completed[f.Root] = true
+		if f.Name == "c" {
+			return f, errors.New("failed")
+		}
+		return f, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []string{"", "", "failed", "skipped d because its dependency c failed", "skipped e because its dependency d failed"} {
+		var actual string
+		if results[i].Err != nil {
+			actual = results[i].Err.Error()
+		}
+		if actual != expected {
+			t.Errorf("expected result of %v to be %q, got %q", ff[i].Name, expected, actual)
+		}
+	}
+	if maxInflight > 2 {
+		t.Errorf("expected at most 2 concurrent operations, got %v", maxInflight)
+	}
+
+	// Circular dependencies are an error.
+	dependencies["/b"] = []string{"/a"}
+	if _, err = Batch(context.Background(), ff, dependencies, 2, nil); err == nil {
+		t.Fatal("expected an error for circular dependencies")
+	}
+}
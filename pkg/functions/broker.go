@@ -0,0 +1,170 @@
+package functions
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// DefaultBroker is the name of the broker to which subscriptions are made by
+// default, as in Knative.
+const DefaultBroker = "default"
+
+// Broker is a local CloudEvents broker which delivers the events it receives
+// to a running function per the function's subscriptions (see
+// Deploy.Subscriptions), as the Knative Triggers created for them would on
+// deploy:  an event is delivered once for each subscription to the broker
+// whose filters it matches.  Events with which the function replies are sent
+// to the broker's sink, if any.  The decision made for each event, and the
+// outcome of its delivery, are written to the broker's output.
+type Broker struct {
+	name          string
+	subscriptions []KnativeSubscription
+	target        string // URL of the running function
+	sink          string // URL to which replies are sent
+	out           io.Writer
+}
+
+// NewBroker returns a broker of the given name which delivers events to the
+// function at target per the function's subscriptions to it.  Replies are
+// sent to sink, or if empty only written to out.
+func NewBroker(f Function, name, target, sink string, out io.Writer) *Broker {
+	b := &Broker{name: name, target: target, sink: sink, out: out}
+	for _, s := range f.Deploy.Subscriptions {
+		if s.Source == name {
+			b.subscriptions = append(b.subscriptions, s)
+		}
+	}
+	return b
+}
+
+// Subscriptions to the broker.
+func (b *Broker) Subscriptions() []KnativeSubscription {
+	return b.subscriptions
+}
+
+// Serve accepts events on the listener until the context is canceled.
+func (b *Broker) Serve(ctx context.Context, ln net.Listener) error {
+	p, err := cloudevents.NewHTTP(cloudevents.WithListener(ln))
+	if err != nil {
+		return err
+	}
+	c, err := cloudevents.NewClient(p)
+	if err != nil {
+		return err
+	}
+	return c.StartReceiver(ctx, b.receive)
+}
+
+// receive an event, delivering it to the function once per matching
+// subscription.  The report of each event is written at once, such that
+// those of events received concurrently are not interleaved.
+func (b *Broker) receive(ctx context.Context, event cloudevents.Event) protocol.Result {
+	out := &strings.Builder{}
+	defer func() { fmt.Fprint(b.out, out.String()) }()
+	fmt.Fprintf(out, "Broker %q received event %v (type %q, source %q)\n", b.name, event.ID(), event.Type(), event.Source())
+	matched := false
+	for i, s := range b.subscriptions {
+		if !s.Matches(event) {
+			continue
+		}
+		matched = true
+		fmt.Fprintf(out, "  matched subscription %v %v\n", i, formatFilters(s.Filters))
+		b.deliver(ctx, event, out)
+	}
+	if !matched {
+		fmt.Fprintf(out, "  matched no subscription.  Not delivered\n")
+	}
+	return cloudevents.ResultACK
+}
+
+// deliver the event to the function, sending its reply to the sink.
+func (b *Broker) deliver(ctx context.Context, event cloudevents.Event, out io.Writer) {
+	c, err := cloudevents.NewClientHTTP()
+	if err != nil {
+		fmt.Fprintf(out, "  unable to deliver. %v\n", err)
+		return
+	}
+	reply, result := c.Request(cloudevents.ContextWithTarget(ctx, b.target), event)
+	if !cloudevents.IsACK(result) {
+		fmt.Fprintf(out, "  delivery failed. %v\n", result)
+		return
+	}
+	if reply == nil {
+		fmt.Fprintf(out, "  delivered\n")
+		return
+	}
+	fmt.Fprintf(out, "  delivered.  Reply event %v (type %q, source %q)\n", reply.ID(), reply.Type(), reply.Source())
+	if b.sink == "" {
+		return
+	}
+	if result = c.Send(cloudevents.ContextWithTarget(ctx, b.sink), *reply); !cloudevents.IsACK(result) {
+		fmt.Fprintf(out, "  unable to send reply to %v. %v\n", b.sink, result)
+		return
+	}
+	fmt.Fprintf(out, "  reply sent to %v\n", b.sink)
+}
+
+// Matches returns true if the event matches the subscription's filters.  As
+// with the attribute filters of Knative Triggers, each filter must equal the
+// event's context attribute or extension of the same name, with an empty
+// filter matching any value.
+func (s KnativeSubscription) Matches(event cloudevents.Event) bool {
+	for name, value := range s.Filters {
+		if value == "" {
+			continue
+		}
+		if v, ok := eventAttribute(event, name); !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// eventAttribute returns the value of the named context attribute or
+// extension of the event, if it is set.
+func eventAttribute(e cloudevents.Event, name string) (string, bool) {
+	switch name {
+	case "specversion":
+		return e.SpecVersion(), true
+	case "id":
+		return e.ID(), true
+	case "type":
+		return e.Type(), true
+	case "source":
+		return e.Source(), true
+	case "subject":
+		return e.Subject(), e.Subject() != ""
+	case "datacontenttype":
+		return e.DataContentType(), e.DataContentType() != ""
+	case "dataschema":
+		return e.DataSchema(), e.DataSchema() != ""
+	case "time":
+		return types.FormatTime(e.Time()), !e.Time().IsZero()
+	}
+	v, ok := e.Extensions()[name]
+	if !ok {
+		return "", false
+	}
+	s, err := types.Format(v)
+	return s, err == nil
+}
+
+func formatFilters(filters map[string]string) string {
+	if len(filters) == 0 {
+		return "(no filters)"
+	}
+	ff := make([]string, 0, len(filters))
+	for k, v := range filters {
+		ff = append(ff, k+"="+v)
+	}
+	sort.Strings(ff)
+	return "(" + strings.Join(ff, ", ") + ")"
+}
@@ -0,0 +1,156 @@
+//go:build !integration
+// +build !integration
+
+package functions_test
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestKnativeSubscription_Matches ensures that subscription filters match
+// event attributes and extensions exactly, with empty filters matching any
+// value.
+func TestKnativeSubscription_Matches(t *testing.T) {
+	event := cloudevents.NewEvent()
+	event.SetID("1")
+	event.SetType("com.example.created")
+	event.SetSource("/example")
+	event.SetExtension("tenant", "acme")
+
+	tests := []struct {
+		filters map[string]string
+		matches bool
+	}{
+		{nil, true},
+		{map[string]string{"type": "com.example.created"}, true},
+		{map[string]string{"type": "com.example.deleted"}, false},
+		{map[string]string{"type": "com.example.created", "tenant": "acme"}, true},
+		{map[string]string{"tenant": "other"}, false},
+		{map[string]string{"subject": "x"}, false},
+		{map[string]string{"subject": ""}, true},
+	}
+	for _, test := range tests {
+		s := fn.KnativeSubscription{Source: fn.DefaultBroker, Filters: test.filters}
+		if s.Matches(event) != test.matches {
+			t.Errorf("expected filters %v to match: %v", test.filters, test.matches)
+		}
+	}
+}
+
+// TestBroker ensures that the broker delivers events to the function only
+// when they match a subscription to it, and sends the function's replies to
+// its sink.
+func TestBroker(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []string // types of events received by the function
+		replies  = make(chan cloudevents.Event, 1)
+	)
+
+	// The function, which replies to each event.
+	p, err := cloudevents.NewHTTP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	function, err := cloudevents.NewHTTPReceiveHandler(context.Background(), p,
+		func(e cloudevents.Event) *cloudevents.Event {
+			mu.Lock()
+			received = append(received, e.Type())
+			mu.Unlock()
+			r := cloudevents.NewEvent()
+			r.SetID("reply-" + e.ID())
+			r.SetType("com.example.reply")
+			r.SetSource("/function")
+			return &r
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	functionServer := httptest.NewServer(function)
+	defer functionServer.Close()
+
+	// The sink
+	if p, err = cloudevents.NewHTTP(); err != nil {
+		t.Fatal(err)
+	}
+	sink, err := cloudevents.NewHTTPReceiveHandler(context.Background(), p,
+		func(e cloudevents.Event) { replies <- e })
+	if err != nil {
+		t.Fatal(err)
+	}
+	sinkServer := httptest.NewServer(sink)
+	defer sinkServer.Close()
+
+	// The broker
+	f := fn.Function{}
+	f.Deploy.Subscriptions = []fn.KnativeSubscription{
+		{Source: fn.DefaultBroker, Filters: map[string]string{"type": "com.example.created"}},
+		{Source: "other", Filters: map[string]string{"type": "com.example.deleted"}},
+	}
+	out := &strings.Builder{}
+	outMu := &lockedWriter{w: out}
+	b := fn.NewBroker(f, fn.DefaultBroker, functionServer.URL, sinkServer.URL, outMu)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = b.Serve(ctx, ln) }()
+
+	// Send an event which matches, and one which matches only the
+	// subscription to another broker.
+	c, err := cloudevents.NewClientHTTP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := cloudevents.ContextWithTarget(ctx, "http://"+ln.Addr().String())
+	for i, typ := range []string{"com.example.created", "com.example.deleted"} {
+		e := cloudevents.NewEvent()
+		e.SetID(string(rune('a' + i)))
+		e.SetType(typ)
+		e.SetSource("/test")
+		if result := c.Send(target, e); !cloudevents.IsACK(result) {
+			t.Fatal(result)
+		}
+	}
+
+	reply := <-replies
+	if reply.ID() != "reply-a" {
+		t.Fatalf("expected the reply to the first event at the sink, got %v", reply.ID())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(received, ",") != "com.example.created" {
+		t.Fatalf("expected the function to receive only the matching event, got %v", received)
+	}
+	if !strings.Contains(outMu.String(), "matched no subscription") {
+		t.Fatalf("expected the unmatched event to be reported, got:\n%v", outMu.String())
+	}
+}
+
+type lockedWriter struct {
+	mu sync.Mutex
+	w  *strings.Builder
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+func (l *lockedWriter) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.String()
+}
@@ -2,13 +2,11 @@ package functions
 
 import (
 	"bufio"
-	"bytes"
 	"context"
-	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
-	"io/fs"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -18,6 +16,7 @@ import (
 
 	"gopkg.in/yaml.v2"
 
+	"knative.dev/func/pkg/funcignore"
 	"knative.dev/func/pkg/scaffolding"
 	"knative.dev/func/pkg/utils"
 )
@@ -71,6 +70,9 @@ type Client struct {
 	remover           Remover           // Removes remote services
 	lister            Lister            // Lists remote services
 	describer         Describer         // Describes function instances
+	differ            Differ            // Diffs functions with their instances
+	router            Router            // Routes traffic between revisions
+	progressListener  ProgressListener  // Receives the progress of tasks
 	dnsProvider       DNSProvider       // Provider of DNS services
 	registry          string            // default registry for OCI image tags
 	repositories      *Repositories     // Repositories management
@@ -79,6 +81,7 @@ type Client struct {
 	transport         http.RoundTripper // Customizable internal transport
 	pipelinesProvider PipelinesProvider // CI/CD pipelines management
 	startTimeout      time.Duration     // default start timeout for all runs
+	jobsPath          string            // path to the registry of running jobs
 }
 
 // Builder of function source to runnable image.
@@ -132,8 +135,11 @@ type Runner interface {
 	// Run the function, returning a Job with metadata, error channels, and
 	// a stop function.  The process can be stopped by running the returned stop
 	// function, either on context cancellation or in a defer.
-	// The duration is the time to wait for the job to start.
-	Run(context.Context, Function, time.Duration) (*Job, error)
+	// The options' port is that preferred on the host, with an available port
+	// chosen if empty or unavailable.  The options' start timeout is the
+	// effective time to wait for the job to start.  Detached jobs continue to
+	// run after the calling process exits.
+	Run(context.Context, Function, RunOptions) (*Job, error)
 }
 
 // Remover of deployed services.
@@ -162,6 +168,34 @@ type Describer interface {
 	Describe(ctx context.Context, name, namespace string) (Instance, error)
 }
 
+// Differ of functions and their deployed instances.
+type Differ interface {
+	// Diff the function with its deployed instance, returning the
+	// differences between them.
+	Diff(ctx context.Context, f Function) ([]Difference, error)
+}
+
+// Difference between a function and its deployed instance.
+type Difference struct {
+	// Field which differs, such as "image" or "env LOG_LEVEL".
+	Field string `json:"field" yaml:"field"`
+	// Desired value, as defined by the function, or empty if not defined.
+	Desired string `json:"desired" yaml:"desired"`
+	// Live value of the deployed instance, or empty if not defined.
+	Live string `json:"live" yaml:"live"`
+}
+
+// Router of the traffic of deployed functions between their revisions.
+type Router interface {
+	// Route the given percent of the traffic of the deployed function to its
+	// latest revision (the canary of its rollout), and the remainder to the
+	// revision which received it before the rollout (the stable revision).
+	// Routing all traffic to the canary promotes it, ending the rollout, and
+	// routing none aborts the rollout.
+	// Returns ErrRolloutNotInProgress if the function has no canary.
+	Route(ctx context.Context, f Function, percent int64) error
+}
+
 // Instance data about the runtime state of a function in a given environment.
 //
 // A function instance is a logical running function space, which share
@@ -214,6 +248,8 @@ func New(options ...Option) *Client {
 		remover:           &noopRemover{output: os.Stdout},
 		lister:            &noopLister{output: os.Stdout},
 		describer:         &noopDescriber{output: os.Stdout},
+		differ:            &noopDiffer{},
+		router:            &noopRouter{},
 		dnsProvider:       &noopDNSProvider{output: os.Stdout},
 		pipelinesProvider: &noopPipelinesProvider{},
 		transport:         http.DefaultTransport,
@@ -310,6 +346,30 @@ func WithDescriber(describer Describer) Option {
 	}
 }
 
+// WithDiffer provides a concrete implementation of a function differ.
+func WithDiffer(differ Differ) Option {
+	return func(c *Client) {
+		c.differ = differ
+	}
+}
+
+// WithRouter provides a concrete implementation of a router of the traffic
+// of deployed functions.
+func WithRouter(router Router) Option {
+	return func(c *Client) {
+		c.router = router
+	}
+}
+
+// WithProgressListener registers a listener of the progress of the client's
+// builds, pushes, deploys and runs.  By default progress is only reported as
+// messages to stderr.
+func WithProgressListener(l ProgressListener) Option {
+	return func(c *Client) {
+		c.progressListener = l
+	}
+}
+
 // WithDNSProvider proivdes a DNS provider implementation for registering the
 // effective DNS name which is either explicitly set via WithName or is derived
 // from the root path.
@@ -374,6 +434,16 @@ func WithStartTimeout(t time.Duration) Option {
 	}
 }
 
+// WithJobsPath sets the location on disk of the registry of jobs running on
+// this host.  Jobs started by the client are recorded in the registry such
+// that they can be listed and managed across functions and processes.  If not
+// provided, jobs are not recorded.
+func WithJobsPath(path string) Option {
+	return func(c *Client) {
+		c.jobsPath = path
+	}
+}
+
 // ACCESSORS
 // ---------
 
@@ -627,15 +697,17 @@ func BuildWithPlatforms(pp []Platform) BuildOption {
 
 // Build the function at path. Errors if the function is either unloadable or does
 // not contain a populated Image.
-func (c *Client) Build(ctx context.Context, f Function, options ...BuildOption) (Function, error) {
+func (c *Client) Build(ctx context.Context, f Function, options ...BuildOption) (_ Function, err error) {
 	fmt.Fprintf(os.Stderr, "Building function image\n")
-	ctx, cancel := context.WithCancel(ctx)
+	ctx, cancel := context.WithCancel(contextWithProgressListener(ctx, c.progressListener))
 	defer cancel()
+	defer c.reportError(ctx, f, &err)
 
 	// If not logging verbosely, the ongoing progress of the build will not
 	// be streaming to stdout, and the lack of activity has been seen to cause
-	// users to prematurely exit due to the sluggishness of pulling large images
-	if !c.verbose {
+	// users to prematurely exit due to the sluggishness of pulling large images.
+	// A progress listener is instead informed of the progress of the build.
+	if !c.verbose && c.progressListener == nil {
 		c.printBuildActivity(ctx) // print friendly messages until context is canceled
 	}
 
@@ -652,7 +724,6 @@ func (c *Client) Build(ctx context.Context, f Function, options ...BuildOption)
 
 	// If no image name has been specified by user (--image), calculate.
 	// Image name is stored on the function for later use by deploy, etc.
-	var err error
 	if f.Image == "" {
 		if f.Build.Image, err = f.ImageName(); err != nil {
 			return f, err
@@ -661,6 +732,7 @@ func (c *Client) Build(ctx context.Context, f Function, options ...BuildOption)
 		f.Build.Image = f.Image
 	}
 
+	ReportProgress(ctx, ProgressEvent{Type: ProgressBuildStarted, Function: f.Name, Image: f.Build.Image})
 	if err = c.builder.Build(ctx, f, oo.Platforms); err != nil {
 		return f, err
 	}
@@ -681,6 +753,7 @@ func (c *Client) Build(ctx context.Context, f Function, options ...BuildOption)
 		message = fmt.Sprintf("Function built: %v", f.Build.Image)
 	}
 	fmt.Fprintf(os.Stderr, "%s\n", message)
+	ReportProgress(ctx, ProgressEvent{Type: ProgressBuildCompleted, Function: f.Name, Image: f.Build.Image})
 
 	return f, err
 }
@@ -726,6 +799,8 @@ func (c *Client) printBuildActivity(ctx context.Context) {
 
 type DeployOptions struct {
 	skipBuiltCheck bool
+	rollbackTo     int
+	canary         *int64
 }
 type DeployOption func(f *DeployOptions)
 
@@ -735,10 +810,34 @@ func WithDeploySkipBuildCheck(skipBuiltCheck bool) DeployOption {
 	}
 }
 
+// WithDeployCanary overrides the percent of traffic routed to the new
+// revision of the function by its rollout (see RolloutSpec), without
+// altering the function.  Zero routes all traffic to the new revision.
+func WithDeployCanary(percent int64) DeployOption {
+	return func(f *DeployOptions) {
+		f.canary = &percent
+	}
+}
+
+// withDeployRollbackTo records the deployment as a rollback to the
+// deployment with the given number.
+func withDeployRollbackTo(n int) DeployOption {
+	return func(f *DeployOptions) {
+		f.rollbackTo = n
+	}
+}
+
 // Deploy the function at path.
 // Errors if the function has not been built unless explicitly instructed
 // to ignore this build check.
-func (c *Client) Deploy(ctx context.Context, f Function, oo ...DeployOption) (Function, error) {
+// The deployment is recorded in the deployment history of the function
+// (see History), which is mirrored as an annotation of the deployed instance.
+// An update of a function whose rollout is a canary routes only a percent of
+// traffic to the new revision, which is stepped up if the rollout has a step,
+// in which case Deploy returns once the canary is promoted.
+func (c *Client) Deploy(ctx context.Context, f Function, oo ...DeployOption) (_ Function, err error) {
+	ctx = contextWithProgressListener(ctx, c.progressListener)
+	defer c.reportError(ctx, f, &err)
 
 	options := &DeployOptions{}
 	for _, o := range oo {
@@ -795,22 +894,160 @@ func (c *Client) Deploy(ctx context.Context, f Function, oo ...DeployOption) (Fu
 	if c.verbose {
 		fmt.Fprintf(os.Stderr, "⬆️  Deploying \n")
 	}
-	result, err := c.deployer.Deploy(ctx, f)
+	history, deployed, err := c.recordDeployment(f, options)
+	if err != nil {
+		return f, err
+	}
+	if options.canary != nil {
+		deployed.Deploy.Rollout = deployed.Deploy.Rollout.WithPercent(*options.canary)
+	}
+	ReportProgress(ctx, ProgressEvent{Type: ProgressDeployStarted, Function: f.Name, Image: f.Deploy.Image, Namespace: f.Namespace})
+	result, err := c.deployer.Deploy(ctx, deployed)
 	if err != nil {
 		return f, fmt.Errorf("deploy error. %w", err)
 	}
 	// Update the function to reflect the new deployed state of the Function
 	f.Deploy.Namespace = result.Namespace
+	if f.Root != "" {
+		if err = f.WriteHistory(history); err != nil {
+			return f, fmt.Errorf("unable to write the deployment history. %w", err)
+		}
+	}
 
 	if result.Status == Deployed {
 		fmt.Fprintf(os.Stderr, "✅ Function deployed in namespace %q and exposed at URL: \n   %v\n", result.Namespace, result.URL)
 	} else if result.Status == Updated {
 		fmt.Fprintf(os.Stderr, "✅ Function updated in namespace %q and exposed at URL: \n   %v\n", result.Namespace, result.URL)
 	}
+	ReportProgress(ctx, ProgressEvent{Type: ProgressDeployCompleted, Function: f.Name, Namespace: result.Namespace, URL: result.URL})
 
+	// A new function has no stable revision, so is never a canary.
+	if rollout := deployed.Deploy.Rollout; result.Status == Updated && rollout.Canary() {
+		fmt.Fprintf(os.Stderr, "🐤 New revision receiving %v%% of traffic as a canary\n", rollout.Percent)
+		if rollout.Step > 0 {
+			if err = c.stepRollout(ctx, f, *rollout); err != nil {
+				return f, err
+			}
+		}
+	}
 	return f, nil
 }
 
+// stepRollout increases the percent of traffic routed to the canary of the
+// function by the rollout's step each interval, until promoted.  If the
+// context is canceled the rollout remains at its current percent.
+func (c *Client) stepRollout(ctx context.Context, f Function, rollout RolloutSpec) error {
+	interval, err := time.ParseDuration(rollout.Interval)
+	if err != nil {
+		return fmt.Errorf("invalid rollout interval %q. %w", rollout.Interval, err)
+	}
+	for percent := rollout.Percent; percent < 100; {
+		select {
+		case <-ctx.Done():
+			fmt.Fprintf(os.Stderr, "Rollout interrupted with %v%% of traffic routed to the canary\n", percent)
+			return nil
+		case <-time.After(interval):
+		}
+		if percent += rollout.Step; percent > 100 {
+			percent = 100
+		}
+		if err = c.router.Route(ctx, f, percent); err != nil {
+			return fmt.Errorf("unable to route %v%% of traffic to the canary. %w", percent, err)
+		}
+		fmt.Fprintf(os.Stderr, "🐤 Canary receiving %v%% of traffic\n", percent)
+	}
+	return nil
+}
+
+// Promote the canary of the deployed function's rollout, routing all traffic
+// to it.
+func (c *Client) Promote(ctx context.Context, f Function) error {
+	if f.Deploy.Namespace == "" {
+		return fmt.Errorf("function %q has not been deployed", f.Name)
+	}
+	return c.router.Route(ctx, f, 100)
+}
+
+// AbortRollout of the deployed function, routing all traffic to its stable
+// revision.  The canary remains the latest revision until the function is
+// next deployed.
+func (c *Client) AbortRollout(ctx context.Context, f Function) error {
+	if f.Deploy.Namespace == "" {
+		return fmt.Errorf("function %q has not been deployed", f.Name)
+	}
+	return c.router.Route(ctx, f, 0)
+}
+
+// recordDeployment returns the deployment history of the function with the
+// deployment of the function recorded, and the function to deploy, whose
+// annotations include the history.  Functions without a root are deployed
+// without a history.
+func (c *Client) recordDeployment(f Function, options *DeployOptions) (History, Function, error) {
+	if f.Root == "" {
+		return nil, f, nil
+	}
+	history, err := f.History()
+	if err != nil {
+		return history, f, fmt.Errorf("unable to read the deployment history. %w", err)
+	}
+	d, err := newDeployment(f, time.Now())
+	if err != nil {
+		return history, f, err
+	}
+	d.RollbackTo = options.rollbackTo
+	history = history.Record(d)
+
+	annotation, err := history.Annotation()
+	if err != nil {
+		return history, f, err
+	}
+	annotations := make(map[string]string, len(f.Deploy.Annotations)+1)
+	for k, v := range f.Deploy.Annotations {
+		annotations[k] = v
+	}
+	annotations[HistoryAnnotation] = annotation
+	f.Deploy.Annotations = annotations
+	return history, f, nil
+}
+
+// Rollback the function to the deployment of its history with the given
+// number, or to the deployment preceding the latest if zero.  The function
+// is redeployed as it was then, with the image then deployed, without being
+// rebuilt, to the namespace in which it is currently deployed.  Returned is
+// the function as redeployed; the function on disk is not changed.
+func (c *Client) Rollback(ctx context.Context, f Function, n int) (Function, error) {
+	if !f.Initialized() {
+		return f, NewErrNotInitialized(f.Root)
+	}
+	if f.Deploy.Namespace == "" {
+		return f, fmt.Errorf("function %q has not been deployed", f.Name)
+	}
+	history, err := f.History()
+	if err != nil {
+		return f, err
+	}
+	var (
+		d  Deployment
+		ok bool
+	)
+	if n == 0 {
+		d, ok = history.Previous()
+	} else {
+		d, ok = history.Deployment(n)
+	}
+	if !ok {
+		return f, ErrDeploymentNotFound{Number: n}
+	}
+	r, err := d.Restore(f)
+	if err != nil {
+		return f, fmt.Errorf("unable to restore deployment %v. %w", d.Number, err)
+	}
+	r.Namespace = f.Deploy.Namespace
+	r.Deploy.Namespace = f.Deploy.Namespace
+	r.Deploy.Rollout = nil // rolled back immediately
+	return c.Deploy(ctx, r, WithDeploySkipBuildCheck(true), withDeployRollbackTo(d.Number))
+}
+
 // RunPipeline runs a Pipeline to build and deploy the function.
 // Returned function contains applicable registry and deployed image name.
 // String is the default route.
@@ -904,6 +1141,13 @@ func (c *Client) Route(ctx context.Context, f Function) (string, Function, error
 
 type RunOptions struct {
 	StartTimeout time.Duration
+	Port         string
+	Detach       bool
+	Debug        bool
+	DebugPort    string
+	Stdout       io.Writer
+	Stderr       io.Writer
+	Network      string
 }
 
 type RunOption func(c *RunOptions)
@@ -918,9 +1162,59 @@ func RunWithStartTimeout(t time.Duration) RunOption {
 	}
 }
 
+// RunWithPort sets the preferred host port for this run request, such as
+// the port of a prior run which is being restarted.  If the port is not
+// available, an available port is chosen.
+func RunWithPort(port string) RunOption {
+	return func(c *RunOptions) {
+		c.Port = port
+	}
+}
+
+// RunDetached runs the function in the background, such that it continues to
+// run after this process exits.  Detached jobs are stopped by stopping the
+// returned job, or later via the client's job registry (see Jobs).
+func RunDetached() RunOption {
+	return func(c *RunOptions) {
+		c.Detach = true
+	}
+}
+
+// RunWithDebug runs the function such that a debugger may attach to it on
+// the given host port, or if empty the runtime's default (see
+// DefaultDebugPort).
+func RunWithDebug(port string) RunOption {
+	return func(c *RunOptions) {
+		c.Debug = true
+		c.DebugPort = port
+	}
+}
+
+// RunWithOutput writes the output of the function to the given writers
+// rather than to the runner's (by default this process' stdout and stderr).
+// The output of detached jobs is written to their log regardless.
+func RunWithOutput(stdout, stderr io.Writer) RunOption {
+	return func(c *RunOptions) {
+		c.Stdout = stdout
+		c.Stderr = stderr
+	}
+}
+
+// RunWithNetwork attaches the function's container to the named network of
+// the container runtime, which is created if necessary.  Other containers on
+// the network can reach the function at its name on port 8080.  Runners
+// which do not run the function in a container ignore the network.
+func RunWithNetwork(name string) RunOption {
+	return func(c *RunOptions) {
+		c.Network = name
+	}
+}
+
 // Run the function whose code resides at root.
 // On start, the chosen port is sent to the provided started channel
 func (c *Client) Run(ctx context.Context, f Function, options ...RunOption) (job *Job, err error) {
+	ctx = contextWithProgressListener(ctx, c.progressListener)
+	defer c.reportError(ctx, f, &err)
 
 	oo := RunOptions{}
 	for _, o := range options {
@@ -939,13 +1233,24 @@ func (c *Client) Run(ctx context.Context, f Function, options ...RunOption) (job
 	if oo.StartTimeout != 0 { // Highest precidence is an option passed to Run
 		timeout = oo.StartTimeout
 	}
+	oo.StartTimeout = timeout
 
 	// Run the function, which returns a Job for use interacting (at arms length)
 	// with that running task (which is likely inside a container process).
-	if job, err = c.runner.Run(ctx, f, timeout); err != nil {
+	ReportProgress(ctx, ProgressEvent{Type: ProgressRunStarted, Function: f.Name})
+	if job, err = c.runner.Run(ctx, f, oo); err != nil {
 		return
 	}
 
+	// Record the job such that it can be listed and managed by other
+	// processes.  The record is removed when the job is stopped.
+	if err = registerJob(c.jobsPath, job); err != nil {
+		_ = job.Stop()
+		return nil, err
+	}
+	ReportProgress(ctx, ProgressEvent{Type: ProgressRunReady, Function: f.Name,
+		URL: fmt.Sprintf("http://%v", net.JoinHostPort(job.Host, job.Port))})
+
 	// Return to the caller the effective port, a function to call to trigger
 	// stop, and a channel on which can be received runtime errors.
 	return job, nil
@@ -977,6 +1282,19 @@ func (c *Client) Describe(ctx context.Context, name, namespace string, f Functio
 	return c.describer.Describe(ctx, f.Name, f.Deploy.Namespace)
 }
 
+// Diff the function with its deployed instance, returning the differences
+// between the instance as it would be deployed and as it is, such as those
+// made to the instance directly rather than by deploying the function.
+func (c *Client) Diff(ctx context.Context, f Function) ([]Difference, error) {
+	if !f.Initialized() {
+		return nil, NewErrNotInitialized(f.Root)
+	}
+	if f.Deploy.Namespace == "" {
+		return nil, fmt.Errorf("function %q has not been deployed", f.Name)
+	}
+	return c.differ.Diff(ctx, f)
+}
+
 // List currently deployed functions.
 // If namespace is empty, the static implementation of the current
 // "Lister" is used, which for example with the knative lister defaults to
@@ -1074,12 +1392,14 @@ func (c *Client) Invoke(ctx context.Context, root string, target string, m Invok
 // Push the image for the named service to the configured registry
 // returns in this order: 1)Function structure 2)bool indicating if push succeeded
 // 3) error
-func (c *Client) Push(ctx context.Context, f Function) (Function, bool, error) {
+func (c *Client) Push(ctx context.Context, f Function) (_ Function, _ bool, err error) {
+	ctx = contextWithProgressListener(ctx, c.progressListener)
+	defer c.reportError(ctx, f, &err)
 	if !f.Built() {
 		return f, false, ErrNotBuilt
 	}
-	var err error
 
+	ReportProgress(ctx, ProgressEvent{Type: ProgressPushStarted, Function: f.Name, Image: f.Build.Image})
 	imageDigest, err := c.pusher.Push(ctx, f)
 	if err != nil {
 		return f, false, err
@@ -1091,10 +1411,19 @@ func (c *Client) Push(ctx context.Context, f Function) (Function, bool, error) {
 	// its populated here. This will eventually be moved to build stage where we get
 	// the full image name and its digest right after building
 	f.Build.Image = f.ImageNameWithDigest(imageDigest)
+	ReportProgress(ctx, ProgressEvent{Type: ProgressDigestResolved, Function: f.Name, Image: f.Build.Image, Digest: imageDigest})
 
 	return f, true, err
 }
 
+// reportError of a task of the client to its progress listener, if the task
+// failed.  Deferred by each task with its named error result.
+func (c *Client) reportError(ctx context.Context, f Function, err *error) {
+	if *err != nil {
+		ReportProgress(ctx, ProgressEvent{Type: ProgressError, Function: f.Name, Error: (*err).Error()})
+	}
+}
+
 // ensureRunDataDir creates a .func directory at the given path, and
 // registers it as ignored in a .gitignore file.
 func ensureRunDataDir(root string) error {
@@ -1156,7 +1485,7 @@ func ensureRunDataDir(root string) error {
 }
 
 func ensureFuncIgnore(root string) error {
-	filePath := filepath.Join(root, ".funcignore")
+	filePath := filepath.Join(root, funcignore.File)
 
 	// Check if the file exists
 	_, err := os.Stat(filePath)
@@ -1181,8 +1510,8 @@ func ensureFuncIgnore(root string) error {
 	_, err = file.WriteString(`
 # Use the .funcignore file to exclude files which should not be
 # tracked in the image build. To instruct the system not to track
-# files in the image build, add the regex pattern or file information
-# to this file.
+# files in the image build, add their paths or patterns to this file,
+# using the syntax of a .gitignore.
 `)
 	if err != nil {
 		return err
@@ -1190,37 +1519,6 @@ func ensureFuncIgnore(root string) error {
 	return nil
 }
 
-// Fingerprint the files at a given path.  Returns a hash calculated from the
-// filenames and modification timestamps of the files within the given root.
-// Also returns a logfile consiting of the filenames and modification times
-// which contributed to the hash.
-// Intended to determine if there were appreciable changes to a function's
-// source code, certain directories and files are ignored, such as
-// .git and .func.
-// Future updates will include files explicitly marked as ignored by a
-// .funcignore.
-func Fingerprint(root string) (hash, log string, err error) {
-	h := sha256.New()   // Hash builder
-	l := bytes.Buffer{} // Log buffer
-
-	err = filepath.Walk(root, func(path string, info fs.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if path == root {
-			return nil
-		}
-		// Always ignore .func, .git (TODO: .funcignore)
-		if info.IsDir() && (info.Name() == RunDataDir || info.Name() == ".git") {
-			return filepath.SkipDir
-		}
-		fmt.Fprintf(h, "%v:%v:", path, info.ModTime().UnixNano())   // Write to the Hasher
-		fmt.Fprintf(&l, "%v:%v\n", path, info.ModTime().UnixNano()) // Write to the Log
-		return nil
-	})
-	return fmt.Sprintf("%x", h.Sum(nil)), l.String(), err
-}
-
 // assertEmptyRoot ensures that the directory is empty enough to be used for
 // initializing a new function.
 func assertEmptyRoot(path string) (err error) {
@@ -1351,6 +1649,16 @@ func (n *noopDescriber) Describe(context.Context, string, string) (Instance, err
 	return Instance{}, nil
 }
 
+// Differ
+type noopDiffer struct{}
+
+func (n *noopDiffer) Diff(context.Context, Function) ([]Difference, error) { return nil, nil }
+
+// Router
+type noopRouter struct{}
+
+func (n *noopRouter) Route(context.Context, Function, int64) error { return nil }
+
 // PipelinesProvider
 type noopPipelinesProvider struct{}
 
@@ -12,16 +12,20 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"os/exec"
 	"path"
 	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
 	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"gopkg.in/yaml.v2"
+
 	"knative.dev/func/pkg/builders"
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/mock"
@@ -723,6 +727,132 @@ func TestClient_Runner(t *testing.T) {
 	cancel()
 }
 
+// TestClient_Runner_Detached ensures that a function run detached by the
+// default runner is recorded in the jobs registry with its process and log,
+// and can be stopped using the registry.
+func TestClient_Runner_Detached(t *testing.T) {
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+	jobs := t.TempDir()
+	ctx := context.Background()
+	client := fn.New(fn.WithJobsPath(jobs))
+
+	f, err := client.Init(fn.Function{Root: root, Runtime: "go", Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Run detached
+	job, err := client.Run(ctx, f, fn.RunDetached())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !job.Detached || job.PID == 0 {
+		t.Fatalf("expected a detached job with a PID, got detached=%v pid=%v", job.Detached, job.PID)
+	}
+
+	// Listed
+	jj, err := client.Jobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jj) != 1 || jj[0].PID != job.PID || jj[0].Port != job.Port || jj[0].Log != job.Log() {
+		t.Fatalf("unexpected jobs: %+v", jj)
+	}
+
+	// Invoke
+	resp, err := http.Get(fmt.Sprintf("http://%s:%s", job.Host, job.Port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// Stop via the registry, as would another process
+	if err = client.StopJob(ctx, jj[0]); err != nil {
+		t.Fatal(err)
+	}
+	if jj, err = client.Jobs(); err != nil || len(jj) != 0 {
+		t.Fatalf("expected no jobs after stop, got %+v (%v)", jj, err)
+	}
+	if _, err = os.Stat(job.Dir()); !os.IsNotExist(err) {
+		t.Fatalf("expected the job directory to be removed. %v", err)
+	}
+}
+
+// TestClient_Jobs ensures that jobs are recorded in the registry when run,
+// removed when stopped, and that records of jobs no longer running are
+// removed when listing.
+func TestClient_Jobs(t *testing.T) {
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+	jobs := t.TempDir()
+
+	// The jobs of the mock runner are hosted by this process, which is
+	// therefore recorded as theirs.  The port is informational only.
+	port := "8089"
+
+	client := fn.New(fn.WithRunner(mock.NewRunner()), fn.WithJobsPath(jobs))
+	f, err := client.Init(fn.Function{Root: root, Runtime: TestRuntime})
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := client.Run(context.Background(), f, fn.RunWithPort(port), fn.RunDetached())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jj, err := client.Jobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jj) != 1 || jj[0].Root != root || jj[0].Port != port || !jj[0].Detached {
+		t.Fatalf("unexpected jobs: %+v", jj)
+	}
+
+	// Stopping the job removes its record
+	if err = job.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if jj, _ = client.Jobs(); len(jj) != 0 {
+		t.Fatalf("expected no jobs after stop, got %+v", jj)
+	}
+
+	// Records of jobs whose process is no longer that which was recorded,
+	// such as one which exited and whose PID was reused, are removed.
+	if _, err = client.Run(context.Background(), f, fn.RunWithPort(port)); err != nil {
+		t.Fatal(err)
+	}
+	if jj, _ = client.Jobs(); len(jj) != 1 || jj[0].PID != os.Getpid() {
+		t.Fatalf("expected a job of this process, got %+v", jj)
+	}
+	ee, err := os.ReadDir(jobs)
+	if err != nil || len(ee) != 1 {
+		t.Fatalf("expected one job record, got %v (%v)", len(ee), err)
+	}
+	path := filepath.Join(jobs, ee[0].Name())
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info fn.JobInfo
+	if err = yaml.Unmarshal(bb, &info); err != nil {
+		t.Fatal(err)
+	}
+	info.PIDStart = "stale"
+	if bb, err = yaml.Marshal(info); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(path, bb, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if jj, _ = client.Jobs(); len(jj) != 0 {
+		t.Fatalf("expected stale job to be removed, got %+v", jj)
+	}
+	if ee, _ := os.ReadDir(jobs); len(ee) != 0 {
+		t.Fatalf("expected stale job record to be removed, found %v", len(ee))
+	}
+}
+
 // TestClient_Run_DataDir ensures that when a function is created, it also
 // includes a .func (runtime data) directory which is registered as ignored for
 // functions which will be tracked in git source control.
@@ -1740,7 +1870,7 @@ func TestClient_Invoke_HTTP(t *testing.T) {
 	// Create a client with a mock runner which will report the port at which the
 	// interloping function is listening.
 	runner := mock.NewRunner()
-	runner.RunFn = func(ctx context.Context, f fn.Function, _ time.Duration) (*fn.Job, error) {
+	runner.RunFn = func(ctx context.Context, f fn.Function, _ fn.RunOptions) (*fn.Job, error) {
 		_, p, _ := net.SplitHostPort(l.Addr().String())
 		errs := make(chan error, 10)
 		stop := func() error { return nil }
@@ -1842,7 +1972,7 @@ func TestClient_Invoke_CloudEvent(t *testing.T) {
 
 	// Create a client with a mock Runner which returns its address.
 	runner := mock.NewRunner()
-	runner.RunFn = func(ctx context.Context, f fn.Function, _ time.Duration) (*fn.Job, error) {
+	runner.RunFn = func(ctx context.Context, f fn.Function, _ fn.RunOptions) (*fn.Job, error) {
 		_, p, _ := net.SplitHostPort(l.Addr().String())
 		errs := make(chan error, 10)
 		stop := func() error { return nil }
@@ -1896,7 +2026,7 @@ func TestClient_Instances(t *testing.T) {
 
 	// A mock runner
 	runner := mock.NewRunner()
-	runner.RunFn = func(_ context.Context, f fn.Function, _ time.Duration) (*fn.Job, error) {
+	runner.RunFn = func(_ context.Context, f fn.Function, _ fn.RunOptions) (*fn.Job, error) {
 		errs := make(chan error, 10)
 		stop := func() error { return nil }
 		return fn.NewJob(f, "127.0.0.1", "8080", errs, stop, false)
@@ -2197,3 +2327,40 @@ func TestClient_BuildPopulatesRuntimeImage(t *testing.T) {
 		t.Fatalf("written image in ./.func/built-image '%s' does not match expected '%s'", got, expect)
 	}
 }
+
+// TestClient_StopJob_ReusedPID ensures that the process of a job is not
+// signaled when its PID no longer belongs to the job, such as when it was
+// reused by another process, and that the job's stale record is removed.
+func TestClient_StopJob_ReusedPID(t *testing.T) {
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+	jobs := t.TempDir()
+
+	// A process which is not the job's, but has the PID it recorded.
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+	other := exec.Command("sleep", "60")
+	if err := other.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = other.Process.Kill(); _ = other.Wait() }()
+
+	j := fn.JobInfo{Root: root, Host: "127.0.0.1", Port: "8080", PID: other.Process.Pid, PIDStart: "0"}
+	record := filepath.Join(jobs, j.Port+".yaml")
+	if err := os.WriteFile(record, []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := fn.New(fn.WithJobsPath(jobs))
+	err := client.StopJob(context.Background(), j)
+	if !errors.As(err, &fn.ErrJobNotRunning{}) {
+		t.Fatalf("expected ErrJobNotRunning, got %v", err)
+	}
+	if err = other.Process.Signal(syscall.Signal(0)); err != nil {
+		t.Fatalf("expected the other process to not be signaled. %v", err)
+	}
+	if _, err = os.Stat(record); !os.IsNotExist(err) {
+		t.Fatalf("expected the stale record to be removed. %v", err)
+	}
+}
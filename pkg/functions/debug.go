@@ -0,0 +1,44 @@
+package functions
+
+import (
+	"fmt"
+	"net"
+)
+
+// DefaultDebugPort returns the port on which the debug agent of the runtime
+// conventionally listens: Delve for Go, debugpy for Python, the Node.js
+// inspector for Node.js and TypeScript, and JDWP for the JVM runtimes.  An
+// empty string is returned for runtimes which can not be debugged.
+func DefaultDebugPort(runtime string) string {
+	switch runtime {
+	case "go":
+		return "2345"
+	case "python":
+		return "5678"
+	case "node", "typescript":
+		return "9229"
+	case "quarkus", "springboot":
+		return "5005"
+	default:
+		return ""
+	}
+}
+
+// debugPort returns the host port on which the debug agent of a function of
+// the given runtime is to listen: the requested port, or the runtime's
+// default.  Unlike the port of the function itself, an alternative is not
+// chosen when the port is in use, as debuggers are configured to attach to
+// a known port.
+func debugPort(runtime, host, port string) (string, error) {
+	if port == "" {
+		port = DefaultDebugPort(runtime)
+	}
+	if port == "" {
+		return "", ErrDebugNotSupported{Runtime: runtime}
+	}
+	l, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return "", fmt.Errorf("debug port %v is not available. %w", port, err)
+	}
+	return port, l.Close()
+}
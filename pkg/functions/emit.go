@@ -0,0 +1,129 @@
+package functions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultEmitRate is the default number of events emitted per second.
+const DefaultEmitRate = 1.0
+
+// EmitSpec describes a stream of synthetic CloudEvents with which to exercise
+// a function, as would be produced by an event source such as a PingSource.
+//
+// The data of each event is either rendered from the Data template, which
+// may refer to the event's {{.Sequence}} (starting at 1), {{.ID}} and
+// {{.Time}}, or is the content of each of the Files in turn.
+type EmitSpec struct {
+	Source      string
+	Type        string
+	ContentType string
+	Data        string
+	Files       []string
+	// Rate of events per second.
+	Rate float64
+	// Count of events to emit, or zero to emit until canceled.
+	Count int
+}
+
+// EmitResult is the outcome of emitting an event.
+type EmitResult struct {
+	// Sequence of the event in the stream, starting at 1.
+	Sequence int
+	// ID of the event.
+	ID string
+	// Response event of the function, if any.
+	Response string
+	// Err is the error delivering the event, if any.
+	Err error
+}
+
+// emitData are the values available to the data template of an EmitSpec.
+type emitData struct {
+	Sequence int
+	ID       string
+	Time     string
+}
+
+// Emit the stream of events described by the spec to the function at root,
+// running at the given target instance (see Invoke).  Each event is sent as
+// a CloudEvent as would be by Invoke, and the outcome of each is reported.
+// Emit returns once all events have been emitted or the context is canceled;
+// errors delivering individual events are reported rather than returned.
+func (c *Client) Emit(ctx context.Context, root, target string, spec EmitSpec, report func(EmitResult)) error {
+	f, err := NewFunction(root)
+	if err != nil {
+		return err
+	}
+	if !f.Initialized() {
+		return NewErrNotInitialized(f.Root)
+	}
+	if spec.Rate <= 0 {
+		return errors.New("the rate of events must be greater than zero")
+	}
+	if spec.Count < 0 {
+		return errors.New("the count of events may not be negative")
+	}
+
+	// Data of each event, either from the template or the fixtures.
+	var (
+		tmpl     *texttemplate.Template
+		fixtures [][]byte
+	)
+	if len(spec.Files) > 0 {
+		for _, file := range spec.Files {
+			bb, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			fixtures = append(fixtures, bb)
+		}
+	} else if tmpl, err = texttemplate.New("data").Parse(spec.Data); err != nil {
+		return fmt.Errorf("invalid data template. %w", err)
+	}
+
+	route, err := invocationRoute(ctx, c, f, target)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / spec.Rate))
+	defer ticker.Stop()
+	for i := 1; spec.Count == 0 || i <= spec.Count; i++ {
+		if i > 1 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+		m := InvokeMessage{
+			ID:          uuid.NewString(),
+			Source:      spec.Source,
+			Type:        spec.Type,
+			ContentType: spec.ContentType,
+		}
+		if fixtures != nil {
+			m.Data = string(fixtures[(i-1)%len(fixtures)])
+		} else {
+			buf := &bytes.Buffer{}
+			if err = tmpl.Execute(buf, emitData{Sequence: i, ID: m.ID, Time: time.Now().UTC().Format(time.RFC3339)}); err != nil {
+				return fmt.Errorf("unable to render data of event %v. %w", i, err)
+			}
+			m.Data = buf.String()
+		}
+		resp, err := sendEvent(ctx, route, m, c.transport, c.verbose)
+		if ctx.Err() != nil {
+			return nil
+		}
+		report(EmitResult{Sequence: i, ID: m.ID, Response: resp, Err: err})
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+//go:build !integration
+// +build !integration
+
+package functions_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestClient_Emit ensures that the events of a spec are emitted with data
+// rendered from its template or read from its fixtures, and that the outcome
+// of each, including the function's response, is reported.
+func TestClient_Emit(t *testing.T) {
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+
+	var (
+		mu       sync.Mutex
+		received []string // data of the events received by the function
+	)
+	p, err := cloudevents.NewHTTP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := cloudevents.NewHTTPReceiveHandler(context.Background(), p,
+		func(e cloudevents.Event) *cloudevents.Event {
+			mu.Lock()
+			received = append(received, string(e.Data()))
+			mu.Unlock()
+			r := cloudevents.NewEvent()
+			r.SetID("reply")
+			r.SetType("com.example.reply")
+			r.SetSource("/function")
+			return &r
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := httptest.NewServer(h)
+	defer s.Close()
+
+	client := fn.New()
+	if _, err = client.Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// From a template
+	var results []fn.EmitResult
+	spec := fn.EmitSpec{
+		Source:      "/test",
+		Type:        "com.example.tick",
+		ContentType: "application/json",
+		Data:        `{"n":{{.Sequence}}}`,
+		Rate:        100,
+		Count:       3,
+	}
+	if err = client.Emit(context.Background(), root, s.URL, spec, func(r fn.EmitResult) {
+		results = append(results, r)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %v", len(results))
+	}
+	for i, r := range results {
+		if r.Err != nil || r.Sequence != i+1 || r.ID == "" || r.Response == "" {
+			t.Fatalf("unexpected result %+v", r)
+		}
+	}
+
+	// From fixtures, in turn
+	fixtures := []string{filepath.Join(root, "a.json"), filepath.Join(root, "b.json")}
+	for i, file := range fixtures {
+		if err = os.WriteFile(file, []byte(`{"fixture":`+string(rune('1'+i))+`}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	spec.Files, spec.Count = fixtures, 3
+	if err = client.Emit(context.Background(), root, s.URL, spec, func(fn.EmitResult) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []string{`{"n":1}`, `{"n":2}`, `{"n":3}`, `{"fixture":1}`, `{"fixture":2}`, `{"fixture":1}`}
+	if len(received) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, received)
+	}
+	for i := range expected {
+		if received[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, received)
+		}
+	}
+}
@@ -23,6 +23,7 @@ var (
 	ErrTemplateNotFound          = errors.New("template not found")
 	ErrTemplatesNotFound         = errors.New("templates path (runtimes) not found")
 	ErrContextCanceled           = errors.New("the operation was canceled")
+	ErrRolloutNotInProgress      = errors.New("no rollout in progress")
 
 	// TODO: change the wording of this error to not be CLI-specific;
 	// eg "registry required".  Then catch the error in the CLI and add the
@@ -66,6 +67,31 @@ func (e ErrRunnerNotImplemented) Error() string {
 	return fmt.Sprintf("the %q runtime may only be run containerized.", e.Runtime)
 }
 
+// ErrDebugNotSupported is returned when running a function with debugging
+// enabled whose runtime can not be debugged by the runner.
+type ErrDebugNotSupported struct {
+	Runtime   string
+	Container bool
+}
+
+func (e ErrDebugNotSupported) Error() string {
+	if e.Container {
+		return fmt.Sprintf("debugging %v functions in a container is not supported", e.Runtime)
+	}
+	return fmt.Sprintf("debugging %v functions is not supported", e.Runtime)
+}
+
+// ErrJobNotRunning is returned when stopping a job whose process has exited,
+// possibly with its process ID since assigned to another process.
+type ErrJobNotRunning struct {
+	PID  int
+	Port string
+}
+
+func (e ErrJobNotRunning) Error() string {
+	return fmt.Sprintf("the job on port %v is no longer running as process %v.  Removed its record", e.Port, e.PID)
+}
+
 type ErrRunTimeout struct {
 	Timeout time.Duration
 }
@@ -73,3 +99,27 @@ type ErrRunTimeout struct {
 func (e ErrRunTimeout) Error() string {
 	return fmt.Sprintf("timed out waiting for function to be ready for %s", e.Timeout)
 }
+
+// ErrDependencyFailed is returned for a function of a batch when the operation
+// upon a function on which it depends failed (see Batch).
+type ErrDependencyFailed struct {
+	Function   string
+	Dependency string
+}
+
+func (e ErrDependencyFailed) Error() string {
+	return fmt.Sprintf("skipped %v because its dependency %v failed", e.Function, e.Dependency)
+}
+
+// ErrDeploymentNotFound is returned when rolling back to a deployment which
+// is not in the deployment history of the function (see History).
+type ErrDeploymentNotFound struct {
+	Number int
+}
+
+func (e ErrDeploymentNotFound) Error() string {
+	if e.Number == 0 {
+		return "there is no previous deployment in the history of the function"
+	}
+	return fmt.Sprintf("deployment %v is not in the history of the function", e.Number)
+}
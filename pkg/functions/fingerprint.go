@@ -0,0 +1,149 @@
+package functions
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"knative.dev/func/pkg/funcignore"
+)
+
+// FingerprintCache is the name of the file within the runtime data directory
+// (RunDataDir) which caches the content hashes of the files of a function,
+// keyed by their size and modification time, such that only files which
+// have been modified since are read when fingerprinting.
+const FingerprintCache = "fingerprint.yaml"
+
+// fingerprintEntry is the cached content hash of a file.
+type fingerprintEntry struct {
+	Size    int64  `yaml:"size"`
+	ModTime int64  `yaml:"modTime"`
+	Hash    string `yaml:"hash"`
+}
+
+// Fingerprint the files at a given path.  Returns a hash calculated from the
+// relative paths, executable bits and contents of the files within the given
+// root.  Also returns a logfile consisting of the paths, modes and content
+// hashes of the files which contributed to the hash.
+// Intended to determine if there were appreciable changes to a function's
+// source code, modification times do not contribute, such that touching a
+// file, or checking out a branch and back, does not change the fingerprint.
+// Files ignored by the function's .funcignore files (see funcignore),
+// including its .func and .git directories, do not contribute.
+// Content hashes are cached in .func (see FingerprintCache), if it exists.
+func Fingerprint(root string) (hash, log string, err error) {
+	var (
+		h       = sha256.New()   // Hash builder
+		l       = bytes.Buffer{} // Log buffer
+		cache   = readFingerprintCache(root)
+		updated = make(map[string]fingerprintEntry, len(cache))
+
+		// Files modified within a second of fingerprinting are not cached, as
+		// a further change within the resolution of the filesystem's
+		// timestamps would not change their size or modification time.
+		recent = time.Now().Add(-time.Second)
+	)
+
+	err = funcignore.Walk(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		var sum string
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			sum = fmt.Sprintf("%x", sha256.Sum256([]byte(target)))
+		case info.Mode().IsRegular():
+			e, ok := cache[rel]
+			if !ok || e.Size != info.Size() || e.ModTime != info.ModTime().UnixNano() {
+				if sum, err = hashFile(path); err != nil {
+					return err
+				}
+				e = fingerprintEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano(), Hash: sum}
+			}
+			sum = e.Hash
+			if info.ModTime().Before(recent) {
+				updated[rel] = e
+			}
+		default:
+			return nil // sockets, devices etc. have no content
+		}
+		mode := info.Mode() & (fs.ModeSymlink | 0111)
+		fmt.Fprintf(h, "%v:%v:%v:", rel, mode, sum)   // Write to the Hasher
+		fmt.Fprintf(&l, "%v:%v:%v\n", rel, mode, sum) // Write to the Log
+		return nil
+	})
+	if err != nil {
+		return
+	}
+	if !reflect.DeepEqual(cache, updated) {
+		writeFingerprintCache(root, updated)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), l.String(), nil
+}
+
+// hashFile returns the hex-encoded sha256 of the file's content.
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// readFingerprintCache of the function at root.  A missing or unreadable
+// cache is empty.
+func readFingerprintCache(root string) map[string]fingerprintEntry {
+	cache := map[string]fingerprintEntry{}
+	bb, err := os.ReadFile(filepath.Join(root, RunDataDir, FingerprintCache))
+	if err != nil {
+		return cache
+	}
+	if err = yaml.Unmarshal(bb, &cache); err != nil {
+		return map[string]fingerprintEntry{}
+	}
+	return cache
+}
+
+// writeFingerprintCache of the function at root, if its runtime data
+// directory exists.  The cache is an optimization, so failing to write it
+// is not an error.
+func writeFingerprintCache(root string, cache map[string]fingerprintEntry) {
+	if _, err := os.Stat(filepath.Join(root, RunDataDir)); err != nil {
+		return
+	}
+	bb, err := yaml.Marshal(cache)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(root, RunDataDir, FingerprintCache), bb, 0644)
+}
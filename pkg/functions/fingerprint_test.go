@@ -0,0 +1,68 @@
+//go:build !integration
+// +build !integration
+
+package functions_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestFingerprint_Cache ensures that the content hashes of files are cached
+// by their size and modification time, and that files whose modification
+// time changed are hashed again by their content.
+func TestFingerprint_Cache(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+	if err := os.Mkdir(filepath.Join(root, fn.RunDataDir), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(root, "handle.go")
+	write := func(content string, modified time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fingerprint := func() string {
+		t.Helper()
+		hash, _, err := fn.Fingerprint(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+	past := time.Now().Add(-time.Hour)
+
+	write("package a", past)
+	original := fingerprint()
+	if _, err := os.Stat(filepath.Join(root, fn.RunDataDir, fn.FingerprintCache)); err != nil {
+		t.Fatalf("expected the content hashes to be cached. %v", err)
+	}
+
+	// A file of the same size and modification time is not read again.
+	write("package b", past)
+	if fingerprint() != original {
+		t.Fatal("expected the cached content hash to be used")
+	}
+
+	// A file modified since is.
+	write("package b", past.Add(time.Minute))
+	if fingerprint() == original {
+		t.Fatal("expected the file to be hashed again when modified")
+	}
+
+	// The same content is the same fingerprint, whenever it was modified.
+	write("package a", past.Add(2*time.Minute))
+	if fingerprint() != original {
+		t.Fatal("expected the fingerprint of the same content to be unchanged")
+	}
+}
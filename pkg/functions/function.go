@@ -105,6 +105,15 @@ type Function struct {
 	// Deploy defines the deployment properties for a function
 	Deploy DeploySpec `yaml:"deploy,omitempty"`
 
+	// Environments are named deployment environments (for example
+	// "staging" or "prod") whose values override those of the function
+	// when targeted.  See .ForEnvironment.
+	Environments Environments `yaml:"environments,omitempty"`
+
+	// Environment is the name of the environment at which the function is
+	// currently targeted, if any.  Not persisted.
+	Environment string `yaml:"-"`
+
 	Local Local `yaml:"-"`
 }
 
@@ -193,6 +202,10 @@ type DeploySpec struct {
 	ServiceAccountName string `yaml:"serviceAccountName,omitempty"`
 
 	Subscriptions []KnativeSubscription `yaml:"subscriptions,omitempty"`
+
+	// Rollout of new revisions of the function, such as a canary which
+	// receives a percent of traffic until promoted.
+	Rollout *RolloutSpec `yaml:"rollout,omitempty"`
 }
 
 // HealthEndpoints specify the liveness and readiness endpoints for a Runtime
@@ -316,8 +329,10 @@ func (f Function) Validate() error {
 		ValidateBuildEnvs(f.Build.BuildEnvs),
 		ValidateEnvs(f.Run.Envs),
 		validateOptions(f.Deploy.Options),
+		validateRollout(f.Deploy.Rollout),
 		ValidateLabels(f.Deploy.Labels),
 		validateGit(f.Build.Git),
+		validateEnvironments(f.Environments),
 	}
 
 	var b strings.Builder
@@ -410,6 +425,12 @@ func nameFromPath(path string) string {
 
 // Write Function struct (metadata) to Disk at f.Root
 func (f Function) Write() (err error) {
+	// A function targeted at a named environment only persists the state of
+	// that environment's instance.
+	if f.Environment != "" {
+		return f.writeEnvironment()
+	}
+
 	// Skip writing (and dirtying the work tree) if there were no modifications.
 	f1, _ := NewFunction(f.Root)
 	if reflect.DeepEqual(f, f1) {
@@ -472,8 +493,10 @@ func WithStampJournal() stampOption {
 // stamp is checked before certain operations, and if it has been updated,
 // the build can be skuipped.  If in doubt, just use .Write only.
 //
-// Updates the build stamp at ./func/built (and the log
+// Updates the build stamp at .func/built-hash (and the log
 // at .func/built.log) to reflect the current state of the filesystem.
+// Functions targeted at a named environment are stamped in
+// .func/environments/<name> instead.
 // Note that the caller should call .Write first to flush any changes to the
 // function in-memory to the filesystem prior to calling stamp.
 //
@@ -487,6 +510,10 @@ func (f Function) Stamp(oo ...stampOption) (err error) {
 	if err = ensureRunDataDir(f.Root); err != nil {
 		return
 	}
+	dir := f.runDataDir()
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return
+	}
 
 	// Cacluate the hash and a logfile of what comprised it
 	var hash, log string
@@ -495,7 +522,7 @@ func (f Function) Stamp(oo ...stampOption) (err error) {
 	}
 
 	// Write out the hash
-	if err = os.WriteFile(filepath.Join(f.Root, RunDataDir, BuiltHash), []byte(hash), os.ModePerm); err != nil {
+	if err = os.WriteFile(filepath.Join(dir, BuiltHash), []byte(hash), os.ModePerm); err != nil {
 		return
 	}
 
@@ -504,7 +531,7 @@ func (f Function) Stamp(oo ...stampOption) (err error) {
 	if options.journal {
 		logfileName = timestamp(logfileName)
 	}
-	logfile, err := os.Create(filepath.Join(f.Root, RunDataDir, logfileName))
+	logfile, err := os.Create(filepath.Join(dir, logfileName))
 	if err != nil {
 		return
 	}
@@ -652,7 +679,7 @@ var (
 
 // Built returns true if the function is considered built.
 // Note that this only considers the function as it exists on-disk at
-// f.Root.
+// f.Root, and the build stamp of the environment at which it is targeted.
 func (f Function) Built() bool {
 	// If there is no build stamp, it is not built.
 	stamp := f.BuildStamp()
@@ -694,10 +721,11 @@ func (f Function) Built() bool {
 	return true
 }
 
-// BuildStamp accesses the current (last) build stamp for the function.
-// Unbuilt functions return empty string.
+// BuildStamp accesses the current (last) build stamp for the function, or
+// for the environment at which it is targeted.  Unbuilt functions return
+// empty string.
 func (f Function) BuildStamp() string {
-	path := filepath.Join(f.Root, RunDataDir, BuiltHash)
+	path := filepath.Join(f.runDataDir(), BuiltHash)
 	if _, err := os.Stat(path); err != nil {
 		return ""
 	}
@@ -729,14 +757,18 @@ func (f Function) newLocal() (localConfig Local, err error) {
 }
 
 // WriteRuntimeBuiltImage writes built image name into runtime metadata
-// directory (.func/) from f.Build.Image
+// directory (.func/, or .func/environments/<name> when targeted at a named
+// environment) from f.Build.Image
 func (f Function) WriteRuntimeBuiltImage(verbose bool) error {
-	path := filepath.Join(f.Root, RunDataDir, BuiltImage)
+	path := filepath.Join(f.runDataDir(), BuiltImage)
 
 	// dont write if empty (not built)
 	if f.Build.Image == "" {
 		return nil
 	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
 
 	if verbose {
 		fmt.Printf("Writing built image: '%s' at path: '%s'\n", f.Build.Image, path)
@@ -745,10 +777,11 @@ func (f Function) WriteRuntimeBuiltImage(verbose bool) error {
 	return os.WriteFile(path, []byte(f.Build.Image), os.ModePerm)
 }
 
-// getLastBuiltImage reads .func/built-image and returns its value or empty string
+// getLastBuiltImage reads .func/built-image (or that of the environment at
+// which the function is targeted) and returns its value or empty string
 // if the file doesnt exist (not built yet). Other errors are returned as usual.
 func (f Function) getLastBuiltImage() (string, error) {
-	path := filepath.Join(f.Root, RunDataDir, BuiltImage)
+	path := filepath.Join(f.runDataDir(), BuiltImage)
 	if _, err := os.Stat(path); err != nil {
 		if os.IsNotExist(err) {
 			return "", nil
@@ -0,0 +1,254 @@
+package functions
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// Environments is a set of named deployment environments (for example
+// "staging" and "prod") keyed by environment name.
+type Environments map[string]EnvironmentSpec
+
+// EnvironmentSpec defines overrides applied to a function when it is
+// targeted at a named environment.  Unset members retain the value defined
+// by the function itself.
+type EnvironmentSpec struct {
+	// Namespace into which the function is deployed in this environment.
+	Namespace string `yaml:"namespace,omitempty"`
+
+	// Registry at which to store the function's image for this environment.
+	Registry string `yaml:"registry,omitempty"`
+
+	// Domain to use for the function's route in this environment.
+	Domain string `yaml:"domain,omitempty"`
+
+	// Envs are added to the function's runtime environment variables,
+	// replacing any of the same name.
+	Envs Envs `yaml:"envs,omitempty"`
+
+	// Options replace the function's scale and resource options when set.
+	Options Options `yaml:"options,omitempty"`
+
+	// Labels are added to the function's labels, replacing any of the
+	// same key.
+	Labels []Label `yaml:"labels,omitempty"`
+
+	// Rollout replaces the function's rollout when set.
+	Rollout *RolloutSpec `yaml:"rollout,omitempty"`
+
+	// Deploy records the state of the function as last deployed to this
+	// environment.  It is managed by the system.
+	Deploy EnvironmentDeploy `yaml:"deploy,omitempty"`
+}
+
+// EnvironmentDeploy is the deployed state of a function instance in a
+// named environment.
+type EnvironmentDeploy struct {
+	// Namespace into which the function was deployed.
+	Namespace string `yaml:"namespace,omitempty"`
+
+	// Image is the deployed image including sha256
+	Image string `yaml:"image,omitempty"`
+}
+
+// Names of the environments, sorted.
+func (ee Environments) Names() []string {
+	names := make([]string, 0, len(ee))
+	for name := range ee {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ErrInvalidEnvironment indicates a named environment which is not defined
+// by the function.
+type ErrInvalidEnvironment struct {
+	Name  string
+	Known []string
+}
+
+func (e ErrInvalidEnvironment) Error() string {
+	if len(e.Known) == 0 {
+		return fmt.Sprintf("environment %q not found; the function defines no environments", e.Name)
+	}
+	return fmt.Sprintf("environment %q not found; defined environments are %v", e.Name, e.Known)
+}
+
+// Is allows an ErrInvalidEnvironment to be matched as ErrEnvironmentNotFound
+func (e ErrInvalidEnvironment) Is(target error) bool {
+	return target == ErrEnvironmentNotFound
+}
+
+// ForEnvironment returns the function as it is to be deployed to the named
+// environment: the environment's values merged over those of the function.
+// The returned function records the environment name such that .Write
+// persists its deployed state to that environment rather than to the
+// function's defaults.  An empty name returns the function unchanged.
+func (f Function) ForEnvironment(name string) (Function, error) {
+	if name == "" {
+		return f, nil
+	}
+	e, ok := f.Environments[name]
+	if !ok {
+		return f, ErrInvalidEnvironment{Name: name, Known: f.Environments.Names()}
+	}
+	if e.Namespace != "" {
+		f.Namespace = e.Namespace
+	}
+	if e.Registry != "" {
+		f.Registry = e.Registry
+	}
+	if e.Domain != "" {
+		f.Domain = e.Domain
+	}
+	f.Run.Envs = mergeEnvironmentEnvs(f.Run.Envs, e.Envs)
+	f.Deploy.Labels = mergeEnvironmentLabels(f.Deploy.Labels, e.Labels)
+	if e.Options.Scale != nil {
+		f.Deploy.Options.Scale = e.Options.Scale
+	}
+	if e.Options.Resources != nil {
+		f.Deploy.Options.Resources = e.Options.Resources
+	}
+	if e.Rollout != nil {
+		f.Deploy.Rollout = e.Rollout
+	}
+
+	// Each environment has its own built image and build stamp, held in
+	// .func/environments/<name> alongside the function's own, such that
+	// building for one does not invalidate (nor validate) the build of
+	// another.  Without one, the function's image is used unless the
+	// environment stores images in another registry.
+	f.Environment = name
+	image, err := f.getLastBuiltImage()
+	if err != nil {
+		return f, err
+	}
+	if image != "" {
+		f.Build.Image = image
+	} else if e.Registry != "" {
+		f.Build.Image = ""
+	}
+
+	// Each environment is a separate instance, so the deployed state is
+	// always that of the environment.  The image falls back to that of the
+	// function such that an image may be promoted without rebuilding.
+	f.Deploy.Namespace = e.Deploy.Namespace
+	if e.Deploy.Image != "" {
+		f.Deploy.Image = e.Deploy.Image
+	}
+	return f, nil
+}
+
+// writeEnvironment persists the built and deployed state of a function which
+// was targeted at a named environment: the deployed state to the
+// environment in func.yaml, and the built image to the environment's runtime
+// data directory.  The remainder of the function is a merged view, so the
+// function's own values (including its local settings) are not altered.
+func (f Function) writeEnvironment() error {
+	base, err := NewFunction(f.Root)
+	if err != nil {
+		return err
+	}
+	e, ok := base.Environments[f.Environment]
+	if !ok {
+		return ErrInvalidEnvironment{Name: f.Environment, Known: base.Environments.Names()}
+	}
+	e.Deploy = EnvironmentDeploy{
+		Namespace: f.Deploy.Namespace,
+		Image:     f.Deploy.Image,
+	}
+	base.Environments[f.Environment] = e
+	if err = base.Write(); err != nil {
+		return err
+	}
+	return f.WriteRuntimeBuiltImage(false)
+}
+
+// environmentsDir is the directory within the runtime data directory which
+// holds the built state and deployment history of each named environment.
+const environmentsDir = "environments"
+
+// runDataDir returns the runtime data directory which holds the built state
+// and deployment history of the function: .func for the function itself, and
+// .func/environments/<name> when targeted at a named environment.
+func (f Function) runDataDir() string {
+	if f.Environment == "" {
+		return filepath.Join(f.Root, RunDataDir)
+	}
+	return filepath.Join(f.Root, RunDataDir, environmentsDir, f.Environment)
+}
+
+// mergeEnvironmentEnvs returns a new set of envs consisting of the given
+// envs with overrides applied by name.  Overrides without a name (such as
+// those which include all keys of a Secret) are appended.
+func mergeEnvironmentEnvs(envs, overrides Envs) Envs {
+	if len(overrides) == 0 {
+		return envs
+	}
+	merged := make(Envs, 0, len(envs)+len(overrides))
+	merged = append(merged, envs...)
+	for _, o := range overrides {
+		replaced := false
+		for i, e := range merged {
+			if o.Name != nil && e.Name != nil && *o.Name == *e.Name {
+				merged[i] = o
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, o)
+		}
+	}
+	return merged
+}
+
+// mergeEnvironmentLabels returns a new set of labels consisting of the
+// given labels with overrides applied by key.
+func mergeEnvironmentLabels(labels, overrides []Label) []Label {
+	if len(overrides) == 0 {
+		return labels
+	}
+	merged := make([]Label, 0, len(labels)+len(overrides))
+	merged = append(merged, labels...)
+	for _, o := range overrides {
+		replaced := false
+		for i, l := range merged {
+			if o.Key != nil && l.Key != nil && *o.Key == *l.Key {
+				merged[i] = o
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, o)
+		}
+	}
+	return merged
+}
+
+// validateEnvironments checks that the named environments are correctly
+// defined.  Returns array of error messages, empty if no errors are found
+func validateEnvironments(environments Environments) (errors []string) {
+	for _, name := range environments.Names() {
+		if name == EnvironmentLocal || name == EnvironmentRemote {
+			errors = append(errors, fmt.Sprintf("environment name %q is reserved", name))
+		}
+		e := environments[name]
+		for _, s := range ValidateEnvs(e.Envs) {
+			errors = append(errors, fmt.Sprintf("environment %q: %s", name, s))
+		}
+		for _, s := range ValidateLabels(e.Labels) {
+			errors = append(errors, fmt.Sprintf("environment %q: %s", name, s))
+		}
+		for _, s := range validateOptions(e.Options) {
+			errors = append(errors, fmt.Sprintf("environment %q: %s", name, s))
+		}
+		for _, s := range validateRollout(e.Rollout) {
+			errors = append(errors, fmt.Sprintf("environment %q: %s", name, s))
+		}
+	}
+	return
+}
@@ -0,0 +1,243 @@
+//go:build !integration
+// +build !integration
+
+package functions
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"knative.dev/pkg/ptr"
+
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestFunction_ForEnvironment ensures that the values of a named environment
+// are merged over those of the function.
+func TestFunction_ForEnvironment(t *testing.T) {
+	f := Function{
+		Name:      "f",
+		Namespace: "default",
+		Registry:  "example.com/alice",
+		Run: RunSpec{
+			Envs: Envs{
+				{Name: ptr.String("A"), Value: ptr.String("a")},
+				{Name: ptr.String("B"), Value: ptr.String("b")},
+			},
+		},
+		Deploy: DeploySpec{
+			Namespace: "default",
+			Image:     "example.com/alice/f@sha256:1234",
+			Labels:    []Label{{Key: ptr.String("team"), Value: ptr.String("a")}},
+			Options:   Options{Scale: &ScaleOptions{Min: ptr.Int64(0)}},
+		},
+		Environments: Environments{
+			"staging": {
+				Namespace: "staging",
+				Registry:  "example.com/staging",
+				Domain:    "staging.example.com",
+				Envs:      Envs{{Name: ptr.String("B"), Value: ptr.String("staging")}},
+				Labels:    []Label{{Key: ptr.String("tier"), Value: ptr.String("staging")}},
+				Options:   Options{Scale: &ScaleOptions{Min: ptr.Int64(1)}},
+			},
+		},
+	}
+
+	sf, err := f.ForEnvironment("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf.Environment != "staging" {
+		t.Errorf("expected environment 'staging', got %q", sf.Environment)
+	}
+	if sf.Namespace != "staging" || sf.Registry != "example.com/staging" || sf.Domain != "staging.example.com" {
+		t.Errorf("environment values not applied: %q %q %q", sf.Namespace, sf.Registry, sf.Domain)
+	}
+	if got := sf.Run.Envs.String(); got != "A=a B=staging" {
+		t.Errorf("expected envs 'A=a B=staging', got %q", got)
+	}
+	if len(sf.Deploy.Labels) != 2 {
+		t.Errorf("expected 2 labels, got %v", len(sf.Deploy.Labels))
+	}
+	if *sf.Deploy.Options.Scale.Min != 1 {
+		t.Errorf("expected scale options from environment, got min %v", *sf.Deploy.Options.Scale.Min)
+	}
+	// Not yet deployed to the environment: no namespace, but the image of the
+	// function is retained for promotion.
+	if sf.Deploy.Namespace != "" {
+		t.Errorf("expected no deployed namespace, got %q", sf.Deploy.Namespace)
+	}
+	if sf.Deploy.Image != f.Deploy.Image {
+		t.Errorf("expected image %q, got %q", f.Deploy.Image, sf.Deploy.Image)
+	}
+
+	// The original function must not be altered by merging.
+	if got := f.Run.Envs.String(); got != "A=a B=b" {
+		t.Errorf("function envs were modified: %q", got)
+	}
+
+	// An empty environment name is the function itself.
+	if df, err := f.ForEnvironment(""); err != nil || df.Environment != "" {
+		t.Errorf("expected unchanged function for empty environment, got %q %v", df.Environment, err)
+	}
+
+	// Undefined environments are an error.
+	if _, err := f.ForEnvironment("prod"); !errors.Is(err, ErrEnvironmentNotFound) {
+		t.Errorf("expected ErrEnvironmentNotFound, got %v", err)
+	}
+}
+
+// TestFunction_WriteEnvironment ensures that writing a function which is
+// targeted at a named environment records the deployed state of that
+// environment only.
+func TestFunction_WriteEnvironment(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	f, err := New().Init(Function{Root: root, Runtime: "go", Namespace: "default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Deploy.Namespace = "default"
+	f.Environments = Environments{"staging": {Namespace: "staging"}}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	sf, err := f.ForEnvironment("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf.Deploy.Namespace = "staging"
+	sf.Deploy.Image = "example.com/alice/f@sha256:1234"
+	sf.Build.Image = "example.com/alice/f:staging"
+	if err = sf.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	if f, err = NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	if f.Namespace != "default" || f.Deploy.Namespace != "default" {
+		t.Errorf("function values altered by environment write: %q %q", f.Namespace, f.Deploy.Namespace)
+	}
+	e := f.Environments["staging"]
+	if e.Deploy.Namespace != "staging" || e.Deploy.Image != sf.Deploy.Image {
+		t.Errorf("environment deployed state not recorded: %+v", e.Deploy)
+	}
+	if f.Build.Image != "" {
+		t.Errorf("function's image altered by environment write: %q", f.Build.Image)
+	}
+	// The built image is local state, recorded in the environment's runtime
+	// data directory rather than in func.yaml.
+	image, err := os.ReadFile(filepath.Join(root, RunDataDir, "environments", "staging", BuiltImage))
+	if err != nil || string(image) != sf.Build.Image {
+		t.Errorf("environment built image not recorded: %q (%v)", image, err)
+	}
+	bb, err := os.ReadFile(filepath.Join(root, FunctionFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(bb), sf.Build.Image) {
+		t.Errorf("environment built image written to %v:\n%s", FunctionFile, bb)
+	}
+
+	// Reloading the environment reflects its deployed state.
+	if sf, err = f.ForEnvironment("staging"); err != nil {
+		t.Fatal(err)
+	}
+	if sf.Deploy.Namespace != "staging" {
+		t.Errorf("expected deployed namespace 'staging', got %q", sf.Deploy.Namespace)
+	}
+	if sf.Build.Image != "example.com/alice/f:staging" {
+		t.Errorf("expected the environment's image, got %q", sf.Build.Image)
+	}
+}
+
+// TestFunction_BuiltEnvironment ensures that each environment has its own
+// build stamp, such that building for one environment does not mark the
+// function as built for another.
+func TestFunction_BuiltEnvironment(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	f, err := New().Init(Function{Root: root, Runtime: "go", Registry: "example.com/alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Environments = Environments{"staging": {}, "prod": {}}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	sf, err := f.ForEnvironment("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf.Build.Image = "example.com/alice/f:staging"
+	if err = sf.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if err = sf.Stamp(); err != nil {
+		t.Fatal(err)
+	}
+
+	if f, err = NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	if sf, err = f.ForEnvironment("staging"); err != nil {
+		t.Fatal(err)
+	}
+	if !sf.Built() {
+		t.Error("expected the staging environment to be built")
+	}
+	pf, err := f.ForEnvironment("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pf.Built() {
+		t.Error("expected the prod environment to not be built")
+	}
+	if f.Built() {
+		t.Error("expected the function itself to not be built")
+	}
+}
+
+func Test_validateEnvironments(t *testing.T) {
+	tests := []struct {
+		name         string
+		environments Environments
+		errs         int
+	}{
+		{
+			name:         "valid",
+			environments: Environments{"staging": {Namespace: "staging", Envs: Envs{{Name: ptr.String("A"), Value: ptr.String("a")}}}},
+			errs:         0,
+		},
+		{
+			name:         "reserved names",
+			environments: Environments{EnvironmentLocal: {}, EnvironmentRemote: {}},
+			errs:         2,
+		},
+		{
+			name:         "invalid env",
+			environments: Environments{"staging": {Envs: Envs{{Name: ptr.String("*invalid"), Value: ptr.String("a")}}}},
+			errs:         1,
+		},
+		{
+			name:         "invalid label",
+			environments: Environments{"staging": {Labels: []Label{{Value: ptr.String("a")}}}},
+			errs:         1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateEnvironments(tt.environments); len(errs) != tt.errs {
+				t.Errorf("expected %v errors, got %v: %v", tt.errs, len(errs), errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package functions
+
+import (
+	"fmt"
+	"time"
+)
+
+// RolloutSpec defines how a new revision of a deployed function is rolled
+// out.  By default a new revision receives all traffic once ready.
+type RolloutSpec struct {
+	// Percent of traffic routed to a new revision (the canary), with the
+	// remainder routed to the revision which received it before (the stable
+	// revision), until the rollout is promoted or aborted.  Zero routes all
+	// traffic to the new revision.
+	Percent int64 `yaml:"percent,omitempty" jsonschema:"maximum=99" jsonschema_extras:"minimum=0"`
+
+	// Step by which the percent of traffic routed to the canary is increased
+	// each interval, until it is promoted upon reaching 100.  By default the
+	// traffic is not stepped, and the canary is promoted explicitly.
+	Step int64 `yaml:"step,omitempty" jsonschema:"maximum=100" jsonschema_extras:"minimum=0"`
+
+	// Interval between steps, as a duration such as "5m".
+	Interval string `yaml:"interval,omitempty"`
+}
+
+// Canary returns true if a new revision is rolled out as a canary.
+func (r *RolloutSpec) Canary() bool {
+	return r != nil && r.Percent > 0
+}
+
+// WithPercent returns a copy of the rollout which routes the given percent
+// of traffic to the canary.
+func (r *RolloutSpec) WithPercent(percent int64) *RolloutSpec {
+	c := RolloutSpec{}
+	if r != nil {
+		c = *r
+	}
+	c.Percent = percent
+	return &c
+}
+
+// validateRollout checks that the rollout is correctly set.
+// Returns array of error messages, empty if no errors are found
+func validateRollout(r *RolloutSpec) (errors []string) {
+	if r == nil {
+		return
+	}
+	if r.Percent < 0 || r.Percent > 99 {
+		errors = append(errors, fmt.Sprintf("rollout field \"percent\" has invalid value set: %d, the value must be between 0 and 99", r.Percent))
+	}
+	if r.Step < 0 || r.Step > 100 {
+		errors = append(errors, fmt.Sprintf("rollout field \"step\" has invalid value set: %d, the value must be between 0 and 100", r.Step))
+	}
+	if r.Interval != "" {
+		if d, err := time.ParseDuration(r.Interval); err != nil || d <= 0 {
+			errors = append(errors, fmt.Sprintf("rollout field \"interval\" has invalid value set: %q, the value must be a positive duration such as \"5m\"", r.Interval))
+		}
+	} else if r.Step > 0 {
+		errors = append(errors, "rollout field \"interval\" is required when \"step\" is set")
+	}
+	return
+}
@@ -0,0 +1,117 @@
+//go:build !integration
+// +build !integration
+
+package functions_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestFunction_Validate_Rollout ensures that the rollout of a function is
+// validated.
+func TestFunction_Validate_Rollout(t *testing.T) {
+	tests := []struct {
+		name    string
+		rollout fn.RolloutSpec
+		valid   bool
+	}{
+		{"canary", fn.RolloutSpec{Percent: 10}, true},
+		{"stepped", fn.RolloutSpec{Percent: 10, Step: 30, Interval: "5m"}, true},
+		{"percent of all traffic", fn.RolloutSpec{Percent: 100}, false},
+		{"negative percent", fn.RolloutSpec{Percent: -1}, false},
+		{"step without interval", fn.RolloutSpec{Percent: 10, Step: 30}, false},
+		{"invalid interval", fn.RolloutSpec{Percent: 10, Step: 30, Interval: "often"}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rollout := test.rollout
+			f := fn.Function{Root: "/", Deploy: fn.DeploySpec{Rollout: &rollout}}
+			if err := f.Validate(); (err == nil) != test.valid {
+				t.Fatalf("expected valid %v, got %v", test.valid, err)
+			}
+		})
+	}
+}
+
+// TestClient_Deploy_Canary ensures that the rollout of a function is that
+// deployed unless overridden, and that a stepped rollout routes increasing
+// traffic to the canary until promoted.
+func TestClient_Deploy_Canary(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	var rollout *fn.RolloutSpec
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		rollout = f.Deploy.Rollout
+		return fn.DeploymentResult{Status: fn.Updated, Namespace: "prod"}, nil
+	}
+	var routed []int64
+	router := mock.NewRouter()
+	router.RouteFn = func(_ context.Context, _ fn.Function, percent int64) error {
+		routed = append(routed, percent)
+		return nil
+	}
+	client := fn.New(fn.WithDeployer(deployer), fn.WithRouter(router))
+	f, err := client.Init(fn.Function{Runtime: "go", Root: root, Namespace: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Deploy.Image = "example.com/alice/f@sha256:1"
+	f.Deploy.Rollout = &fn.RolloutSpec{Percent: 10, Step: 40, Interval: "1ms"}
+
+	// Overridden
+	if _, err = client.Deploy(context.Background(), f, fn.WithDeploySkipBuildCheck(true), fn.WithDeployCanary(0)); err != nil {
+		t.Fatal(err)
+	}
+	if rollout.Canary() || router.RouteInvoked {
+		t.Fatalf("expected the canary to be overridden, got %v", rollout)
+	}
+
+	// Stepped
+	if f, err = client.Deploy(context.Background(), f, fn.WithDeploySkipBuildCheck(true)); err != nil {
+		t.Fatal(err)
+	}
+	if rollout.Percent != 10 {
+		t.Fatalf("expected a canary of 10%%, got %v", rollout)
+	}
+	if !reflect.DeepEqual(routed, []int64{50, 90, 100}) {
+		t.Fatalf("unexpected steps %v", routed)
+	}
+	if f.Deploy.Rollout.Percent != 10 {
+		t.Fatal("the rollout of the function should not be altered")
+	}
+}
+
+// TestClient_Promote ensures that promoting routes all traffic to the canary
+// and aborting none, and that a function must be deployed.
+func TestClient_Promote(t *testing.T) {
+	var routed []int64
+	router := mock.NewRouter()
+	router.RouteFn = func(_ context.Context, _ fn.Function, percent int64) error {
+		routed = append(routed, percent)
+		return nil
+	}
+	client := fn.New(fn.WithRouter(router))
+
+	f := fn.Function{Name: "myfunc"}
+	if err := client.Promote(context.Background(), f); err == nil {
+		t.Fatal("expected an error promoting a function which has not been deployed")
+	}
+	f.Deploy.Namespace = "prod"
+	if err := client.Promote(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AbortRollout(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(routed, []int64{100, 0}) {
+		t.Fatalf("unexpected traffic routed %v", routed)
+	}
+}
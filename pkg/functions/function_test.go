@@ -231,7 +231,8 @@ func TestFunction_MigrationError(t *testing.T) {
 
 // TestFunction_Built ensures that the function's Built method reports
 // filesystem changes as indicating the function is no longer Built (aka stale)
-// This includes modifying timestamps, removing or adding files.
+// This includes modifying the content of files, removing or adding files,
+// but not modifying timestamps nor changing files ignored by .funcignore.
 func TestFunction_Built(t *testing.T) {
 	var (
 		ctx      = context.Background()
@@ -259,16 +260,35 @@ func TestFunction_Built(t *testing.T) {
 	// Release thread and wait to ensure that the clock advances even in constrained CI environments
 	time.Sleep(100 * time.Millisecond)
 
-	// Edit the filesystem by touching a file (updating modified timestamp)
+	// Edit the filesystem by touching a file (updating modified timestamp),
+	// which leaves its content unchanged.
 	if err := os.Chtimes(filepath.Join(root, "func.yaml"), time.Now(), time.Now()); err != nil {
 		fmt.Println(err)
 	}
+	if !f.Built() {
+		t.Fatal("client detected a file timestamp change as indicating build staleness")
+	}
 
-	// Release thread and wait to ensure that the clock advances even in constrained CI environments
-	time.Sleep(100 * time.Millisecond)
+	// Edit a file ignored by the .funcignore
+	if err := os.WriteFile(filepath.Join(root, ".funcignore"), []byte("*.md\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = client.Build(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "README.md"), []byte("# f\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !f.Built() {
+		t.Fatal("client detected a change to an ignored file as indicating build staleness")
+	}
 
+	// Edit the content of a file
+	if err := os.WriteFile(filepath.Join(root, "handle.go"), []byte("package function\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
 	if f.Built() {
-		t.Fatal("client did not detect file timestamp change as indicating build staleness")
+		t.Fatal("client did not detect a file content change as indicating build staleness")
 	}
 
 	// Build and double-check Built has been reset
@@ -365,8 +385,8 @@ func TestFunction_Stamp(t *testing.T) {
 	time.Sleep(1 * time.Second)
 
 	// Editing the filesystem and re-stamping should have an effect
-	if err := os.Chtimes(filepath.Join(root, "func.yaml"), time.Now(), time.Now()); err != nil {
-		fmt.Println(err)
+	if err := os.WriteFile(filepath.Join(root, "handle.go"), []byte("package function\n"), 0644); err != nil {
+		t.Fatal(err)
 	}
 	if err = f.Stamp(); err != nil {
 		t.Fatal(err)
@@ -0,0 +1,229 @@
+package functions
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// HistoryFile is the name of the file within the runtime data directory
+	// (RunDataDir) which holds the deployment history of a function.  Each
+	// named environment has its own history, in its own directory of
+	// .func/environments.
+	HistoryFile = "history.yaml"
+
+	// HistoryAnnotation is the annotation of the deployed instance of a
+	// function which mirrors its deployment history, without the func.yaml
+	// of each deployment.
+	HistoryAnnotation = "function.knative.dev/history"
+
+	// HistoryLimit is the number of deployments retained in the history.
+	// Older deployments are discarded, as the history is mirrored as an
+	// annotation, whose size is limited.
+	HistoryLimit = 10
+)
+
+// Deployment is an entry in the deployment history of a function.
+type Deployment struct {
+	// Number of the deployment, which increases with each deployment.
+	Number int `yaml:"number" json:"number"`
+
+	// Timestamp at which the function was deployed.
+	Timestamp time.Time `yaml:"timestamp" json:"timestamp"`
+
+	// Image deployed, with its digest if known.
+	Image string `yaml:"image" json:"image"`
+
+	// Revision of the git repository containing the function, if any, at the
+	// time of the deployment.
+	Revision string `yaml:"revision,omitempty" json:"revision,omitempty"`
+
+	// RollbackTo is the number of the deployment which this deployment
+	// restored, if it was a rollback.
+	RollbackTo int `yaml:"rollbackTo,omitempty" json:"rollbackTo,omitempty"`
+
+	// Function is the func.yaml of the function as deployed.
+	Function string `yaml:"function" json:"function"`
+}
+
+// History of the deployments of a function, oldest first.
+type History []Deployment
+
+// History returns the deployment history of the function, or of the
+// environment at which it is targeted, which is empty if it has not been
+// deployed from its root.
+func (f Function) History() (h History, err error) {
+	bb, err := os.ReadFile(filepath.Join(f.runDataDir(), HistoryFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return History{}, nil
+	} else if err != nil {
+		return
+	}
+	err = yaml.Unmarshal(bb, &h)
+	return
+}
+
+// WriteHistory replaces the deployment history of the function.
+func (f Function) WriteHistory(h History) error {
+	if err := ensureRunDataDir(f.Root); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(f.runDataDir(), os.ModePerm); err != nil {
+		return err
+	}
+	bb, err := yaml.Marshal(h)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(f.runDataDir(), HistoryFile), bb, 0644)
+}
+
+// Deployment returns the deployment with the given number.
+func (h History) Deployment(n int) (Deployment, bool) {
+	for _, d := range h {
+		if d.Number == n {
+			return d, true
+		}
+	}
+	return Deployment{}, false
+}
+
+// Previous returns the deployment preceding that currently deployed.
+// Rollbacks are not themselves returned, nor are the deployments they
+// superseded, such that successive rollbacks step back through the history
+// rather than undoing one another.
+func (h History) Previous() (Deployment, bool) {
+	s := h.effective()
+	if len(s) < 2 {
+		return Deployment{}, false
+	}
+	return s[len(s)-2], true
+}
+
+// effective returns the deployments of the history which remain in effect,
+// oldest first: each rollback discards the deployments following that which
+// it restored.  A rollback to a deployment no longer in the history takes its
+// place.
+func (h History) effective() History {
+	var (
+		s        History
+		restored = map[int]int{} // number of each rollback to that it restored
+	)
+	for _, d := range h {
+		if d.RollbackTo == 0 {
+			s = append(s, d)
+			continue
+		}
+		to := d.RollbackTo
+		if n, ok := restored[to]; ok {
+			to = n // rolled back to a rollback
+		}
+		i := s.index(to)
+		if i < 0 {
+			s = append(s, d)
+			continue
+		}
+		restored[d.Number] = to
+		s = s[:i+1]
+	}
+	return s
+}
+
+// index of the deployment with the given number, or -1 if not found.
+func (h History) index(n int) int {
+	for i, d := range h {
+		if d.Number == n {
+			return i
+		}
+	}
+	return -1
+}
+
+// Record the deployment, numbering it after the latest, and discarding the
+// oldest deployments beyond the HistoryLimit.
+func (h History) Record(d Deployment) History {
+	d.Number = 1
+	if len(h) > 0 {
+		d.Number = h[len(h)-1].Number + 1
+	}
+	h = append(h, d)
+	if len(h) > HistoryLimit {
+		h = h[len(h)-HistoryLimit:]
+	}
+	return h
+}
+
+// annotatedDeployment is a deployment as mirrored in the HistoryAnnotation.
+// The func.yaml of the deployment is omitted, as annotations are limited in
+// size.
+type annotatedDeployment struct {
+	Number    int       `json:"number"`
+	Timestamp time.Time `json:"timestamp"`
+	Image     string    `json:"image"`
+	Revision  string    `json:"revision,omitempty"`
+}
+
+// Annotation returns the value of the HistoryAnnotation mirroring the
+// history.
+func (h History) Annotation() (string, error) {
+	aa := make([]annotatedDeployment, len(h))
+	for i, d := range h {
+		aa[i] = annotatedDeployment{
+			Number:    d.Number,
+			Timestamp: d.Timestamp,
+			Image:     d.Image,
+			Revision:  d.Revision,
+		}
+	}
+	bb, err := json.Marshal(aa)
+	return string(bb), err
+}
+
+// Restore the function as it was deployed, within the root of the given
+// function and targeted at its environment.  The image deployed is restored,
+// such that it is redeployed without being rebuilt.
+func (d Deployment) Restore(f Function) (Function, error) {
+	r := Function{}
+	if err := yaml.Unmarshal([]byte(d.Function), &r); err != nil {
+		return r, err
+	}
+	r.Root = f.Root
+	r.Environment = f.Environment
+	r.Local = f.Local
+	r.Deploy.Image = d.Image
+	return r, nil
+}
+
+// newDeployment returns the deployment of the function at the given time.
+func newDeployment(f Function, t time.Time) (Deployment, error) {
+	bb, err := yaml.Marshal(&f)
+	if err != nil {
+		return Deployment{}, err
+	}
+	return Deployment{
+		Timestamp: t.UTC().Truncate(time.Second),
+		Image:     f.Deploy.Image,
+		Revision:  gitRevision(f.Root),
+		Function:  string(bb),
+	}, nil
+}
+
+// gitRevision returns the revision checked out of the git repository
+// containing the path, or empty string if it is not within one.
+func gitRevision(path string) string {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "" // not a git repository
+	}
+	ref, err := repo.Head()
+	if err != nil {
+		return "" // no commits
+	}
+	return ref.Hash().String()
+}
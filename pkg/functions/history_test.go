@@ -0,0 +1,249 @@
+//go:build !integration
+// +build !integration
+
+package functions_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+	"knative.dev/pkg/ptr"
+)
+
+// TestHistory_Record ensures that deployments are numbered in order, and that
+// only the latest are retained.
+func TestHistory_Record(t *testing.T) {
+	h := fn.History{}
+	for i := 0; i < fn.HistoryLimit+2; i++ {
+		h = h.Record(fn.Deployment{})
+	}
+	if len(h) != fn.HistoryLimit {
+		t.Fatalf("expected %v deployments, got %v", fn.HistoryLimit, len(h))
+	}
+	if h[0].Number != 3 || h[len(h)-1].Number != fn.HistoryLimit+2 {
+		t.Fatalf("unexpected deployments %v to %v", h[0].Number, h[len(h)-1].Number)
+	}
+	if d, ok := h.Previous(); !ok || d.Number != fn.HistoryLimit+1 {
+		t.Fatalf("unexpected previous deployment %v", d.Number)
+	}
+	if _, ok := h.Deployment(1); ok {
+		t.Fatal("expected the first deployment to be discarded")
+	}
+}
+
+// TestHistory_Previous ensures that the deployment preceding that currently
+// deployed skips rollbacks and the deployments they superseded, such that a
+// second rollback does not undo the first.
+func TestHistory_Previous(t *testing.T) {
+	tests := []struct {
+		name     string
+		history  fn.History
+		previous int // zero if none
+	}{
+		{"none", fn.History{}, 0},
+		{"one", fn.History{{Number: 1}}, 0},
+		{"deployed", fn.History{{Number: 1}, {Number: 2}, {Number: 3}}, 2},
+		{"rolled back", fn.History{{Number: 1}, {Number: 2}, {Number: 3}, {Number: 4, RollbackTo: 2}}, 1},
+		{"rolled back twice", fn.History{{Number: 1}, {Number: 2}, {Number: 3}, {Number: 4, RollbackTo: 2}, {Number: 5, RollbackTo: 1}}, 0},
+		{"deployed after rollback", fn.History{{Number: 1}, {Number: 2}, {Number: 3, RollbackTo: 1}, {Number: 4}}, 1},
+		{"rolled back to a rollback", fn.History{{Number: 1}, {Number: 2}, {Number: 3}, {Number: 4, RollbackTo: 2}, {Number: 5}, {Number: 6, RollbackTo: 4}}, 1},
+		{"rolled back to a discarded deployment", fn.History{{Number: 3}, {Number: 4}, {Number: 5, RollbackTo: 1}}, 4},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d, ok := test.history.Previous()
+			if test.previous == 0 {
+				if ok {
+					t.Fatalf("expected no previous deployment, got %v", d.Number)
+				}
+				return
+			}
+			if !ok || d.Number != test.previous {
+				t.Fatalf("expected previous deployment %v, got %v", test.previous, d.Number)
+			}
+		})
+	}
+}
+
+// TestClient_Deploy_History ensures that each deployment is recorded in the
+// history of the function, which is mirrored as an annotation of the
+// deployed instance.
+func TestClient_Deploy_History(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	var annotation string
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		annotation = f.Deploy.Annotations[fn.HistoryAnnotation]
+		return fn.DeploymentResult{Namespace: "prod"}, nil
+	}
+	client := fn.New(fn.WithDeployer(deployer))
+	f, err := client.Init(fn.Function{Runtime: "go", Root: root, Namespace: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, image := range []string{"example.com/alice/f@sha256:1", "example.com/alice/f@sha256:2"} {
+		f.Deploy.Image = image
+		if f, err = client.Deploy(context.Background(), f, fn.WithDeploySkipBuildCheck(true)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := f.Deploy.Annotations[fn.HistoryAnnotation]; ok {
+		t.Fatal("the history annotation should not be written to the function")
+	}
+
+	h, err := f.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 2 || h[0].Number != 1 || h[1].Image != "example.com/alice/f@sha256:2" {
+		t.Fatalf("unexpected history %v", h)
+	}
+	if h[0].Timestamp.IsZero() || h[0].Function == "" {
+		t.Fatalf("expected the deployment to record its time and function, got %v", h[0])
+	}
+	var mirrored fn.History
+	if err = json.Unmarshal([]byte(annotation), &mirrored); err != nil {
+		t.Fatal(err)
+	}
+	if len(mirrored) != 2 || mirrored[1].Image != h[1].Image {
+		t.Fatalf("unexpected mirrored history %v", mirrored)
+	}
+	if mirrored[1].Function != "" {
+		t.Fatal("expected the func.yaml of each deployment to not be mirrored")
+	}
+}
+
+// TestClient_Rollback ensures that rolling back redeploys the image and
+// function of a previous deployment, in place, and records the rollback.
+func TestClient_Rollback(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	var deployed fn.Function
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		deployed = f
+		return fn.DeploymentResult{Namespace: f.Namespace}, nil
+	}
+	client := fn.New(fn.WithDeployer(deployer))
+	f, err := client.Init(fn.Function{Runtime: "go", Root: root, Namespace: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Not yet deployed
+	if _, err = client.Rollback(context.Background(), f, 0); err == nil {
+		t.Fatal("expected an error rolling back a function which has not been deployed")
+	}
+
+	for _, image := range []string{"example.com/alice/f@sha256:1", "example.com/alice/f@sha256:2"} {
+		f.Deploy.Image = image
+		f.Run.Envs = []fn.Env{{Name: ptr.String("DEPLOYMENT"), Value: ptr.String(image)}}
+		if f, err = client.Deploy(context.Background(), f, fn.WithDeploySkipBuildCheck(true)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The previous deployment
+	if _, err = client.Rollback(context.Background(), f, 0); err != nil {
+		t.Fatal(err)
+	}
+	if deployed.Deploy.Image != "example.com/alice/f@sha256:1" || *deployed.Run.Envs[0].Value != "example.com/alice/f@sha256:1" {
+		t.Fatalf("expected the first deployment to be redeployed, got %v with %v", deployed.Deploy.Image, deployed.Run.Envs)
+	}
+	if deployed.Namespace != "prod" {
+		t.Fatalf("expected to redeploy to the current namespace, got %q", deployed.Namespace)
+	}
+	h, err := f.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 3 || h[2].RollbackTo != 1 || h[2].Image != h[0].Image {
+		t.Fatalf("expected the rollback to be recorded, got %v", h)
+	}
+
+	// A numbered deployment
+	if _, err = client.Rollback(context.Background(), f, 2); err != nil {
+		t.Fatal(err)
+	}
+	if deployed.Deploy.Image != "example.com/alice/f@sha256:2" {
+		t.Fatalf("expected the second deployment to be redeployed, got %v", deployed.Deploy.Image)
+	}
+
+	// A deployment not in the history
+	if _, err = client.Rollback(context.Background(), f, 42); !errors.As(err, &fn.ErrDeploymentNotFound{}) {
+		t.Fatalf("expected ErrDeploymentNotFound, got %v", err)
+	}
+}
+
+// TestClient_Rollback_Environments ensures that each environment has its own
+// history, such that a rollback redeploys a previous deployment of the same
+// environment.
+func TestClient_Rollback_Environments(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	var deployed fn.Function
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		deployed = f
+		return fn.DeploymentResult{Namespace: f.Namespace}, nil
+	}
+	client := fn.New(fn.WithDeployer(deployer))
+	f, err := client.Init(fn.Function{Runtime: "go", Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Environments = fn.Environments{"staging": {}, "prod": {}}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Interleave the deployments of the environments
+	envs := map[string]fn.Function{}
+	for i, env := range []string{"prod", "staging", "prod", "staging"} {
+		ef, ok := envs[env]
+		if !ok {
+			if ef, err = f.ForEnvironment(env); err != nil {
+				t.Fatal(err)
+			}
+			ef.Namespace = env
+		}
+		ef.Deploy.Image = fmt.Sprintf("example.com/alice/f@sha256:%v-%v", env, i)
+		if envs[env], err = client.Deploy(context.Background(), ef, fn.WithDeploySkipBuildCheck(true)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err = client.Rollback(context.Background(), envs["staging"], 0); err != nil {
+		t.Fatal(err)
+	}
+	if deployed.Deploy.Image != "example.com/alice/f@sha256:staging-1" || deployed.Namespace != "staging" {
+		t.Fatalf("expected the previous staging deployment to be redeployed to staging, got %v to %v", deployed.Deploy.Image, deployed.Namespace)
+	}
+	if deployed.Environment != "staging" {
+		t.Fatalf("expected the rollback to target the staging environment, got %q", deployed.Environment)
+	}
+
+	for env, n := range map[string]int{"staging": 3, "prod": 2} {
+		h, err := envs[env].History()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(h) != n {
+			t.Fatalf("expected %v deployments of %v, got %v", n, env, h)
+		}
+	}
+	if h, err := f.History(); err != nil || len(h) != 0 {
+		t.Fatalf("expected the function itself to have no deployments, got %v (%v)", h, err)
+	}
+}
@@ -16,6 +16,8 @@ const (
 // InstanceRefs are point-in-time snapshots of a function's runtime state in
 // a given environment.  By default 'local' and 'remote' environmnts are
 // available when a function is run locally and deployed (respectively).
+// Additional named environments are those defined by the function itself
+// (see Function.Environments).
 type InstanceRefs struct {
 	client *Client
 }
@@ -37,12 +39,28 @@ func (s *InstanceRefs) Get(ctx context.Context, f Function, environment string)
 	case EnvironmentRemote:
 		return s.Remote(ctx, f.Name, f.Deploy.Namespace)
 	default:
-		// Future versions will support additional ad-hoc named environments, such
-		// as for testing. Local and remote remaining the base cases.
-		return Instance{}, ErrEnvironmentNotFound
+		// Named environments defined by the function are remote instances
+		// in the environment's namespace.
+		if _, ok := f.Environments[environment]; !ok {
+			return Instance{}, ErrEnvironmentNotFound
+		}
+		return s.Environment(ctx, f, environment)
 	}
 }
 
+// Environment instance details for the function in a named environment
+// defined by the function.  If the function has not been deployed to the
+// environment the error returned is ErrNotRunning.
+func (s *InstanceRefs) Environment(ctx context.Context, f Function, environment string) (i Instance, err error) {
+	if f, err = f.ForEnvironment(environment); err != nil {
+		return
+	}
+	if f.Deploy.Namespace == "" {
+		return i, ErrNotRunning
+	}
+	return s.Remote(ctx, f.Name, f.Deploy.Namespace)
+}
+
 // Local instance details for the function
 // If the function is not running locally the error returned is ErrNotRunning
 func (s *InstanceRefs) Local(ctx context.Context, f Function) (Instance, error) {
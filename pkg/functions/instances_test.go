@@ -119,3 +119,52 @@ func TestInstance_RemoteErrors(t *testing.T) {
 	}
 
 }
+
+// TestInstances_Environment ensures that named environments defined by the
+// function are resolved to the instance deployed in the environment's
+// namespace, and that undefined environments are not found.
+func TestInstances_Environment(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	f, err := New().Init(Function{Runtime: "go", Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Environments = Environments{
+		"staging": {Namespace: "staging"},
+		"prod":    {Namespace: "prod", Deploy: EnvironmentDeploy{Namespace: "prod"}},
+	}
+
+	var describedNamespace string
+	describer := describerFunc(func(_ context.Context, name, namespace string) (Instance, error) {
+		describedNamespace = namespace
+		return Instance{Name: name, Namespace: namespace, Route: "http://" + name + "." + namespace}, nil
+	})
+	i := newInstances(New(WithDescriber(describer)))
+
+	// Undefined
+	if _, err = i.Get(context.Background(), f, "dev"); !errors.Is(err, ErrEnvironmentNotFound) {
+		t.Fatalf("expected ErrEnvironmentNotFound, got %v", err)
+	}
+
+	// Defined but not deployed
+	if _, err = i.Get(context.Background(), f, "staging"); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("expected ErrNotRunning, got %v", err)
+	}
+
+	// Deployed
+	instance, err := i.Get(context.Background(), f, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if describedNamespace != "prod" || instance.Namespace != "prod" {
+		t.Fatalf("expected instance in namespace 'prod', got %q", describedNamespace)
+	}
+}
+
+type describerFunc func(context.Context, string, string) (Instance, error)
+
+func (f describerFunc) Describe(ctx context.Context, name, namespace string) (Instance, error) {
+	return f(ctx, name, namespace)
+}
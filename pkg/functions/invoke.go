@@ -142,6 +142,15 @@ func invocationRoute(ctx context.Context, c *Client, f Function, target string)
 			return "", err // unexpected error
 		}
 		return instance.Route, nil
+	} else if _, ok := f.Environments[target]; ok { // named environment
+		instance, err := c.Instances().Get(ctx, f, target)
+		if errors.Is(err, ErrNotRunning) {
+			return "", fmt.Errorf("not deployed to environment %q", target)
+		}
+		if err != nil {
+			return "", err
+		}
+		return instance.Route, nil
 	} else { // treat an unrecognized target as an ad-hoc verbatim endpoint
 		return target, nil
 	}
@@ -3,13 +3,18 @@ package functions
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 )
 
-const runsDir = "runs"
+const (
+	runsDir = "runs"
+	logFile = "log"
+)
 
 // Job represents a running function job (presumably started by this process'
 // Runner instance.
@@ -22,6 +27,27 @@ type Job struct {
 	Errors   chan error
 	onStop   func() error
 	verbose  bool
+
+	// Detached jobs continue to run after the process which started them
+	// exits.  Their output is written to the job's log file.
+	Detached bool
+
+	// PID of the process serving the function when run on the host, or the
+	// ID of the Container and its Image when run in a container.
+	PID       int
+	Container string
+	Image     string
+
+	// DebugPort is the host port on which a debugger may attach to the
+	// function, if run with debugging enabled.
+	DebugPort string
+
+	// Started is the time at which the job was created.
+	Started time.Time
+
+	stdout, stderr io.Writer // output of the job's processes, if attached
+
+	record string // path of the job's entry in the jobs registry, if any
 }
 
 // Create a new Job which represents a running function task by providing
@@ -35,6 +61,9 @@ func NewJob(f Function, host, port string, errs chan error, onStop func() error,
 		Errors:   errs,
 		onStop:   onStop,
 		verbose:  verbose,
+		Started:  time.Now(),
+		stdout:   os.Stdout,
+		stderr:   os.Stderr,
 	}
 	if !f.Initialized() {
 		return j, errors.New("initialized function required to create job")
@@ -66,6 +95,11 @@ func (j *Job) Stop() error {
 	if err := os.RemoveAll(j.Dir()); err != nil {
 		fmt.Fprintf(os.Stderr, "warning: unable to remove run directory. %v", err)
 	}
+	if j.record != "" {
+		if err := os.Remove(j.record); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "warning: unable to remove job record. %v", err)
+		}
+	}
 	return j.onStop()
 }
 
@@ -75,6 +109,13 @@ func (j *Job) Dir() string {
 	return filepath.Join(funcJobsDir(j.Function), j.Port)
 }
 
+// Log is the path of the file to which the output of a detached job is
+// written.
+// ${f.Root}/.func/runs/${j.Port}/log
+func (j *Job) Log() string {
+	return filepath.Join(j.Dir(), logFile)
+}
+
 // Directory within which all runs (jobs) are held for the given function.
 // ${f.Root}/.func/runs/
 func funcJobsDir(f Function) string {
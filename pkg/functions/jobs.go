@@ -0,0 +1,278 @@
+package functions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// JobInfo describes a job running on this host such that it can be listed
+// and managed by processes other than the one which started it.
+type JobInfo struct {
+	Name      string    `json:"name" yaml:"name"`
+	Root      string    `json:"root" yaml:"root"`
+	Runtime   string    `json:"runtime" yaml:"runtime"`
+	Host      string    `json:"host" yaml:"host"`
+	Port      string    `json:"port" yaml:"port"`
+	PID       int       `json:"pid,omitempty" yaml:"pid,omitempty"`
+	PIDStart  string    `json:"pidStart,omitempty" yaml:"pidStart,omitempty"`
+	DebugPort string    `json:"debugPort,omitempty" yaml:"debugPort,omitempty"`
+	Container string    `json:"container,omitempty" yaml:"container,omitempty"`
+	Image     string    `json:"image,omitempty" yaml:"image,omitempty"`
+	Detached  bool      `json:"detached" yaml:"detached"`
+	Log       string    `json:"log,omitempty" yaml:"log,omitempty"`
+	Started   time.Time `json:"started" yaml:"started"`
+}
+
+// ID of the job: the (short) ID of its container, or the PID of its process.
+func (j JobInfo) ID() string {
+	if j.Container != "" {
+		if len(j.Container) > 12 {
+			return j.Container[:12]
+		}
+		return j.Container
+	}
+	return strconv.Itoa(j.PID)
+}
+
+// Dir is the job's directory within its function's runtime data directory.
+func (j JobInfo) Dir() string {
+	return filepath.Join(j.Root, RunDataDir, runsDir, j.Port)
+}
+
+// ownsPID returns true if the process of the job's PID is that which was
+// recorded, as opposed to one which was assigned the PID after the job's
+// process exited.  Processes whose start could not be recorded can not be
+// verified, and are therefore not considered the job's.
+func (j JobInfo) ownsPID() bool {
+	if j.PIDStart == "" {
+		return false
+	}
+	start, err := processStart(j.PID)
+	return err == nil && start == j.PIDStart
+}
+
+// JobController stops, reads the output of and checks the liveness of jobs
+// which may have been started by other processes.  Runners whose jobs are
+// not processes on this host, such as those which run functions in
+// containers, implement it such that the client can manage their jobs.
+type JobController interface {
+	// StopJob stops and removes the job.
+	StopJob(context.Context, JobInfo) error
+	// JobLogs writes the output of the job to the writer, following the
+	// output until the context is canceled if requested.
+	JobLogs(ctx context.Context, job JobInfo, follow bool, w io.Writer) error
+	// JobRunning returns true if the job is still running.
+	JobRunning(context.Context, JobInfo) (bool, error)
+}
+
+// jobRunning returns true if the job is still running.  Jobs run on the host
+// are running if their process is still that which was recorded, and jobs
+// run in containers if their runner reports so.  The port of a job is not
+// considered, as another process may bind it once the job has exited.
+// Jobs whose runner can not be queried are presumed to be running, such
+// that their records are not removed.
+func (c *Client) jobRunning(ctx context.Context, j JobInfo) bool {
+	if j.Container == "" {
+		return j.ownsPID()
+	}
+	jc, ok := c.runner.(JobController)
+	if !ok {
+		return true
+	}
+	running, err := jc.JobRunning(ctx, j)
+	return err != nil || running
+}
+
+// registerJob records the job in the registry at path.  Jobs are recorded by
+// port, which is unique on the host while they run.  An empty path
+// indicates no registry is used.  Jobs which are neither a process of their
+// own nor run in a container, such as those of in-process runners, are
+// recorded with the PID of this process, which hosts them.
+func registerJob(path string, job *Job) (err error) {
+	if path == "" || job == nil {
+		return
+	}
+	info := JobInfo{
+		Name:      job.Function.Name,
+		Root:      job.Function.Root,
+		Runtime:   job.Function.Runtime,
+		Host:      job.Host,
+		Port:      job.Port,
+		PID:       job.PID,
+		DebugPort: job.DebugPort,
+		Container: job.Container,
+		Image:     job.Image,
+		Detached:  job.Detached,
+		Started:   job.Started,
+	}
+	if info.Name == "" {
+		info.Name = filepath.Base(info.Root)
+	}
+	if info.PID == 0 && info.Container == "" {
+		info.PID = os.Getpid()
+	}
+	if info.PID != 0 {
+		if info.PIDStart, err = processStart(info.PID); err != nil {
+			return fmt.Errorf("cannot record job process. %w", err)
+		}
+	}
+	if job.Detached && job.Container == "" {
+		info.Log = job.Log()
+	}
+	bb, err := yaml.Marshal(info)
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(path, os.ModePerm); err != nil {
+		return fmt.Errorf("cannot create jobs registry. %w", err)
+	}
+	record := filepath.Join(path, job.Port+".yaml")
+	if err = os.WriteFile(record, bb, 0644); err != nil {
+		return fmt.Errorf("cannot record job. %w", err)
+	}
+	job.record = record
+	return
+}
+
+// Jobs returns the jobs running on this host, across functions, as recorded
+// in the client's jobs registry (see WithJobsPath), ordered by name and port.
+// Records of jobs which are no longer running are removed.
+func (c *Client) Jobs() (jobs []JobInfo, err error) {
+	jobs = []JobInfo{}
+	if c.jobsPath == "" {
+		return
+	}
+	ee, err := os.ReadDir(c.jobsPath)
+	if os.IsNotExist(err) {
+		return jobs, nil
+	} else if err != nil {
+		return
+	}
+	for _, e := range ee {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".yaml") {
+			continue
+		}
+		record := filepath.Join(c.jobsPath, e.Name())
+		bb, err := os.ReadFile(record)
+		if err != nil {
+			return jobs, err
+		}
+		var j JobInfo
+		if err = yaml.Unmarshal(bb, &j); err != nil || !c.jobRunning(context.Background(), j) {
+			if c.verbose {
+				fmt.Printf("Job on port %v is no longer running.  Removing its record\n", j.Port)
+			}
+			_ = os.Remove(record)
+			continue
+		}
+		jobs = append(jobs, j)
+	}
+	sort.Slice(jobs, func(a, b int) bool {
+		if jobs[a].Name != jobs[b].Name {
+			return jobs[a].Name < jobs[b].Name
+		}
+		pa, _ := strconv.Atoi(jobs[a].Port)
+		pb, _ := strconv.Atoi(jobs[b].Port)
+		return pa < pb
+	})
+	return
+}
+
+// StopJob stops a job running on this host, which may have been started by
+// another process, and removes its record and job directory.  Jobs run in
+// containers require a runner which is a JobController.  The process of a
+// job run on the host is only signaled if it is still that which was
+// recorded; otherwise the job's stale record is removed and an
+// ErrJobNotRunning returned.
+func (c *Client) StopJob(ctx context.Context, j JobInfo) (err error) {
+	if j.Container != "" {
+		jc, ok := c.runner.(JobController)
+		if !ok {
+			return errors.New("stopping jobs run in containers requires a container runner")
+		}
+		if err = jc.StopJob(ctx, j); err != nil {
+			return
+		}
+	} else {
+		if j.PID == 0 {
+			return fmt.Errorf("job on port %v has no process ID", j.Port)
+		}
+		if j.PID == os.Getpid() {
+			return fmt.Errorf("job on port %v is hosted by this process and is stopped with it", j.Port)
+		}
+		if !j.ownsPID() {
+			if err = c.removeJob(j); err != nil {
+				return
+			}
+			return ErrJobNotRunning{PID: j.PID, Port: j.Port}
+		}
+		if c.verbose {
+			fmt.Printf("Stopping process %v\n", j.PID)
+		}
+		if err = stopProcess(j.PID); err != nil {
+			return fmt.Errorf("cannot stop process %v. %w", j.PID, err)
+		}
+		if err = waitForRelease(j.Host, j.Port, defaultRunStopTimeout); err != nil {
+			return
+		}
+	}
+	return c.removeJob(j)
+}
+
+// removeJob removes the record and job directory of a job.
+func (c *Client) removeJob(j JobInfo) (err error) {
+	if err = os.RemoveAll(j.Dir()); err != nil {
+		return
+	}
+	if c.jobsPath != "" {
+		if err = os.Remove(filepath.Join(c.jobsPath, j.Port+".yaml")); os.IsNotExist(err) {
+			err = nil
+		}
+	}
+	return
+}
+
+// JobLogs writes the output of the job to w.  If follow is set, output is
+// written as it is produced until the job stops or the context is canceled.
+// Only detached jobs have their output retained.
+func (c *Client) JobLogs(ctx context.Context, j JobInfo, follow bool, w io.Writer) (err error) {
+	if j.Container != "" {
+		jc, ok := c.runner.(JobController)
+		if !ok {
+			return errors.New("reading the logs of jobs run in containers requires a container runner")
+		}
+		return jc.JobLogs(ctx, j, follow, w)
+	}
+	if j.Log == "" {
+		return fmt.Errorf("the output of the job on port %v is not retained.  Only detached jobs have logs", j.Port)
+	}
+	file, err := os.Open(j.Log)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	for {
+		if _, err = io.Copy(w, file); err != nil || !follow {
+			return
+		}
+		if !j.ownsPID() {
+			_, err = io.Copy(w, file) // final output
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,57 @@
+//go:build !windows
+
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// detach the command from this process such that it is not signaled along
+// with this process (for example on ^C) and continues to run after it exits.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// stopProcess signals the process to terminate.  Detached processes lead
+// their own process group, which is signaled such that any processes they
+// started are terminated as well.
+func stopProcess(pid int) error {
+	if err := syscall.Kill(-pid, syscall.SIGTERM); err == nil {
+		return nil
+	}
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
+
+// processStart returns an identifier of the time at which the process of the
+// given ID started, such that a process which has since exited can be
+// distinguished from another which was later assigned the same ID.
+func processStart(pid int) (string, error) {
+	if runtime.GOOS == "linux" {
+		// The start time (in clock ticks since boot) is the 22nd field, and
+		// the 20th following the (possibly space-containing) command name.
+		b, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+		if err != nil {
+			return "", err
+		}
+		fields := strings.Fields(string(b[strings.LastIndexByte(string(b), ')')+1:]))
+		if len(fields) < 20 {
+			return "", fmt.Errorf("unexpected format of the status of process %v", pid)
+		}
+		return fields[19], nil
+	}
+	out, err := exec.Command("ps", "-o", "lstart=", "-p", fmt.Sprint(pid)).Output()
+	if err != nil {
+		return "", err
+	}
+	start := strings.TrimSpace(string(out))
+	if start == "" {
+		return "", errors.New("process not found")
+	}
+	return start, nil
+}
@@ -0,0 +1,85 @@
+package functions
+
+import (
+	"context"
+	"time"
+)
+
+// ProgressType identifies the step of a task of the client which a
+// ProgressEvent reports.
+type ProgressType string
+
+const (
+	ProgressBuildStarted    ProgressType = "build.started"
+	ProgressBuildCompleted  ProgressType = "build.completed"
+	ProgressPushStarted     ProgressType = "push.started"
+	ProgressPushUpdated     ProgressType = "push.progress" // bytes pushed so far
+	ProgressLayerPushed     ProgressType = "push.layer"
+	ProgressDigestResolved  ProgressType = "push.digest"
+	ProgressDeployStarted   ProgressType = "deploy.started"
+	ProgressRevisionReady   ProgressType = "deploy.revision"
+	ProgressDeployCompleted ProgressType = "deploy.completed"
+	ProgressRunStarted      ProgressType = "run.started"
+	ProgressRunReady        ProgressType = "run.ready" // accepting requests
+	ProgressError           ProgressType = "error"
+)
+
+// ProgressEvent reports the progress of a task of the client, such as a
+// build, push, deploy or run of a function.  Only the members relevant to
+// the type of the event are set.
+type ProgressEvent struct {
+	Type      ProgressType `json:"type"`
+	Time      time.Time    `json:"time"`
+	Function  string       `json:"function,omitempty"`
+	Message   string       `json:"message,omitempty"`
+	Image     string       `json:"image,omitempty"`
+	Digest    string       `json:"digest,omitempty"`
+	Layer     string       `json:"layer,omitempty"`
+	Complete  int64        `json:"complete,omitempty"` // bytes pushed
+	Total     int64        `json:"total,omitempty"`    // bytes to push
+	Namespace string       `json:"namespace,omitempty"`
+	Revision  string       `json:"revision,omitempty"`
+	URL       string       `json:"url,omitempty"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// ProgressListener receives the progress events of the tasks of a client.
+// Events are delivered synchronously, possibly from multiple goroutines,
+// such as when functions are built concurrently, so a listener should
+// return promptly and be safe for concurrent use.
+type ProgressListener interface {
+	Progress(ProgressEvent)
+}
+
+// ProgressListenerFunc adapts an ordinary function to a ProgressListener.
+type ProgressListenerFunc func(ProgressEvent)
+
+func (f ProgressListenerFunc) Progress(e ProgressEvent) { f(e) }
+
+// progressListenerKey is the context key of the listener of the events
+// reported by the builders, pushers, deployers and runners of a client.
+type progressListenerKey struct{}
+
+// contextWithProgressListener returns a context with which the listener
+// receives the events reported by the client's builders, pushers, deployers
+// and runners.
+func contextWithProgressListener(ctx context.Context, l ProgressListener) context.Context {
+	if l == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, progressListenerKey{}, l)
+}
+
+// ReportProgress to the listener of the client whose task is in progress
+// with the given context, if any.  For use by implementations of Builder,
+// Pusher, Deployer and Runner.  The time of the event defaults to now.
+func ReportProgress(ctx context.Context, e ProgressEvent) {
+	l, ok := ctx.Value(progressListenerKey{}).(ProgressListener)
+	if !ok {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	l.Progress(e)
+}
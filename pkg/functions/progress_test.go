@@ -0,0 +1,98 @@
+//go:build !integration
+// +build !integration
+
+package functions_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestClient_Progress ensures that the progress of a build, push and deploy
+// is reported to the client's listener, including that reported by the
+// pusher and deployer, and that a failure is reported as an error.
+func TestClient_Progress(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	var events []fn.ProgressEvent
+	listener := fn.ProgressListenerFunc(func(e fn.ProgressEvent) {
+		if e.Time.IsZero() {
+			t.Errorf("expected event %v to have a time", e.Type)
+		}
+		events = append(events, e)
+	})
+
+	pusher := mock.NewPusher()
+	pusher.PushFn = func(ctx context.Context, f fn.Function) (string, error) {
+		fn.ReportProgress(ctx, fn.ProgressEvent{Type: fn.ProgressLayerPushed, Function: f.Name, Layer: "sha256:1"})
+		return "sha256:2", nil
+	}
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(ctx context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		fn.ReportProgress(ctx, fn.ProgressEvent{Type: fn.ProgressRevisionReady, Function: f.Name, Revision: "f-00001"})
+		return fn.DeploymentResult{Status: fn.Deployed, Namespace: "prod", URL: "http://f.prod"}, nil
+	}
+	client := fn.New(
+		fn.WithRegistry(TestRegistry),
+		fn.WithPusher(pusher),
+		fn.WithDeployer(deployer),
+		fn.WithProgressListener(listener))
+
+	f, err := client.Init(fn.Function{Runtime: "go", Root: root, Namespace: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, err = client.Build(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if f, _, err = client.Push(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	f.Deploy.Image = f.Build.Image
+	if _, err = client.Deploy(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+
+	types := []fn.ProgressType{}
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	expected := []fn.ProgressType{
+		fn.ProgressBuildStarted,
+		fn.ProgressBuildCompleted,
+		fn.ProgressPushStarted,
+		fn.ProgressLayerPushed,
+		fn.ProgressDigestResolved,
+		fn.ProgressDeployStarted,
+		fn.ProgressRevisionReady,
+		fn.ProgressDeployCompleted,
+	}
+	if !reflect.DeepEqual(types, expected) {
+		t.Fatalf("expected events %v, got %v", expected, types)
+	}
+	if e := events[4]; e.Digest != "sha256:2" || e.Function != f.Name {
+		t.Fatalf("unexpected digest event %+v", e)
+	}
+	if e := events[7]; e.URL != "http://f.prod" || e.Namespace != "prod" {
+		t.Fatalf("unexpected deploy event %+v", e)
+	}
+
+	// A failed deployment is reported as an error
+	events = nil
+	deployer.DeployFn = func(context.Context, fn.Function) (fn.DeploymentResult, error) {
+		return fn.DeploymentResult{}, errors.New("unreachable cluster")
+	}
+	if _, err = client.Deploy(context.Background(), f); err == nil {
+		t.Fatal("expected the deployment to fail")
+	}
+	if e := events[len(events)-1]; e.Type != fn.ProgressError || e.Error != err.Error() {
+		t.Fatalf("expected the error to be reported, got %+v", e)
+	}
+}
@@ -0,0 +1,234 @@
+package functions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	// resourcesDir is the directory within the function's runtime data
+	// directory holding its local resources.
+	resourcesDir = "resources"
+	// secretsDir, configMapsDir and claimsDir are the directories of the
+	// local resources of each kind.
+	secretsDir    = "secrets"
+	configMapsDir = "configmaps"
+	claimsDir     = "volumes"
+)
+
+// LocalResources is the store of the Secrets, ConfigMaps and persistent
+// volumes referenced by a function, with which it is run locally.  It is
+// held in the function's runtime data directory (.func/resources), which is
+// excluded from source control and builds.
+//
+// The layout is that with which Secrets and ConfigMaps are mounted as volumes
+// in a cluster: each is a directory of files, one per key, whose contents are
+// the key's value.  For example:
+//
+//	.func/resources/secrets/{name}/{key}
+//	.func/resources/configmaps/{name}/{key}
+//	.func/resources/volumes/{claimName}/
+//
+// Resources may be created by hand, or fetched from the cluster.
+type LocalResources struct {
+	root string
+}
+
+// NewLocalResources returns the store of local resources of the function.
+func NewLocalResources(f Function) LocalResources {
+	return LocalResources{root: filepath.Join(f.Root, RunDataDir, resourcesDir)}
+}
+
+// SecretPath is the directory of the named Secret.
+func (r LocalResources) SecretPath(name string) string {
+	return filepath.Join(r.root, secretsDir, name)
+}
+
+// ConfigMapPath is the directory of the named ConfigMap.
+func (r LocalResources) ConfigMapPath(name string) string {
+	return filepath.Join(r.root, configMapsDir, name)
+}
+
+// ClaimPath is the directory which stands in for the volume of the named
+// PersistentVolumeClaim.
+func (r LocalResources) ClaimPath(name string) string {
+	return filepath.Join(r.root, claimsDir, name)
+}
+
+// Secret returns the data of the named Secret.
+func (r LocalResources) Secret(name string) (map[string]string, error) {
+	return readResource("Secret", name, r.SecretPath(name))
+}
+
+// ConfigMap returns the data of the named ConfigMap.
+func (r LocalResources) ConfigMap(name string) (map[string]string, error) {
+	return readResource("ConfigMap", name, r.ConfigMapPath(name))
+}
+
+// WriteSecret replaces the named Secret with the given data.  Its files are
+// readable only by the current user.
+func (r LocalResources) WriteSecret(name string, data map[string][]byte) error {
+	return writeResource(r.SecretPath(name), data)
+}
+
+// WriteConfigMap replaces the named ConfigMap with the given data.
+func (r LocalResources) WriteConfigMap(name string, data map[string]string) error {
+	bb := make(map[string][]byte, len(data))
+	for k, v := range data {
+		bb[k] = []byte(v)
+	}
+	return writeResource(r.ConfigMapPath(name), bb)
+}
+
+// Interpolate the given envs as Interpolate, additionally resolving
+// references to the keys of Secrets and ConfigMaps, and to all keys of
+// Secrets and ConfigMaps, from the store:
+//
+//	{{ secret:secretName:key }}
+//	{{ secret:secretName }}
+//	{{ configMap:configMapName:key }}
+//	{{ configMap:configMapName }}
+func (r LocalResources) Interpolate(ee []Env) (map[string]string, error) {
+	envs := make(map[string]string, len(ee))
+	for _, e := range ee {
+		var (
+			data map[string]string
+			key  string
+			err  error
+		)
+		switch {
+		case e.Value == nil:
+			// Not a reference; interpolated below.
+		case e.Name == nil && regWholeSecret.MatchString(*e.Value):
+			data, err = r.Secret(regWholeSecret.FindStringSubmatch(*e.Value)[1])
+		case e.Name == nil && regWholeConfigMap.MatchString(*e.Value):
+			data, err = r.ConfigMap(regWholeConfigMap.FindStringSubmatch(*e.Value)[1])
+		case e.Name != nil && regKeyFromSecret.MatchString(*e.Value):
+			match := regKeyFromSecret.FindStringSubmatch(*e.Value)
+			data, err = r.Secret(match[1])
+			key = match[2]
+		case e.Name != nil && regKeyFromConfigMap.MatchString(*e.Value):
+			match := regKeyFromConfigMap.FindStringSubmatch(*e.Value)
+			data, err = r.ConfigMap(match[1])
+			key = match[2]
+		}
+		if err != nil {
+			return envs, err
+		}
+
+		if data == nil { // Neither a Secret nor a ConfigMap reference
+			values, err := Interpolate([]Env{e})
+			if err != nil {
+				return envs, err
+			}
+			for k, v := range values {
+				envs[k] = v
+			}
+			continue
+		}
+		if key == "" { // All keys
+			for k, v := range data {
+				envs[k] = v
+			}
+			continue
+		}
+		v, ok := data[key]
+		if !ok {
+			return envs, fmt.Errorf("key %q referenced by env %q not found in %v", key, *e.Name, *e.Value)
+		}
+		envs[*e.Name] = v
+	}
+	return envs, nil
+}
+
+// ResourceReferences returns the names of the Secrets and ConfigMaps
+// referenced by the function's envs and volumes.
+func ResourceReferences(f Function) (secrets, configMaps []string) {
+	ss, cc := map[string]bool{}, map[string]bool{}
+	for _, e := range f.Run.Envs {
+		if e.Value == nil {
+			continue
+		}
+		for _, re := range []struct {
+			names map[string]bool
+			match []string
+		}{
+			{ss, regWholeSecret.FindStringSubmatch(*e.Value)},
+			{ss, regKeyFromSecret.FindStringSubmatch(*e.Value)},
+			{cc, regWholeConfigMap.FindStringSubmatch(*e.Value)},
+			{cc, regKeyFromConfigMap.FindStringSubmatch(*e.Value)},
+		} {
+			if len(re.match) > 1 {
+				re.names[re.match[1]] = true
+			}
+		}
+	}
+	for _, v := range f.Run.Volumes {
+		if v.Secret != nil {
+			ss[*v.Secret] = true
+		}
+		if v.ConfigMap != nil {
+			cc[*v.ConfigMap] = true
+		}
+	}
+	return sortedKeys(ss), sortedKeys(cc)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	kk := make([]string, 0, len(m))
+	for k := range m {
+		kk = append(kk, k)
+	}
+	sort.Strings(kk)
+	return kk
+}
+
+// ErrLocalResourceNotFound is returned when a function references a Secret or
+// ConfigMap which is not in its local resources.
+type ErrLocalResourceNotFound struct {
+	Kind string
+	Name string
+	Path string
+}
+
+func (e ErrLocalResourceNotFound) Error() string {
+	return fmt.Sprintf("%v %q not found locally. Expected a directory of files (one per key) at %v", e.Kind, e.Name, e.Path)
+}
+
+func readResource(kind, name, path string) (map[string]string, error) {
+	ee, err := os.ReadDir(path)
+	if os.IsNotExist(err) {
+		return nil, ErrLocalResourceNotFound{Kind: kind, Name: name, Path: path}
+	} else if err != nil {
+		return nil, err
+	}
+	data := make(map[string]string, len(ee))
+	for _, e := range ee {
+		if e.IsDir() {
+			continue
+		}
+		bb, err := os.ReadFile(filepath.Join(path, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		data[e.Name()] = string(bb)
+	}
+	return data, nil
+}
+
+func writeResource(path string, data map[string][]byte) (err error) {
+	if err = os.RemoveAll(path); err != nil {
+		return
+	}
+	if err = os.MkdirAll(path, 0700); err != nil {
+		return
+	}
+	for k, v := range data {
+		if err = os.WriteFile(filepath.Join(path, k), v, 0600); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,83 @@
+//go:build !integration
+// +build !integration
+
+package functions_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+	"knative.dev/pkg/ptr"
+)
+
+// TestLocalResources_Interpolate ensures that references to the keys of
+// Secrets and ConfigMaps, and to all their keys, are resolved from the local
+// resources, along with references to local environment variables.
+func TestLocalResources_Interpolate(t *testing.T) {
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+	t.Setenv("LOCAL", "local-value")
+
+	r := fn.NewLocalResources(fn.Function{Root: root})
+	if err := r.WriteSecret("s", map[string][]byte{"a": []byte("1"), "b": []byte("2")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.WriteConfigMap("c", map[string]string{"c": "3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	envs, err := r.Interpolate([]fn.Env{
+		{Name: ptr.String("PLAIN"), Value: ptr.String("plain")},
+		{Name: ptr.String("LOCAL"), Value: ptr.String("{{ env:LOCAL }}")},
+		{Name: ptr.String("KEY"), Value: ptr.String("{{ secret:s:a }}")},
+		{Value: ptr.String("{{ secret:s }}")},
+		{Value: ptr.String("{{ configMap:c }}")},
+		{Name: ptr.String("CM_KEY"), Value: ptr.String("{{ configMap:c:c }}")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"PLAIN": "plain", "LOCAL": "local-value", "KEY": "1",
+		"a": "1", "b": "2", "c": "3", "CM_KEY": "3",
+	}
+	if !reflect.DeepEqual(envs, expected) {
+		t.Fatalf("expected %v, got %v", expected, envs)
+	}
+
+	// Missing resources and keys are errors.
+	_, err = r.Interpolate([]fn.Env{{Value: ptr.String("{{ secret:missing }}")}})
+	if !errors.As(err, &fn.ErrLocalResourceNotFound{}) {
+		t.Fatalf("expected ErrLocalResourceNotFound, got %v", err)
+	}
+	if _, err = r.Interpolate([]fn.Env{{Name: ptr.String("X"), Value: ptr.String("{{ configMap:c:missing }}")}}); err == nil {
+		t.Fatal("expected an error for a missing key")
+	}
+}
+
+// TestResourceReferences ensures that the Secrets and ConfigMaps referenced
+// by a function's envs and volumes are each returned once.
+func TestResourceReferences(t *testing.T) {
+	f := fn.Function{}
+	f.Run.Envs = []fn.Env{
+		{Name: ptr.String("A"), Value: ptr.String("{{ secret:s1:a }}")},
+		{Value: ptr.String("{{ secret:s2 }}")},
+		{Value: ptr.String("{{ configMap:c1 }}")},
+		{Name: ptr.String("B"), Value: ptr.String("{{ env:B }}")},
+	}
+	f.Run.Volumes = []fn.Volume{
+		{Secret: ptr.String("s1"), Path: ptr.String("/s1")},
+		{ConfigMap: ptr.String("c2"), Path: ptr.String("/c2")},
+		{EmptyDir: &fn.EmptyDir{}, Path: ptr.String("/tmp")},
+	}
+	secrets, configMaps := fn.ResourceReferences(f)
+	if !reflect.DeepEqual(secrets, []string{"s1", "s2"}) {
+		t.Errorf("unexpected secrets %v", secrets)
+	}
+	if !reflect.DeepEqual(configMaps, []string{"c1", "c2"}) {
+		t.Errorf("unexpected configMaps %v", configMaps)
+	}
+}
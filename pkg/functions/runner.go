@@ -2,6 +2,7 @@ package functions
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
@@ -11,7 +12,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"time"
+
+	"knative.dev/func/pkg/filesystem"
+	"knative.dev/func/pkg/funcignore"
 )
 
 const (
@@ -36,41 +42,116 @@ func newDefaultRunner(client *Client, out, err io.Writer) *defaultRunner {
 	}
 }
 
-func (r *defaultRunner) Run(ctx context.Context, f Function, startTimeout time.Duration) (job *Job, err error) {
+func (r *defaultRunner) Run(ctx context.Context, f Function, opts RunOptions) (job *Job, err error) {
 	var (
-		port    string
 		runFn   func() error
 		verbose = r.client.verbose
+		port    = opts.Port
 	)
 
-	port, err = choosePort(defaultRunHost, defaultRunPort)
+	if port == "" {
+		port = defaultRunPort
+	}
+	port, err = choosePort(defaultRunHost, port)
 	if err != nil {
 		return nil, fmt.Errorf("cannot choose port: %w", err)
 	}
 
+	// The job's processes run within a context which is canceled when the job
+	// is stopped.  Stopping waits for the job's port to be released such that
+	// it may be reused, for example by a restart.  The processes of detached
+	// jobs are not bound to the context, and are instead signaled directly.
+	runCtx, cancel := context.WithCancel(ctx)
+	if opts.Detach {
+		runCtx = context.WithoutCancel(runCtx)
+	}
+	defer func() {
+		if err != nil {
+			cancel()
+		}
+	}()
+	stop := func() error {
+		cancel()
+		if opts.Detach && job.PID != 0 {
+			if err := stopProcess(job.PID); err != nil {
+				return err
+			}
+		}
+		return waitForRelease(defaultRunHost, port, defaultRunStopTimeout)
+	}
+
 	// Job contains metadata and references for the running function.
-	job, err = NewJob(f, defaultRunHost, port, nil, nil, verbose)
+	job, err = NewJob(f, defaultRunHost, port, nil, stop, verbose)
 	if err != nil {
 		return
 	}
-
-	// Scaffold the function such that it can be run.
-	if err = r.client.Scaffold(ctx, f, job.Dir()); err != nil {
-		return
+	job.Detached = opts.Detach
+	job.stdout, job.stderr = r.out, r.err
+	if opts.Stdout != nil {
+		job.stdout = opts.Stdout
+	}
+	if opts.Stderr != nil {
+		job.stderr = opts.Stderr
+	}
+	if opts.Debug {
+		if job.DebugPort, err = debugPort(f.Runtime, defaultRunHost, opts.DebugPort); err != nil {
+			return
+		}
 	}
 
 	// Runner for the Function's runtime.
-	if runFn, err = getRunFunc(ctx, job); err != nil {
+	if runFn, err = getRunFunc(runCtx, job); err != nil {
 		return
 	}
 
+	// Scaffold the function such that it can be run.  Every runtime with a
+	// runner has scaffolding, so a function whose signature can not be
+	// detected is an error rather than being started without any.
+	if err = r.client.Scaffold(ctx, f, job.Dir()); err != nil {
+		return nil, fmt.Errorf("cannot scaffold %v function. %w", f.Runtime, err)
+	}
+
 	// Run the scaffolded function asynchronously.
 	if err = runFn(); err != nil {
 		return
 	}
 
 	// Wait for it to become available before returning the metadata.
-	err = waitFor(ctx, job, startTimeout)
+	if err = waitFor(ctx, job, opts.StartTimeout); err != nil && opts.Detach {
+		_ = job.Stop()
+	}
+	return
+}
+
+// serve the function by starting the command asynchronously, recording its
+// process ID on the job.  The command's exit, which is unexpected, is sent
+// on the job's error channel.  Attached jobs write to the job's output.
+// The commands of detached jobs are started in their own session with their
+// output written to the job's log, such that they continue to run after this
+// process exits.
+func serve(job *Job, cmd *exec.Cmd) (err error) {
+	cmd.Stdout = job.stdout
+	cmd.Stderr = job.stderr
+	if job.Detached {
+		var log *os.File
+		if log, err = os.OpenFile(job.Log(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+			return fmt.Errorf("cannot create job log. %w", err)
+		}
+		defer log.Close() // the process retains its own descriptor
+		cmd.Stdout = log
+		cmd.Stderr = log
+		detach(cmd)
+	}
+	if err = cmd.Start(); err != nil {
+		return
+	}
+	job.PID = cmd.Process.Pid
+
+	// Running asynchronously allows for the client Run method to return
+	// metadata about the running function such as its chosen port.
+	go func() {
+		job.Errors <- cmd.Wait()
+	}()
 	return
 }
 
@@ -84,13 +165,13 @@ func getRunFunc(ctx context.Context, job *Job) (runFn func() error, err error) {
 	case "go":
 		runFn = func() error { return runGo(ctx, job) }
 	case "python":
-		err = ErrRunnerNotImplemented{runtime}
+		runFn = func() error { return runPython(ctx, job) }
 	case "java":
 		err = ErrRunnerNotImplemented{runtime}
 	case "node":
-		err = ErrRunnerNotImplemented{runtime}
+		runFn = func() error { return runNode(ctx, job) }
 	case "typescript":
-		err = ErrRunnerNotImplemented{runtime}
+		runFn = func() error { return runNode(ctx, job) }
 	case "rust":
 		err = ErrRunnerNotImplemented{runtime}
 	case "quarkus":
@@ -106,19 +187,22 @@ func runGo(ctx context.Context, job *Job) (err error) {
 	// -----
 	// TODO: extract the build command code from the OCI Container Builder
 	// and have both the runner and OCI Container Builder use the same here.
-	if job.verbose {
-		fmt.Printf("cd %v && go build -o f.bin\n", job.Dir())
-	}
-
 	// Build
+	// Debugged functions are built with optimizations and inlining disabled.
 	args := []string{"build", "-o", "f.bin"}
+	if job.DebugPort != "" {
+		args = append(args, "-gcflags=all=-N -l")
+	}
 	if job.verbose {
 		args = append(args, "-v")
 	}
+	if job.verbose {
+		fmt.Printf("cd %v && go %v\n", job.Dir(), strings.Join(args, " "))
+	}
 	cmd := exec.CommandContext(ctx, "go", args...)
 	cmd.Dir = job.Dir()
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = job.stdout
+	cmd.Stderr = job.stderr
 	err = cmd.Run()
 	if err != nil {
 		return
@@ -131,9 +215,12 @@ func runGo(ctx context.Context, job *Job) (err error) {
 		fmt.Printf("cd %v && PORT=%v %v\n", job.Function.Root, job.Port, bin)
 	}
 	cmd = exec.CommandContext(ctx, bin)
+	if job.DebugPort != "" {
+		if cmd, err = delve(ctx, job, bin); err != nil {
+			return
+		}
+	}
 	cmd.Dir = job.Function.Root
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
 
 	// cmd.Cancel = stop // TODO: use when we upgrade to go 1.20
 	//  TODO: Update the functions go runtime to accept LISTEN_ADDRESS rather
@@ -148,11 +235,341 @@ func runGo(ctx context.Context, job *Job) (err error) {
 	// cmd.Env = append(cmd.Environ(), "PORT="+job.Port) // requires go 1.19
 	cmd.Env = append(cmd.Env, "PORT="+job.Port, "PWD="+cmd.Dir)
 
-	// Running asynchronously allows for the client Run method to return
-	// metadata about the running function such as its chosen port.
-	go func() {
-		job.Errors <- cmd.Run()
-	}()
+	return serve(job, cmd)
+}
+
+// delve returns the command which runs the Go function's binary under a
+// headless Delve, which continues the function such that it serves while
+// accepting debuggers on the job's debug port.  Delve and the function are
+// run in their own process group, which is signaled on cancelation such that
+// they are stopped together.
+func delve(ctx context.Context, job *Job, bin string) (*exec.Cmd, error) {
+	dlv, err := exec.LookPath("dlv")
+	if err != nil {
+		return nil, errors.New("dlv not found.  Delve is required to debug Go functions.  See https://github.com/go-delve/delve")
+	}
+	args := []string{"exec", "--headless", "--listen=" + net.JoinHostPort(job.Host, job.DebugPort),
+		"--api-version=2", "--accept-multiclient", "--continue", bin}
+	if job.verbose {
+		fmt.Printf("dlv %v\n", strings.Join(args, " "))
+	}
+	cmd := exec.CommandContext(ctx, dlv, args...)
+	detach(cmd)
+	cmd.Cancel = func() error { return stopProcess(cmd.Process.Pid) }
+	return cmd, nil
+}
+
+func runPython(ctx context.Context, job *Job) (err error) {
+	// VIRTUALENV
+	// ----------
+	// The function's requirements are installed into a virtual environment
+	// held in the function's runtime data directory such that it is isolated
+	// from (and does not pollute) the function's source, and is reused across
+	// runs until the requirements change.
+	python, err := preparePython(ctx, job)
+	if err != nil {
+		return
+	}
+
+	// Run
+	// ---
+	// Debugged functions are run using debugpy, installed into the virtual
+	// environment if necessary.
+	args := []string{filepath.Join(job.Dir(), "main.py")}
+	if job.DebugPort != "" {
+		if err = installDebugpy(ctx, job, python); err != nil {
+			return
+		}
+		args = append([]string{"-m", "debugpy", "--listen", net.JoinHostPort(job.Host, job.DebugPort)}, args...)
+	}
+	if job.verbose {
+		fmt.Printf("cd %v && PORT=%v %v %v\n", job.Function.Root, job.Port, python, strings.Join(args, " "))
+	}
+	cmd := exec.CommandContext(ctx, python, args...)
+	cmd.Dir = job.Function.Root
+	// Unlike Go binaries, the interpreter requires the user's environment
+	// (HOME, locale etc.) so the current environment is extended.
+	cmd.Env = append(os.Environ(), "PORT="+job.Port, "PWD="+cmd.Dir, "PYTHONUNBUFFERED=1")
+
+	return serve(job, cmd)
+}
+
+// preparePython returns the interpreter of the virtual environment for the
+// function's current requirements, creating it and installing the
+// requirements if necessary.  Virtual environments are held in
+// .func/venv/<hash of requirements.txt>, and those of prior requirements are
+// removed when a new one is created.  Downloaded packages are cached across
+// installations in the function's runtime data directory.
+func preparePython(ctx context.Context, job *Job) (python string, err error) {
+	var (
+		root  = job.Function.Root
+		venvs = filepath.Join(root, RunDataDir, "venv")
+	)
+	requirements, err := os.ReadFile(filepath.Join(root, "requirements.txt"))
+	if err != nil && !os.IsNotExist(err) {
+		return
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256(requirements))
+	venv := filepath.Join(venvs, hash[:12])
+	python = venvPython(venv)
+	if _, err = os.Stat(filepath.Join(venv, ".requirements")); err == nil {
+		return // up-to-date
+	}
+
+	// Create, replacing any prior (possibly incomplete) environments.
+	interpreter, err := findPython()
+	if err != nil {
+		return
+	}
+	if err = os.RemoveAll(venvs); err != nil {
+		return
+	}
+	if job.verbose {
+		fmt.Printf("%v -m venv %v\n", interpreter, venv)
+	}
+	cmd := exec.CommandContext(ctx, interpreter, "-m", "venv", venv)
+	cmd.Stdout = job.stdout
+	cmd.Stderr = job.stderr
+	if err = cmd.Run(); err != nil {
+		return python, fmt.Errorf("cannot create virtual environment. %w", err)
+	}
+	return python, installPythonRequirements(ctx, job, python, venv)
+}
+
+// installPythonRequirements installs the function's requirements.txt into
+// the virtual environment of the given interpreter, marking the environment
+// as complete on success.
+func installPythonRequirements(ctx context.Context, job *Job, python, venv string) (err error) {
+	marker := filepath.Join(venv, ".requirements")
+	if _, err = os.Stat(filepath.Join(job.Function.Root, "requirements.txt")); os.IsNotExist(err) {
+		return os.WriteFile(marker, []byte{}, os.ModePerm) // no requirements
+	} else if err != nil {
+		return
+	}
+
+	cache := filepath.Join(job.Function.Root, RunDataDir, "cache", "pip")
+	if job.verbose {
+		fmt.Printf("cd %v && PIP_CACHE_DIR=%v %v -m pip install -r requirements.txt\n", job.Function.Root, cache, python)
+	}
+	args := []string{"-m", "pip", "install", "--disable-pip-version-check", "-r", "requirements.txt"}
+	if !job.verbose {
+		args = append(args, "--quiet")
+	}
+	cmd := exec.CommandContext(ctx, python, args...)
+	cmd.Dir = job.Function.Root
+	cmd.Stdout = job.stdout
+	cmd.Stderr = job.stderr
+	cmd.Env = append(os.Environ(), "PIP_CACHE_DIR="+cache)
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("cannot install requirements. %w", err)
+	}
+	return os.WriteFile(marker, []byte{}, os.ModePerm)
+}
+
+// installDebugpy installs debugpy into the virtual environment of the given
+// interpreter unless already installed.
+func installDebugpy(ctx context.Context, job *Job, python string) error {
+	if exec.CommandContext(ctx, python, "-c", "import debugpy").Run() == nil {
+		return nil
+	}
+	cache := filepath.Join(job.Function.Root, RunDataDir, "cache", "pip")
+	if job.verbose {
+		fmt.Printf("PIP_CACHE_DIR=%v %v -m pip install debugpy\n", cache, python)
+	}
+	args := []string{"-m", "pip", "install", "--disable-pip-version-check", "debugpy"}
+	if !job.verbose {
+		args = append(args, "--quiet")
+	}
+	cmd := exec.CommandContext(ctx, python, args...)
+	cmd.Stdout = job.stdout
+	cmd.Stderr = job.stderr
+	cmd.Env = append(os.Environ(), "PIP_CACHE_DIR="+cache)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("cannot install debugpy. %w", err)
+	}
+	return nil
+}
+
+// findPython returns the name of the python interpreter on the path,
+// preferring "python3".
+func findPython() (string, error) {
+	for _, name := range []string{"python3", "python"} {
+		if _, err := exec.LookPath(name); err == nil {
+			return name, nil
+		}
+	}
+	return "", errors.New("python interpreter not found.  Python 3 is required to run Python functions on the host")
+}
+
+// venvPython returns the path to the interpreter of the virtual environment.
+func venvPython(venv string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(venv, "Scripts", "python.exe")
+	}
+	return filepath.Join(venv, "bin", "python")
+}
+
+func runNode(ctx context.Context, job *Job) (err error) {
+	// DEPENDENCIES
+	// ------------
+	// Dependencies are installed into the function's runtime data directory
+	// and linked into the job directory, from which a copy of the function's
+	// source is run, such that the function's source is unaltered.
+	modules, err := installNodeDependencies(ctx, job)
+	if err != nil {
+		return
+	}
+	src, err := copyNodeSource(job, modules)
+	if err != nil {
+		return
+	}
+
+	// BUILD
+	// -----
+	// TypeScript is compiled from the copy into the job directory.
+	args := []string{filepath.Join(job.Dir(), "index.js")}
+	if job.DebugPort != "" {
+		args = append([]string{"--inspect=" + net.JoinHostPort(job.Host, job.DebugPort)}, args...)
+	}
+	if job.Function.Runtime == "typescript" {
+		out := filepath.Join(job.Dir(), "build")
+		tsc := filepath.Join(job.Dir(), "node_modules", "typescript", "bin", "tsc")
+		if job.verbose {
+			fmt.Printf("cd %v && node %v --outDir %v\n", src, tsc, out)
+		}
+		cmd := exec.CommandContext(ctx, "node", tsc, "--outDir", out)
+		cmd.Dir = src
+		cmd.Stdout = job.stdout
+		cmd.Stderr = job.stderr
+		if err = cmd.Run(); err != nil {
+			return fmt.Errorf("cannot compile typescript. %w", err)
+		}
+		args = append(args, filepath.Join(out, "index.js"))
+	}
+
+	// Run
+	// ---
+	// The scaffolding loads the function and serves it on the job's port
+	// using the faas-js-runtime.
+	if job.verbose {
+		fmt.Printf("cd %v && node %v\n", job.Function.Root, strings.Join(args, " "))
+	}
+	cmd := exec.CommandContext(ctx, "node", args...)
+	cmd.Dir = job.Function.Root
+	cmd.Env = append(os.Environ(), "PORT="+job.Port, "PWD="+cmd.Dir, "FUNC_LOG_LEVEL=info")
+
+	return serve(job, cmd)
+}
+
+// installNodeDependencies installs the function's dependencies, returning
+// the path of their node_modules.  Dependencies are installed into
+// .func/node/<hash of the package manifests>, and those of prior manifests
+// are removed when new ones are installed.  Installation is skipped if the
+// dependencies of the current manifests are already installed.  Downloaded
+// packages are cached across installations in the function's runtime data
+// directory.
+func installNodeDependencies(ctx context.Context, job *Job) (modules string, err error) {
+	var (
+		root  = job.Function.Root
+		deps  = filepath.Join(root, RunDataDir, "node")
+		cache = filepath.Join(root, RunDataDir, "cache", "npm")
+		hash  = sha256.New()
+		files = map[string][]byte{} // package manifests by name
+	)
+	for _, name := range []string{"package.json", "package-lock.json"} {
+		b, err := os.ReadFile(filepath.Join(root, name))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+		files[name] = b
+		hash.Write(b)
+	}
+	dir := filepath.Join(deps, fmt.Sprintf("%x", hash.Sum(nil))[:12])
+	modules = filepath.Join(dir, "node_modules")
+	marker := filepath.Join(dir, ".installed")
+	if _, err = os.Stat(marker); err == nil {
+		return // up-to-date
+	}
+
+	if _, err = exec.LookPath("npm"); err != nil {
+		return "", errors.New("npm not found.  Node.js and npm are required to run Node.js and TypeScript functions on the host")
+	}
+
+	// Install from copies of the manifests, replacing any prior (possibly
+	// incomplete) installations.
+	if err = os.RemoveAll(deps); err != nil {
+		return
+	}
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return
+	}
+	for name, b := range files {
+		if err = os.WriteFile(filepath.Join(dir, name), b, os.ModePerm); err != nil {
+			return
+		}
+	}
+
+	// Prefer a clean install from the lockfile when available.
+	args := []string{"install"}
+	if _, ok := files["package-lock.json"]; ok {
+		args = []string{"ci"}
+	}
+	args = append(args, "--cache", cache, "--prefer-offline", "--no-audit", "--no-fund")
+	if !job.verbose {
+		args = append(args, "--silent")
+	}
+	if job.verbose {
+		fmt.Printf("cd %v && npm %v\n", dir, strings.Join(args, " "))
+	}
+	cmd := exec.CommandContext(ctx, "npm", args...)
+	cmd.Dir = dir
+	cmd.Stdout = job.stdout
+	cmd.Stderr = job.stderr
+	if err = cmd.Run(); err != nil {
+		return "", fmt.Errorf("cannot install dependencies. %w", err)
+	}
+	return modules, os.WriteFile(marker, []byte{}, os.ModePerm)
+}
+
+// copyNodeSource copies the function's source, less that which is ignored
+// and any node_modules of its own, into the job directory, returning the
+// path of the copy.  The scaffolding is linked to the copy, and the given
+// dependencies are linked into the job directory, from which they are
+// resolved by both the copy and its compiled output.
+func copyNodeSource(job *Job, modules string) (src string, err error) {
+	src = filepath.Join(job.Dir(), "src")
+	if err = os.RemoveAll(src); err != nil {
+		return
+	}
+	fsys, err := funcignore.FS(job.Function.Root)
+	if err != nil {
+		return
+	}
+	fsys = filesystem.NewMaskingFS(func(name string) bool { return name == "node_modules" }, fsys)
+	if job.verbose {
+		fmt.Printf("cp -r %v %v\n", job.Function.Root, src)
+	}
+	if err = filesystem.CopyFromFS(".", src, fsys); err != nil {
+		return "", fmt.Errorf("cannot copy function source. %w", err)
+	}
+	links := map[string]string{
+		filepath.Join(job.Dir(), "f"):            src,
+		filepath.Join(job.Dir(), "node_modules"): modules,
+	}
+	for link, target := range links {
+		rel, err := filepath.Rel(job.Dir(), target)
+		if err != nil {
+			return "", err
+		}
+		if err = os.Remove(link); err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		if err = os.Symlink(rel, link); err != nil {
+			return "", err
+		}
+	}
 	return
 }
 
@@ -221,6 +638,22 @@ func isReady(ctx context.Context, uri string, timeout time.Duration, verbose boo
 	return true, nil
 }
 
+// waitForRelease waits until the port on the given interface is no longer
+// in use, or the timeout is reached.
+func waitForRelease(iface, port string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		l, err := net.Listen("tcp", net.JoinHostPort(iface, port))
+		if err == nil {
+			return l.Close()
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for port %v to be released", port)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
 // choosePort returns an unused port on the given interface (host)
 // Note this is not fool-proof becase of a race with any other processes
 // looking for a port at the same time.  If that is important, we can implement
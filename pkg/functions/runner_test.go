@@ -5,8 +5,19 @@ package functions
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"knative.dev/func/pkg/scaffolding"
+	. "knative.dev/func/pkg/testing"
 )
 
 // TestGetRunFuncErrors ensures that known runtimes which do not yet
@@ -20,10 +31,10 @@ func TestGetRunFuncErrors(t *testing.T) {
 	}{
 		{"", ErrRuntimeRequired, nil},
 		{"go", nil, nil},
-		{"python", nil, &ErrRunnerNotImplemented{}},
+		{"python", nil, nil},
 		{"rust", nil, &ErrRunnerNotImplemented{}},
-		{"node", nil, &ErrRunnerNotImplemented{}},
-		{"typescript", nil, &ErrRunnerNotImplemented{}},
+		{"node", nil, nil},
+		{"typescript", nil, nil},
 		{"quarkus", nil, &ErrRunnerNotImplemented{}},
 		{"java", nil, &ErrRunnerNotImplemented{}},
 		{"other", nil, &ErrRuntimeNotRecognized{}},
@@ -42,3 +53,209 @@ func TestGetRunFuncErrors(t *testing.T) {
 		})
 	}
 }
+
+// TestRun_SignatureNotFound ensures that a function whose signature can not
+// be detected is an error naming its runtime, rather than being started
+// without scaffolding which would serve it.
+func TestRun_SignatureNotFound(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	client := New()
+	f, err := client.Init(Function{Root: root, Runtime: "python"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, "func.py"), []byte("# no function\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	_, err = newDefaultRunner(client, io.Discard, io.Discard).Run(context.Background(), f, RunOptions{})
+	if !errors.Is(err, scaffolding.ErrSignatureNotFound) {
+		t.Fatalf("expected ErrSignatureNotFound, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "python") {
+		t.Fatalf("expected the error to name the runtime, got %v", err)
+	}
+}
+
+// TestPreparePython_Cached ensures that the virtual environment into which
+// a function's requirements are installed outlives its jobs, such that a
+// subsequent run does not reinstall unchanged requirements, and that changed
+// requirements are installed into a new environment.
+func TestPreparePython_Cached(t *testing.T) {
+	if _, err := findPython(); err != nil {
+		t.Skip(err)
+	}
+	root, rm := Mktemp(t)
+	defer rm()
+	ctx := context.Background()
+
+	f, err := New().Init(Function{Root: root, Runtime: "python"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, "requirements.txt"), []byte("# none\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	prepare := func() (venv string) {
+		t.Helper()
+		job, err := NewJob(f, "127.0.0.1", "8080", nil, nil, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		python, err := preparePython(ctx, job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = job.Stop(); err != nil {
+			t.Fatal(err)
+		}
+		return filepath.Dir(filepath.Dir(python))
+	}
+
+	// First run creates the environment and installs the requirements.
+	venv := prepare()
+	marker := filepath.Join(venv, ".requirements")
+	past := time.Now().Add(-time.Hour)
+	if err = os.Chtimes(marker, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	// Second run, after the first job was stopped, reuses it without
+	// reinstalling (which would rewrite the marker).
+	if again := prepare(); again != venv {
+		t.Fatalf("expected environment %v to be reused, got %v", venv, again)
+	}
+	if fi, err := os.Stat(marker); err != nil || !fi.ModTime().Equal(past) {
+		t.Fatalf("expected requirements not to be reinstalled. %v", err)
+	}
+
+	// Changed requirements are installed into a new environment, replacing
+	// the prior.
+	if err = os.WriteFile(filepath.Join(root, "requirements.txt"), []byte("# changed\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	changed := prepare()
+	if changed == venv {
+		t.Fatal("expected a new environment for changed requirements")
+	}
+	if _, err = os.Stat(venv); !os.IsNotExist(err) {
+		t.Fatalf("expected prior environment to be removed. %v", err)
+	}
+}
+
+// TestInstallNodeDependencies_Cached ensures that dependencies are
+// installed into the function's runtime data directory, and are not
+// reinstalled when the package manifests are unchanged since the last
+// installation.
+func TestInstallNodeDependencies_Cached(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	manifest := []byte(`{"name":"f","dependencies":{"faas-js-runtime":"^2.4.0"}}`)
+	if err := os.WriteFile(filepath.Join(root, "package.json"), manifest, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256(manifest))
+	dir := filepath.Join(root, RunDataDir, "node", hash[:12])
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".installed"), []byte{}, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	// With an empty PATH, npm would not be found were an install attempted.
+	t.Setenv("PATH", "")
+	job := &Job{Function: Function{Root: root, Runtime: "node"}, Port: "8080"}
+	modules, err := installNodeDependencies(context.Background(), job)
+	if err != nil {
+		t.Fatalf("unexpected error with installed dependencies. %v", err)
+	}
+	if modules != filepath.Join(dir, "node_modules") {
+		t.Fatalf("unexpected node_modules %v", modules)
+	}
+
+	// Changed manifests require an install.
+	if err := os.WriteFile(filepath.Join(root, "package.json"), []byte(`{"name":"f"}`), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := installNodeDependencies(context.Background(), job); err == nil {
+		t.Fatal("expected changed dependencies to be installed")
+	}
+}
+
+// TestCopyNodeSource ensures that Node.js functions are run from a copy of
+// their source, less their own node_modules, to which the scaffolding and
+// the installed dependencies are linked, such that the source is unaltered.
+func TestCopyNodeSource(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	for _, name := range []string{"index.js", "node_modules/dep/index.js", "lib/util.js"} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte{}, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	modules := filepath.Join(root, RunDataDir, "node", "0123456789ab", "node_modules")
+	if err := os.MkdirAll(modules, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	job := &Job{Function: Function{Root: root, Runtime: "node"}, Port: "8080"}
+	if err := os.MkdirAll(job.Dir(), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := copyNodeSource(job, modules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"index.js", "lib/util.js"} {
+		if _, err = os.Stat(filepath.Join(src, filepath.FromSlash(name))); err != nil {
+			t.Errorf("expected %v to be copied. %v", name, err)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(src, "node_modules")); !os.IsNotExist(err) {
+		t.Errorf("expected the function's own node_modules to not be copied. %v", err)
+	}
+	links := map[string]string{"f": src, "node_modules": modules}
+	for link, target := range links {
+		resolved, err := filepath.EvalSymlinks(filepath.Join(job.Dir(), link))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, _ := filepath.EvalSymlinks(target); resolved != expected {
+			t.Errorf("expected %v to link to %v, got %v", link, expected, resolved)
+		}
+	}
+}
+
+// TestDebugPort ensures the debug port defaults to that of the runtime, is an
+// error for runtimes which can not be debugged, and is an error rather than
+// an alternative when in use.
+func TestDebugPort(t *testing.T) {
+	if _, err := debugPort("rust", "127.0.0.1", ""); !errors.As(err, &ErrDebugNotSupported{}) {
+		t.Fatalf("expected ErrDebugNotSupported, got %v", err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	_, inUse, _ := net.SplitHostPort(l.Addr().String())
+	if _, err = debugPort("go", "127.0.0.1", inUse); err == nil {
+		t.Fatal("expected an error for a debug port in use")
+	}
+	l.Close()
+	if port, err := debugPort("go", "127.0.0.1", inUse); err != nil || port != inUse {
+		t.Fatalf("expected port %v, got %q (%v)", inUse, port, err)
+	}
+	if DefaultDebugPort("go") != "2345" || DefaultDebugPort("python") != "5678" {
+		t.Fatal("unexpected default debug ports")
+	}
+}
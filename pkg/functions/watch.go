@@ -0,0 +1,156 @@
+package functions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"knative.dev/func/pkg/funcignore"
+)
+
+// DefaultWatchDebounce is the time to wait after a change to a function's
+// source for further changes before notifying.
+const DefaultWatchDebounce = 500 * time.Millisecond
+
+// watchIgnored are patterns of files and directories, matched against each
+// element of a path, which are never watched in addition to those ignored by
+// the function's .funcignore files.  These are written by the runners
+// themselves when running the function: dependencies installed into
+// node_modules (with the lockfile updated by the install), and bytecode
+// compiled by Python.
+// Changes to dependencies are instead reflected in the function's package
+// manifests (package.json or requirements.txt).
+var watchIgnored = []string{"node_modules", "package-lock.json", "__pycache__", "*.pyc"}
+
+// Watch the source of the function at root for changes.  Each set of
+// changes, after no further changes have occurred for the debounce period,
+// is sent on the returned channel as the paths (relative to root) which
+// changed.  Files ignored by the function's .funcignore files (see
+// funcignore) are not watched.
+// The channel is closed when the context is canceled.
+func Watch(ctx context.Context, root string, debounce time.Duration) (<-chan []string, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("cannot create watcher. %w", err)
+	}
+	ignore, err := newWatchIgnore(root)
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err = watchDirs(w, root, root, ignore); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	changes := make(chan []string)
+	go func() {
+		defer close(changes)
+		defer w.Close()
+		var (
+			pending = map[string]bool{}
+			timer   = time.NewTimer(debounce)
+			fire    <-chan time.Time
+		)
+		timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case e, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				rel, err := filepath.Rel(root, e.Name)
+				if err != nil {
+					continue
+				}
+				if name := filepath.Base(rel); name == funcignore.File || name == funcignore.Fallback {
+					if i, err := newWatchIgnore(root); err != nil {
+						fmt.Fprintf(os.Stderr, "warning: unable to read %v. %v\n", rel, err)
+					} else {
+						ignore = i
+					}
+				}
+				if ignore(rel) {
+					continue
+				}
+				// New directories are watched as well.
+				if e.Has(fsnotify.Create) {
+					if fi, err := os.Stat(e.Name); err == nil && fi.IsDir() {
+						if err = watchDirs(w, root, e.Name, ignore); err != nil {
+							fmt.Fprintf(os.Stderr, "warning: unable to watch %v. %v\n", rel, err)
+						}
+					}
+				}
+				pending[rel] = true
+				timer.Reset(debounce)
+				fire = timer.C
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				fmt.Fprintf(os.Stderr, "warning: error watching function source. %v\n", err)
+			case <-fire:
+				fire = nil
+				paths := make([]string, 0, len(pending))
+				for p := range pending {
+					paths = append(paths, p)
+				}
+				sort.Strings(paths)
+				pending = map[string]bool{}
+				select {
+				case changes <- paths:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// watchDirs adds the directory dir within the function's root, and all of
+// its subdirectories which are not ignored, to the watcher.
+func watchDirs(w *fsnotify.Watcher, root, dir string, ignore func(string) bool) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if rel, _ := filepath.Rel(root, path); path != root && ignore(rel) {
+			return filepath.SkipDir
+		}
+		return w.Add(path)
+	})
+}
+
+// newWatchIgnore returns a function which returns true for paths (relative
+// to root) which are not watched: those matching or within the always-ignored
+// patterns, or ignored by the function's .funcignore files.
+func newWatchIgnore(root string) (func(string) bool, error) {
+	m, err := funcignore.Load(root)
+	if err != nil {
+		return nil, err
+	}
+	return func(path string) bool {
+		for _, name := range strings.Split(filepath.ToSlash(path), "/") {
+			for _, pattern := range watchIgnored {
+				if ok, _ := filepath.Match(pattern, name); ok {
+					return true
+				}
+			}
+		}
+		fi, err := os.Lstat(filepath.Join(root, path))
+		return m.Ignored(path, err == nil && fi.IsDir())
+	}, nil
+}
@@ -0,0 +1,85 @@
+//go:build !integration
+// +build !integration
+
+package functions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestWatch ensures that changes to a function's source are sent as a
+// single debounced set of paths, excluding those ignored by .funcignore and
+// those always ignored, such as those written by the runners.
+func TestWatch(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	if err := os.WriteFile(".funcignore", []byte("*.log\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(RunDataDir, "runs"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := Watch(ctx, root, 100*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ignored changes
+	if err = os.WriteFile("debug.log", []byte("ignored"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(RunDataDir, "runs", "8080"), []byte("ignored"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writes by the runners themselves, such as Python bytecode written
+	// through the scaffolding's link to the function, are ignored.
+	if err = os.MkdirAll("__pycache__", os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join("__pycache__", "func.cpython-311.pyc"), []byte("ignored"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile("package-lock.json", []byte("{}"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes, including within a new directory, are debounced into a
+	// single notification.
+	if err = os.WriteFile("handle.go", []byte("package f"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Mkdir("lib", os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond) // allow the new directory to be watched
+	if err = os.WriteFile(filepath.Join("lib", "lib.go"), []byte("package lib"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case paths := <-changes:
+		expected := []string{"handle.go", "lib", filepath.Join("lib", "lib.go")}
+		if !reflect.DeepEqual(paths, expected) {
+			t.Fatalf("expected changes %v, got %v", expected, paths)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for changes")
+	}
+
+	// The channel is closed on cancel.
+	cancel()
+	for range changes {
+	}
+}
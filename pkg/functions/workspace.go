@@ -0,0 +1,183 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// WorkspaceFile is the name of the file, at the root of a repository, which
+// lists the functions of a workspace.
+const WorkspaceFile = "func-workspace.yaml"
+
+// Workspace is a set of functions within a repository, such as the functions
+// of a monorepo which call one another, which are run together locally.
+//
+// A workspace is defined by a workspace file at its root which lists the
+// paths of the functions' roots relative to it, and optionally the functions
+// on which each depends (by path), which are deployed before it.  For
+// example:
+//
+//	functions:
+//	  - path: orders
+//	    dependsOn: [services/payments]
+//	  - path: services/payments
+type Workspace struct {
+	// Root of the workspace: the directory of its workspace file.
+	Root string `yaml:"-"`
+
+	// Functions of the workspace.
+	Functions []WorkspaceFunction `yaml:"functions"`
+}
+
+// WorkspaceFunction is a function of a workspace.
+type WorkspaceFunction struct {
+	// Path of the function's root, relative to the workspace root.
+	Path string `yaml:"path"`
+
+	// DependsOn are the paths of the functions of the workspace on which
+	// the function depends.
+	DependsOn []string `yaml:"dependsOn,omitempty"`
+}
+
+// LoadWorkspace loads the workspace whose workspace file is in root.
+func LoadWorkspace(root string) (w Workspace, err error) {
+	if root, err = filepath.Abs(root); err != nil {
+		return
+	}
+	bb, err := os.ReadFile(filepath.Join(root, WorkspaceFile))
+	if os.IsNotExist(err) {
+		return w, fmt.Errorf("no workspace file %v found in %v", WorkspaceFile, root)
+	} else if err != nil {
+		return
+	}
+	if err = yaml.UnmarshalStrict(bb, &w); err != nil {
+		return w, fmt.Errorf("workspace file %v is not valid. %w", filepath.Join(root, WorkspaceFile), err)
+	}
+	w.Root = root
+	return w, w.Validate()
+}
+
+// Validate the workspace, whose functions must each be listed once by a
+// path within the workspace, and whose dependencies must be functions of the
+// workspace and not be circular.
+func (w Workspace) Validate() error {
+	if len(w.Functions) == 0 {
+		return errors.New("the workspace lists no functions")
+	}
+	paths := map[string]bool{}
+	for _, wf := range w.Functions {
+		if wf.Path == "" {
+			return errors.New("a function of the workspace has no path")
+		}
+		p := filepath.Clean(filepath.FromSlash(wf.Path))
+		if filepath.IsAbs(p) || p == ".." || strings.HasPrefix(p, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("the path %q of a function of the workspace is not within the workspace", wf.Path)
+		}
+		if paths[p] {
+			return fmt.Errorf("the function %q is listed more than once", wf.Path)
+		}
+		paths[p] = true
+	}
+	for _, wf := range w.Functions {
+		for _, d := range wf.DependsOn {
+			if !paths[filepath.Clean(filepath.FromSlash(d))] {
+				return fmt.Errorf("the function %q depends on %q, which is not a function of the workspace", wf.Path, d)
+			}
+		}
+	}
+	return checkDependencies(w.Dependencies())
+}
+
+// Dependencies of the functions of the workspace, as the roots of the
+// functions on which each depends by the root of each (see Batch).
+func (w Workspace) Dependencies() map[string][]string {
+	root := func(path string) string {
+		return filepath.Join(w.Root, filepath.Clean(filepath.FromSlash(path)))
+	}
+	deps := make(map[string][]string, len(w.Functions))
+	for _, wf := range w.Functions {
+		for _, d := range wf.DependsOn {
+			deps[root(wf.Path)] = append(deps[root(wf.Path)], root(d))
+		}
+	}
+	return deps
+}
+
+// Load the functions of the workspace, in the order listed.  Each must be
+// initialized and have a name unique within the workspace.
+func (w Workspace) Load() ([]Function, error) {
+	var (
+		ff    = make([]Function, 0, len(w.Functions))
+		names = map[string]string{}
+	)
+	for _, wf := range w.Functions {
+		f, err := NewFunction(filepath.Join(w.Root, filepath.FromSlash(wf.Path)))
+		if err != nil {
+			return ff, err
+		}
+		if !f.Initialized() {
+			return ff, NewErrNotInitialized(f.Root)
+		}
+		if other, ok := names[f.Name]; ok {
+			return ff, fmt.Errorf("the functions %q and %q of the workspace are both named %q", other, wf.Path, f.Name)
+		}
+		names[f.Name] = wf.Path
+		ff = append(ff, f)
+	}
+	return ff, nil
+}
+
+// WorkspaceURLEnv returns the name of the environment variable with which the
+// functions of a workspace are provided the URL of the named sibling: the
+// name in upper case, with characters not valid in an environment variable
+// name replaced with an underscore, suffixed with _URL.  For example
+// "order-service" is ORDER_SERVICE_URL.
+func WorkspaceURLEnv(name string) string {
+	env := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	return env + "_URL"
+}
+
+// WithSiblingURLs returns the function with an environment variable for the
+// URL of each of its siblings in a workspace (see WorkspaceURLEnv), given by
+// name.  Environment variables defined by the function itself take
+// precedence.
+func WithSiblingURLs(f Function, urls map[string]string) Function {
+	defined := map[string]bool{}
+	for _, e := range f.Run.Envs {
+		if e.Name != nil {
+			defined[*e.Name] = true
+		}
+	}
+	envs := append([]Env{}, f.Run.Envs...)
+	for _, name := range sortedURLNames(urls) {
+		env, url := WorkspaceURLEnv(name), urls[name]
+		if name == f.Name || defined[env] {
+			continue
+		}
+		envs = append(envs, Env{Name: &env, Value: &url})
+	}
+	f.Run.Envs = envs
+	return f
+}
+
+func sortedURLNames(urls map[string]string) []string {
+	names := make(map[string]bool, len(urls))
+	for name := range urls {
+		names[name] = true
+	}
+	return sortedKeys(names)
+}
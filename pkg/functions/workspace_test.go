@@ -0,0 +1,93 @@
+//go:build !integration
+// +build !integration
+
+package functions
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestLoadWorkspace ensures that a workspace is loaded from its workspace
+// file, and that its functions must be listed once each by a path within the
+// workspace, and have unique names.
+func TestLoadWorkspace(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"orders", "payments"} {
+		if _, err := New().Init(Function{Root: filepath.Join(root, name), Name: name, Runtime: "go"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := LoadWorkspace(root); err == nil {
+		t.Fatal("expected an error loading a workspace with no workspace file")
+	}
+
+	write := func(s string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, WorkspaceFile), []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("functions:\n  - path: orders\n  - path: payments\n")
+	w, err := LoadWorkspace(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ff, err := w.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ff) != 2 || ff[0].Name != "orders" || ff[1].Name != "payments" {
+		t.Fatalf("unexpected functions %v", ff)
+	}
+
+	for _, invalid := range []string{
+		"functions: []\n",
+		"functions:\n  - path: ../orders\n",
+		"functions:\n  - path: orders\n  - path: ./orders\n",
+		"functions:\n  - dir: orders\n",
+	} {
+		write(invalid)
+		if _, err = LoadWorkspace(root); err == nil {
+			t.Errorf("expected an error loading workspace file %q", invalid)
+		}
+	}
+
+	// Functions must be initialized
+	write("functions:\n  - path: orders\n  - path: missing\n")
+	if w, err = LoadWorkspace(root); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Load(); err == nil {
+		t.Fatal("expected an error loading an uninitialized function")
+	}
+}
+
+// TestWithSiblingURLs ensures a function is provided the URLs of its
+// siblings but not of itself, and that its own envs take precedence.
+func TestWithSiblingURLs(t *testing.T) {
+	name, value := "PAYMENTS_URL", "http://example.com"
+	f := Function{Name: "orders"}
+	f.Run.Envs = []Env{{Name: &name, Value: &value}}
+
+	f = WithSiblingURLs(f, map[string]string{
+		"orders":      "http://127.0.0.1:8080",
+		"payments":    "http://127.0.0.1:8081",
+		"stock.level": "http://127.0.0.1:8082",
+	})
+	envs, err := Interpolate(f.Run.Envs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"PAYMENTS_URL":    "http://example.com",
+		"STOCK_LEVEL_URL": "http://127.0.0.1:8082",
+	}
+	if !reflect.DeepEqual(envs, expected) {
+		t.Fatalf("expected envs %v, got %v", expected, envs)
+	}
+}
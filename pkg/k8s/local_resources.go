@@ -0,0 +1,57 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// FetchLocalResources copies the Secrets and ConfigMaps referenced by the
+// function's envs and volumes from the cluster into its local resources, such
+// that it can be run locally with them.  The cluster is only read.
+func FetchLocalResources(ctx context.Context, f fn.Function, namespaceOverride string) error {
+	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	if err != nil {
+		return err
+	}
+	resources := fn.NewLocalResources(f)
+	secrets, configMaps := fn.ResourceReferences(f)
+
+	for _, name := range secrets {
+		secret, err := client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("cannot fetch Secret %q from namespace %q. %w", name, namespace, err)
+		}
+		data := make(map[string][]byte, len(secret.Data)+len(secret.StringData))
+		for k, v := range secret.Data {
+			data[k] = v
+		}
+		for k, v := range secret.StringData {
+			data[k] = []byte(v)
+		}
+		if err = resources.WriteSecret(name, data); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range configMaps {
+		cm, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("cannot fetch ConfigMap %q from namespace %q. %w", name, namespace, err)
+		}
+		data := make(map[string]string, len(cm.Data)+len(cm.BinaryData))
+		for k, v := range cm.BinaryData {
+			data[k] = string(v)
+		}
+		for k, v := range cm.Data {
+			data[k] = v
+		}
+		if err = resources.WriteConfigMap(name, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
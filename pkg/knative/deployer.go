@@ -240,6 +240,7 @@ func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResu
 				}
 				return fn.DeploymentResult{}, err
 			}
+			reportRevisionReady(ctx, client, f, namespace)
 
 			route, err := client.GetRoute(ctx, f.Name)
 			if err != nil {
@@ -304,6 +305,7 @@ func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResu
 			}
 			return fn.DeploymentResult{}, err
 		}
+		reportRevisionReady(ctx, client, f, namespace)
 
 		route, err := client.GetRoute(ctx, f.Name)
 		if err != nil {
@@ -324,6 +326,17 @@ func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResu
 	}
 }
 
+// reportRevisionReady reports the latest revision of the function's Service,
+// which it has been waited upon to become ready.
+func reportRevisionReady(ctx context.Context, client clientservingv1.KnServingClient, f fn.Function, namespace string) {
+	service, err := client.GetService(ctx, f.Name)
+	if err != nil {
+		return // reporting is best-effort
+	}
+	fn.ReportProgress(ctx, fn.ProgressEvent{Type: fn.ProgressRevisionReady,
+		Function: f.Name, Namespace: namespace, Revision: service.Status.LatestReadyRevisionName})
+}
+
 func createTriggers(ctx context.Context, f fn.Function, client clientservingv1.KnServingClient, eventingClient clienteventingv1.KnEventingClient) error {
 	ksvc, err := client.GetService(ctx, f.Name)
 	if err != nil {
@@ -333,6 +346,21 @@ func createTriggers(ctx context.Context, f fn.Function, client clientservingv1.K
 
 	fmt.Fprintf(os.Stderr, "🎯 Creating Triggers on the cluster\n")
 
+	for _, trigger := range generateTriggers(f, ksvc) {
+		err = eventingClient.CreateTrigger(ctx, trigger)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			err = fmt.Errorf("knative deployer failed to create the Trigger: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// generateTriggers creates a Trigger for each of the function's subscriptions
+// which delivers events to the given Knative Service.  The Triggers are owned
+// by the Service if it exists on the cluster (has a UID).
+func generateTriggers(f fn.Function, ksvc *v1.Service) []*eventingv1.Trigger {
+	triggers := make([]*eventingv1.Trigger, 0, len(f.Deploy.Subscriptions))
 	for i, sub := range f.Deploy.Subscriptions {
 		// create the filter:
 		attributes := make(map[string]string)
@@ -340,17 +368,10 @@ func createTriggers(ctx context.Context, f fn.Function, client clientservingv1.K
 			attributes[key] = value
 		}
 
-		err = eventingClient.CreateTrigger(ctx, &eventingv1.Trigger{
+		trigger := &eventingv1.Trigger{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: fmt.Sprintf("%s-function-trigger-%d", ksvc.Name, i),
-				OwnerReferences: []metav1.OwnerReference{
-					{
-						APIVersion: ksvc.APIVersion,
-						Kind:       ksvc.Kind,
-						Name:       ksvc.GetName(),
-						UID:        ksvc.GetUID(),
-					},
-				},
+				Name:      fmt.Sprintf("%s-function-trigger-%d", ksvc.Name, i),
+				Namespace: ksvc.Namespace,
 			},
 			Spec: eventingv1.TriggerSpec{
 				Broker: sub.Source,
@@ -366,13 +387,20 @@ func createTriggers(ctx context.Context, f fn.Function, client clientservingv1.K
 					Attributes: attributes,
 				},
 			},
-		})
-		if err != nil && !errors.IsAlreadyExists(err) {
-			err = fmt.Errorf("knative deployer failed to create the Trigger: %v", err)
-			return err
 		}
+		if ksvc.GetUID() != "" {
+			trigger.OwnerReferences = []metav1.OwnerReference{
+				{
+					APIVersion: ksvc.APIVersion,
+					Kind:       ksvc.Kind,
+					Name:       ksvc.GetName(),
+					UID:        ksvc.GetUID(),
+				},
+			}
+		}
+		triggers = append(triggers, trigger)
 	}
-	return nil
+	return triggers
 }
 
 func probeFor(url string) *corev1.Probe {
@@ -611,6 +639,7 @@ func updateService(f fn.Function, previousService *v1.Service, newEnv []corev1.E
 		cp.VolumeMounts = newVolumeMounts
 		service.Spec.ConfigurationSpec.Template.Spec.Volumes = newVolumes
 		service.Spec.ConfigurationSpec.Template.Spec.PodSpec.ServiceAccountName = f.Deploy.ServiceAccountName
+		service.Spec.Traffic = deployTraffic(f, previousService)
 		return service, nil
 	}
 }
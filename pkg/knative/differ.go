@@ -0,0 +1,318 @@
+package knative
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// systemAnnotationPrefixes are the prefixes of annotations set by the
+// cluster or its clients rather than the function, which are not diffed.
+var systemAnnotationPrefixes = []string{
+	"serving.knative.dev/",
+	"client.knative.dev/",
+	"kubectl.kubernetes.io/",
+	fn.HistoryAnnotation,
+}
+
+type DifferOpt func(*Differ)
+
+type Differ struct {
+	verbose   bool
+	decorator DeployDecorator
+}
+
+func NewDiffer(opts ...DifferOpt) *Differ {
+	d := &Differ{}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+func WithDifferVerbose(verbose bool) DifferOpt {
+	return func(d *Differ) {
+		d.verbose = verbose
+	}
+}
+
+// WithDifferDecorator sets the decorator with which the Service is generated,
+// which should be that of the Deployer.
+func WithDifferDecorator(decorator DeployDecorator) DifferOpt {
+	return func(d *Differ) {
+		d.decorator = decorator
+	}
+}
+
+// Diff the function with the Knative Service of its deployed instance, and
+// the Triggers of its subscriptions.  The Service with which it is compared
+// is generated as the Deployer would update the live Service, such that
+// only the fields defined by the function are compared: its image, envs,
+// volumes, labels, annotations, scale and resource options, service account
+// and subscriptions.
+func (d *Differ) Diff(ctx context.Context, f fn.Function) ([]fn.Difference, error) {
+	namespace := f.Deploy.Namespace
+	client, err := NewServingClient(namespace)
+	if err != nil {
+		return nil, err
+	}
+	eventingClient, err := NewEventingClient(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := client.GetService(ctx, f.Name)
+	if err != nil {
+		return nil, fmt.Errorf("knative differ failed to get the Knative Service: %v", err)
+	}
+	desired, err := desiredService(f, live, d.decorator)
+	if err != nil {
+		return nil, fmt.Errorf("knative differ failed to generate the Knative Service: %v", err)
+	}
+
+	var liveTriggers []*eventingv1.Trigger
+	triggers, err := eventingClient.ListTriggers(ctx)
+	// IsNotFound -- Eventing is probably not installed on the cluster
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("knative differ failed to list the Triggers: %v", err)
+	} else if err == nil {
+		for i := range triggers.Items {
+			t := &triggers.Items[i]
+			if ref := t.Spec.Subscriber.Ref; ref != nil && ref.Kind == "Service" && ref.Name == live.Name {
+				liveTriggers = append(liveTriggers, t)
+			}
+		}
+	}
+
+	return append(diffServices(desired, live), diffTriggers(generateTriggers(f, live), liveTriggers)...), nil
+}
+
+// desiredService returns the Service as it would be were the function
+// deployed, by updating the live Service as does the Deployer.
+func desiredService(f fn.Function, live *v1.Service, decorator DeployDecorator) (*v1.Service, error) {
+	referencedSecrets := sets.New[string]()
+	referencedConfigMaps := sets.New[string]()
+	referencedPVCs := sets.New[string]()
+
+	newEnv, newEnvFrom, err := processEnvs(f.Run.Envs, &referencedSecrets, &referencedConfigMaps)
+	if err != nil {
+		return nil, err
+	}
+	newVolumes, newVolumeMounts, err := processVolumes(f.Run.Volumes, &referencedSecrets, &referencedConfigMaps, &referencedPVCs)
+	if err != nil {
+		return nil, err
+	}
+	return updateService(f, live, newEnv, newEnvFrom, newVolumes, newVolumeMounts, decorator)(live.DeepCopy())
+}
+
+// differences accumulates the differences between fields.
+type differences []fn.Difference
+
+// value adds a difference if the desired and live values differ.
+func (dd *differences) value(field, desired, live string) {
+	if desired != live {
+		*dd = append(*dd, fn.Difference{Field: field, Desired: desired, Live: live})
+	}
+}
+
+// values adds a difference for each key whose desired and live values
+// differ, a value being empty where the key is absent.
+func (dd *differences) values(field string, desired, live map[string]string) {
+	keys := sets.KeySet(desired).Union(sets.KeySet(live))
+	for _, k := range sets.List(keys) {
+		dd.value(field+" "+k, desired[k], live[k])
+	}
+}
+
+// diffServices returns the differences between the fields of the desired
+// and live Services which are defined by a function.
+func diffServices(desired, live *v1.Service) []fn.Difference {
+	var (
+		dd     differences
+		ds     = desired.Spec.Template.Spec
+		ls     = live.Spec.Template.Spec
+		dc, lc corev1.Container
+	)
+	if len(ds.Containers) > 0 {
+		dc = ds.Containers[0]
+	}
+	if len(ls.Containers) > 0 {
+		lc = ls.Containers[0]
+	}
+
+	dd.value("image", dc.Image, lc.Image)
+	dd.values("env", envValues(dc.Env), envValues(lc.Env))
+	dd.values("envFrom", envFromValues(dc.EnvFrom), envFromValues(lc.EnvFrom))
+	dd.values("volume", volumeValues(ds.Volumes, dc.VolumeMounts), volumeValues(ls.Volumes, lc.VolumeMounts))
+	dd.values("label", desired.Labels, live.Labels)
+	dd.values("annotation", functionAnnotations(desired.Annotations), functionAnnotations(live.Annotations))
+
+	// The revision's annotations are those of the Service, plus those of the
+	// scale options.
+	dScale, dOther := revisionAnnotations(desired.Spec.Template.Annotations)
+	lScale, lOther := revisionAnnotations(live.Spec.Template.Annotations)
+	dd.values("scale", dScale, lScale)
+	dd.values("revision annotation", dOther, lOther)
+
+	dd.values("resources", resourceValues(dc.Resources), resourceValues(lc.Resources))
+	dd.value("concurrency", concurrencyValue(ds.ContainerConcurrency), concurrencyValue(ls.ContainerConcurrency))
+	dd.value("serviceAccountName", ds.ServiceAccountName, ls.ServiceAccountName)
+	return dd
+}
+
+// diffTriggers returns the subscriptions of the desired Triggers which are
+// not live, and of the live Triggers which are not desired.
+func diffTriggers(desired, live []*eventingv1.Trigger) []fn.Difference {
+	subscriptions := func(tt []*eventingv1.Trigger) map[string]string {
+		ss := make(map[string]string, len(tt))
+		for _, t := range tt {
+			s := t.Spec.Broker
+			if t.Spec.Filter != nil {
+				for _, k := range sets.List(sets.KeySet(t.Spec.Filter.Attributes)) {
+					s += fmt.Sprintf(" %v=%v", k, t.Spec.Filter.Attributes[k])
+				}
+			}
+			ss[s] = s
+		}
+		return ss
+	}
+	var dd differences
+	dd.values("subscription", subscriptions(desired), subscriptions(live))
+	return dd
+}
+
+// envValues returns the values of the environment variables by name,
+// excluding BUILT, which differs upon each deployment.
+func envValues(ee []corev1.EnvVar) map[string]string {
+	vv := make(map[string]string, len(ee))
+	for _, e := range ee {
+		if e.Name == "BUILT" {
+			continue
+		}
+		switch {
+		case e.ValueFrom == nil:
+			vv[e.Name] = e.Value
+		case e.ValueFrom.SecretKeyRef != nil:
+			vv[e.Name] = fmt.Sprintf("secret:%v:%v", e.ValueFrom.SecretKeyRef.Name, e.ValueFrom.SecretKeyRef.Key)
+		case e.ValueFrom.ConfigMapKeyRef != nil:
+			vv[e.Name] = fmt.Sprintf("configMap:%v:%v", e.ValueFrom.ConfigMapKeyRef.Name, e.ValueFrom.ConfigMapKeyRef.Key)
+		case e.ValueFrom.FieldRef != nil:
+			vv[e.Name] = "field:" + e.ValueFrom.FieldRef.FieldPath
+		default:
+			vv[e.Name] = e.ValueFrom.String()
+		}
+	}
+	return vv
+}
+
+// envFromValues returns the sources of all environment variables of
+// Secrets and ConfigMaps.
+func envFromValues(ee []corev1.EnvFromSource) map[string]string {
+	vv := make(map[string]string, len(ee))
+	for _, e := range ee {
+		var s string
+		switch {
+		case e.SecretRef != nil:
+			s = "secret:" + e.SecretRef.Name
+		case e.ConfigMapRef != nil:
+			s = "configMap:" + e.ConfigMapRef.Name
+		default:
+			continue
+		}
+		vv[s] = s
+	}
+	return vv
+}
+
+// volumeValues returns the sources of the volumes by the path at which each
+// is mounted.
+func volumeValues(volumes []corev1.Volume, mounts []corev1.VolumeMount) map[string]string {
+	sources := make(map[string]string, len(volumes))
+	for _, v := range volumes {
+		switch {
+		case v.Secret != nil:
+			sources[v.Name] = "secret:" + v.Secret.SecretName
+		case v.ConfigMap != nil:
+			sources[v.Name] = "configMap:" + v.ConfigMap.Name
+		case v.PersistentVolumeClaim != nil:
+			sources[v.Name] = "persistentVolumeClaim:" + v.PersistentVolumeClaim.ClaimName
+		case v.EmptyDir != nil:
+			sources[v.Name] = "emptyDir"
+		default:
+			sources[v.Name] = v.Name
+		}
+	}
+	vv := make(map[string]string, len(mounts))
+	for _, m := range mounts {
+		vv[m.MountPath] = sources[m.Name]
+		if m.ReadOnly {
+			vv[m.MountPath] += " (read-only)"
+		}
+	}
+	return vv
+}
+
+// functionAnnotations returns the annotations which are not set by the
+// cluster or its clients.
+func functionAnnotations(aa map[string]string) map[string]string {
+	vv := make(map[string]string, len(aa))
+	for k, v := range aa {
+		if !isSystemAnnotation(k) {
+			vv[k] = v
+		}
+	}
+	return vv
+}
+
+func isSystemAnnotation(k string) bool {
+	for _, p := range systemAnnotationPrefixes {
+		if strings.HasPrefix(k, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// revisionAnnotations returns the scale options of the annotations of a
+// revision by name (see scaleAnnotations), and its other annotations which
+// are not set by the cluster or its clients.
+func revisionAnnotations(aa map[string]string) (scale, other map[string]string) {
+	scale = map[string]string{}
+	other = functionAnnotations(aa)
+	for _, a := range scaleAnnotations {
+		if v, ok := other[a.annotation]; ok {
+			scale[a.value] = v
+			delete(other, a.annotation)
+		}
+	}
+	return
+}
+
+// resourceValues returns the resource requests and limits, by the name of
+// each such as "limits.cpu".
+func resourceValues(r corev1.ResourceRequirements) map[string]string {
+	vv := map[string]string{}
+	for prefix, rl := range map[string]corev1.ResourceList{"requests": r.Requests, "limits": r.Limits} {
+		for name, q := range rl {
+			vv[prefix+"."+string(name)] = q.String()
+		}
+	}
+	return vv
+}
+
+// concurrencyValue returns the container concurrency, which is unlimited
+// when zero or unset.
+func concurrencyValue(c *int64) string {
+	if c == nil || *c == 0 {
+		return ""
+	}
+	return fmt.Sprint(*c)
+}
@@ -0,0 +1,105 @@
+//go:build !integration
+// +build !integration
+
+package knative
+
+import (
+	"reflect"
+	"testing"
+
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestDiffServices ensures that a function which has not changed since it was
+// deployed does not differ from its Service, and that the fields which it
+// defines are otherwise reported as differences.
+func TestDiffServices(t *testing.T) {
+	var (
+		envName  = "LOG_LEVEL"
+		envValue = "info"
+		debug    = "debug"
+		max      = int64(10)
+	)
+	f := fn.Function{
+		Name:      "myfunc",
+		Namespace: "prod",
+		Deploy: fn.DeploySpec{
+			Namespace: "prod",
+			Image:     "example.com/alice/myfunc@sha256:deadbeef",
+		},
+		Run: fn.RunSpec{
+			Envs: []fn.Env{{Name: &envName, Value: &envValue}},
+		},
+	}
+	live, err := generateNewService(f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fields set by the cluster are not differences
+	live.Annotations["serving.knative.dev/creator"] = "alice"
+	live.Spec.Template.Annotations["client.knative.dev/updateTimestamp"] = "now"
+
+	desired, err := desiredService(f, live, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dd := diffServices(desired, live); len(dd) != 0 {
+		t.Fatalf("expected no differences, got %v", dd)
+	}
+
+	f.Deploy.Image = "example.com/alice/myfunc@sha256:cafebabe"
+	f.Run.Envs = []fn.Env{{Name: &envName, Value: &debug}}
+	f.Deploy.Options.Scale = &fn.ScaleOptions{Max: &max}
+	desired, err = desiredService(f, live, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []fn.Difference{
+		{Field: "image", Desired: "example.com/alice/myfunc@sha256:cafebabe", Live: "example.com/alice/myfunc@sha256:deadbeef"},
+		{Field: "env LOG_LEVEL", Desired: "debug", Live: "info"},
+		{Field: "scale max", Desired: "10", Live: ""},
+	}
+	if dd := diffServices(desired, live); !reflect.DeepEqual(dd, expected) {
+		t.Fatalf("expected differences %v, got %v", expected, dd)
+	}
+}
+
+// TestDiffTriggers ensures that subscriptions which are not live, and live
+// Triggers which are not subscribed, are reported as differences.
+func TestDiffTriggers(t *testing.T) {
+	f := fn.Function{
+		Name:      "myfunc",
+		Namespace: "prod",
+		Deploy: fn.DeploySpec{
+			Image: "example.com/alice/myfunc:latest",
+			Subscriptions: []fn.KnativeSubscription{
+				{Source: "default", Filters: map[string]string{"type": "order.created"}},
+			},
+		},
+	}
+	live, err := generateNewService(f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	live.Namespace = "prod"
+	liveTriggers := generateTriggers(f, live)
+	if dd := diffTriggers(generateTriggers(f, live), liveTriggers); len(dd) != 0 {
+		t.Fatalf("expected no differences, got %v", dd)
+	}
+
+	f.Deploy.Subscriptions = []fn.KnativeSubscription{
+		{Source: "default", Filters: map[string]string{"type": "order.shipped"}},
+	}
+	expected := []fn.Difference{
+		{Field: "subscription default type=order.created", Desired: "", Live: "default type=order.created"},
+		{Field: "subscription default type=order.shipped", Desired: "default type=order.shipped", Live: ""},
+	}
+	if dd := diffTriggers(generateTriggers(f, live), liveTriggers); !reflect.DeepEqual(dd, expected) {
+		t.Fatalf("expected differences %v, got %v", expected, dd)
+	}
+	if dd := diffTriggers(nil, []*eventingv1.Trigger{}); len(dd) != 0 {
+		t.Fatalf("expected no differences without triggers, got %v", dd)
+	}
+}
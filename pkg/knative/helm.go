@@ -0,0 +1,273 @@
+package knative
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"sigs.k8s.io/yaml"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// HelmValues are the values of the Helm chart of a function, with which the
+// chart's templates are rendered.  Each defaults to the function's own.
+type HelmValues struct {
+	Image                string                        `json:"image"`
+	Namespace            string                        `json:"namespace"`
+	ImagePullSecrets     []corev1.LocalObjectReference `json:"imagePullSecrets"`
+	Envs                 []corev1.EnvVar               `json:"envs"`
+	EnvFrom              []corev1.EnvFromSource        `json:"envFrom"`
+	Scale                HelmScale                     `json:"scale"`
+	Resources            corev1.ResourceRequirements   `json:"resources"`
+	ContainerConcurrency *int64                        `json:"containerConcurrency,omitempty"`
+	Volumes              []corev1.Volume               `json:"volumes"`
+	VolumeMounts         []corev1.VolumeMount          `json:"volumeMounts"`
+	Subscriptions        []HelmSubscription            `json:"subscriptions"`
+}
+
+// HelmScale are the scale options of the function (see fn.ScaleOptions).
+type HelmScale struct {
+	Min         *int64   `json:"min,omitempty"`
+	Max         *int64   `json:"max,omitempty"`
+	Metric      *string  `json:"metric,omitempty"`
+	Target      *float64 `json:"target,omitempty"`
+	Utilization *float64 `json:"utilization,omitempty"`
+}
+
+// HelmSubscription is a subscription of the function to the events of a
+// broker, for which a Trigger is rendered.
+type HelmSubscription struct {
+	Broker  string            `json:"broker"`
+	Filters map[string]string `json:"filters,omitempty"`
+}
+
+// helmMarker is a placeholder within a manifest which is replaced with a
+// template directive when the manifest is written as a template of a chart:
+// either within its line, or by replacing the line with a block.
+type helmMarker struct {
+	inline string
+	block  func(indent, key string) string
+}
+
+// helmWith is a block which renders the given key with the YAML of the value,
+// if it is not empty.
+func helmWith(value string) func(indent, key string) string {
+	return func(indent, key string) string {
+		return fmt.Sprintf("{{- with %v }}\n%v%v:\n%v  {{- toYaml . | nindent %v }}\n{{- end }}\n",
+			value, indent, key, indent, len(indent)+2)
+	}
+}
+
+// WriteHelmChart writes the manifests as a Helm chart in dir, whose values
+// (see HelmValues) are those of the function from which they were
+// generated.
+func (m Manifests) WriteHelmChart(dir string, f fn.Function) error {
+	values, err := m.helmValues(f)
+	if err != nil {
+		return err
+	}
+	service, err := m.helmServiceTemplate()
+	if err != nil {
+		return err
+	}
+	triggers, err := m.helmTriggersTemplate(f)
+	if err != nil {
+		return err
+	}
+
+	chart := fmt.Sprintf(`apiVersion: v2
+name: %v
+description: The function %v
+type: application
+version: 0.1.0
+`, f.Name, f.Name)
+
+	if err = os.MkdirAll(filepath.Join(dir, "templates"), 0755); err != nil {
+		return err
+	}
+	for name, bb := range map[string][]byte{
+		"Chart.yaml":              []byte(chart),
+		"values.yaml":             append([]byte(m.header()), values...),
+		"templates/service.yaml":  service,
+		"templates/triggers.yaml": triggers,
+	} {
+		if err = os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), bb, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// helmValues returns the values.yaml of the chart.
+func (m Manifests) helmValues(f fn.Function) ([]byte, error) {
+	spec := m.Service.Spec.Template.Spec
+	c := spec.Containers[0]
+	v := HelmValues{
+		Image:                c.Image,
+		Namespace:            m.Service.Namespace,
+		ImagePullSecrets:     append([]corev1.LocalObjectReference{}, spec.ImagePullSecrets...),
+		Envs:                 append([]corev1.EnvVar{}, c.Env...),
+		EnvFrom:              append([]corev1.EnvFromSource{}, c.EnvFrom...),
+		Resources:            c.Resources,
+		ContainerConcurrency: spec.ContainerConcurrency,
+		Volumes:              append([]corev1.Volume{}, spec.Volumes...),
+		VolumeMounts:         append([]corev1.VolumeMount{}, c.VolumeMounts...),
+		Subscriptions:        []HelmSubscription{},
+	}
+	if s := f.Deploy.Options.Scale; s != nil {
+		v.Scale = HelmScale{Min: s.Min, Max: s.Max, Metric: s.Metric, Target: s.Target, Utilization: s.Utilization}
+	}
+	for _, s := range f.Deploy.Subscriptions {
+		v.Subscriptions = append(v.Subscriptions, HelmSubscription{Broker: s.Source, Filters: s.Filters})
+	}
+	return yaml.Marshal(v)
+}
+
+// helmServiceTemplate returns the template of the Service, in which the
+// values of the chart replace those of the function.
+func (m Manifests) helmServiceTemplate() ([]byte, error) {
+	s, err := toManifestMap(m.Service)
+	if err != nil {
+		return nil, err
+	}
+	s["metadata"].(map[string]any)["namespace"] = "__namespace__"
+
+	template := s["spec"].(map[string]any)["template"].(map[string]any)
+	annotations := template["metadata"].(map[string]any)["annotations"].(map[string]any)
+	for _, a := range scaleAnnotations {
+		delete(annotations, a.annotation)
+	}
+	annotations["__scale__"] = ""
+
+	spec := template["spec"].(map[string]any)
+	spec["imagePullSecrets"] = "__imagePullSecrets__"
+	spec["containerConcurrency"] = "__containerConcurrency__"
+	spec["volumes"] = "__volumes__"
+	c := spec["containers"].([]any)[0].(map[string]any)
+	c["image"] = "__image__"
+	c["env"] = "__envs__"
+	c["envFrom"] = "__envFrom__"
+	c["resources"] = "__resources__"
+	c["volumeMounts"] = "__volumeMounts__"
+
+	bb, err := yaml.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return renderHelmMarkers(bb, map[string]helmMarker{
+		"__namespace__":        {inline: "{{ $.Values.namespace | default $.Release.Namespace | quote }}"},
+		"__image__":            {inline: "{{ $.Values.image | quote }}"},
+		"__imagePullSecrets__": {block: helmWith("$.Values.imagePullSecrets")},
+		"__envs__":             {block: helmWith("$.Values.envs")},
+		"__envFrom__":          {block: helmWith("$.Values.envFrom")},
+		"__resources__":        {block: helmWith("$.Values.resources")},
+		"__volumes__":          {block: helmWith("$.Values.volumes")},
+		"__volumeMounts__":     {block: helmWith("$.Values.volumeMounts")},
+		"__containerConcurrency__": {block: func(indent, key string) string {
+			return fmt.Sprintf("{{- with $.Values.containerConcurrency }}\n%v%v: {{ . }}\n{{- end }}\n", indent, key)
+		}},
+		"__scale__": {block: func(indent, _ string) string {
+			b := strings.Builder{}
+			for _, a := range scaleAnnotations {
+				fmt.Fprintf(&b, "{{- if hasKey $.Values.scale %q }}\n%v%v: {{ $.Values.scale.%v | quote }}\n{{- end }}\n",
+					a.value, indent, a.annotation, a.value)
+			}
+			return b.String()
+		}},
+	}), nil
+}
+
+// helmTriggersTemplate returns the template of the Triggers: one for each of
+// the subscriptions of the chart's values.
+func (m Manifests) helmTriggersTemplate(f fn.Function) ([]byte, error) {
+	f.Deploy.Subscriptions = []fn.KnativeSubscription{{Source: "__broker__"}}
+	t := generateTriggers(f, m.Service)[0]
+	t.Name = strings.TrimSuffix(t.Name, "0") + "__index__"
+	t.Namespace = "__namespace__"
+	t.SetGroupVersionKind(eventingv1.SchemeGroupVersion.WithKind("Trigger"))
+	tm, err := toManifestMap(t)
+	if err != nil {
+		return nil, err
+	}
+	tm["spec"].(map[string]any)["filter"].(map[string]any)["attributes"] = "__filters__"
+
+	bb, err := yaml.Marshal(tm)
+	if err != nil {
+		return nil, err
+	}
+	bb = renderHelmMarkers(bb, map[string]helmMarker{
+		"__index__":     {inline: "{{ $i }}"},
+		"__namespace__": {inline: "{{ $.Values.namespace | default $.Release.Namespace | quote }}"},
+		"__broker__":    {inline: "{{ $s.broker | quote }}"},
+		"__filters__":   {block: helmWith("$s.filters")},
+	})
+	return []byte("{{- range $i, $s := .Values.subscriptions }}\n---\n" + string(bb) + "{{- end }}\n"), nil
+}
+
+// renderHelmMarkers replaces the markers within the YAML of a manifest.
+func renderHelmMarkers(bb []byte, markers map[string]helmMarker) []byte {
+	out := bytes.Buffer{}
+	for _, line := range strings.SplitAfter(string(bb), "\n") {
+		if line == "" {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		indent := line[:len(line)-len(strings.TrimLeft(line, " "))]
+		replaced := false
+		for marker, m := range markers {
+			if !strings.Contains(line, marker) {
+				continue
+			}
+			if m.block != nil {
+				// The first key of an item of a list is written as an item
+				// of its own, such that the item remains if the block is
+				// empty.
+				if strings.HasPrefix(trimmed, "- ") {
+					out.WriteString(indent + "-\n")
+					indent, trimmed = indent+"  ", trimmed[2:]
+				}
+				key, _, _ := strings.Cut(trimmed, ":")
+				out.WriteString(m.block(indent, key))
+				replaced = true
+				break
+			}
+			line = strings.ReplaceAll(line, marker, m.inline)
+		}
+		if !replaced {
+			out.WriteString(line)
+		}
+	}
+	return out.Bytes()
+}
+
+// scaleAnnotations are the annotations of a revision with which the scale
+// options of a function are set (see setServiceOptions), by the name of each
+// option within the values of a chart.
+var scaleAnnotations = []struct{ value, annotation string }{
+	{"min", autoscaling.MinScaleAnnotationKey},
+	{"max", autoscaling.MaxScaleAnnotationKey},
+	{"metric", autoscaling.MetricAnnotationKey},
+	{"target", autoscaling.TargetAnnotationKey},
+	{"utilization", autoscaling.TargetUtilizationPercentageKey},
+}
+
+// toManifestMap returns the object as it is written as a manifest (see
+// MarshalManifest), as a map.
+func toManifestMap(o runtime.Object) (map[string]any, error) {
+	bb, err := MarshalManifest(o)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err = yaml.Unmarshal(bb, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
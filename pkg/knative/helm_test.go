@@ -0,0 +1,171 @@
+//go:build !integration
+// +build !integration
+
+package knative
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/yaml"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestManifests_WriteHelmChart ensures that the chart of a function renders
+// the manifests of the function with its default values, and with the
+// values overridden.
+func TestManifests_WriteHelmChart(t *testing.T) {
+	var (
+		envName  = "LOG_LEVEL"
+		envValue = "info"
+		min      = int64(1)
+		cm       = "settings"
+		path     = "/etc/settings"
+	)
+	f := fn.Function{
+		Name:      "myfunc",
+		Namespace: "prod",
+		Deploy: fn.DeploySpec{
+			Image: "example.com/alice/myfunc@sha256:deadbeef",
+			Subscriptions: []fn.KnativeSubscription{
+				{Source: "default", Filters: map[string]string{"type": "order.created"}},
+			},
+			Options: fn.Options{Scale: &fn.ScaleOptions{Min: &min}},
+		},
+		Run: fn.RunSpec{
+			Envs:    []fn.Env{{Name: &envName, Value: &envValue}},
+			Volumes: []fn.Volume{{ConfigMap: &cm, Path: &path}},
+		},
+	}
+	m, err := GenerateManifests(f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err = m.WriteHelmChart(dir, f); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "Chart.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Default values render the manifests of the function
+	s, tt := renderHelmChart(t, dir, nil)
+	c := s.Spec.Template.Spec.Containers[0]
+	if s.Namespace != "prod" || c.Image != f.Deploy.Image {
+		t.Fatalf("unexpected service %v/%v with image %v", s.Namespace, s.Name, c.Image)
+	}
+	if len(c.Env) != len(m.Service.Spec.Template.Spec.Containers[0].Env) || len(c.VolumeMounts) != 1 || len(s.Spec.Template.Spec.Volumes) != 1 {
+		t.Fatalf("unexpected envs %v or volumes %v", c.Env, s.Spec.Template.Spec.Volumes)
+	}
+	if s.Spec.Template.Annotations["autoscaling.knative.dev/min-scale"] != "1" {
+		t.Fatalf("unexpected revision annotations %v", s.Spec.Template.Annotations)
+	}
+	if len(tt) != 1 || tt[0].Name != "myfunc-function-trigger-0" || tt[0].Spec.Broker != "default" || tt[0].Spec.Filter.Attributes["type"] != "order.created" {
+		t.Fatalf("unexpected triggers %v", tt)
+	}
+
+	// Overridden values
+	s, tt = renderHelmChart(t, dir, map[string]any{
+		"image":         "example.com/alice/myfunc@sha256:cafebabe",
+		"namespace":     "",
+		"envs":          []any{map[string]any{"name": "LOG_LEVEL", "value": "debug"}},
+		"scale":         map[string]any{"max": 5},
+		"subscriptions": []any{},
+	})
+	c = s.Spec.Template.Spec.Containers[0]
+	if s.Namespace != "release" || c.Image != "example.com/alice/myfunc@sha256:cafebabe" {
+		t.Fatalf("unexpected service %v/%v with image %v", s.Namespace, s.Name, c.Image)
+	}
+	if len(c.Env) != 1 || c.Env[0].Value != "debug" {
+		t.Fatalf("unexpected envs %v", c.Env)
+	}
+	if _, ok := s.Spec.Template.Annotations["autoscaling.knative.dev/min-scale"]; ok || s.Spec.Template.Annotations["autoscaling.knative.dev/max-scale"] != "5" {
+		t.Fatalf("unexpected revision annotations %v", s.Spec.Template.Annotations)
+	}
+	if len(tt) != 0 {
+		t.Fatalf("expected no triggers, got %v", len(tt))
+	}
+}
+
+// renderHelmChart renders the templates of the chart in dir as would Helm,
+// with the chart's values overridden by those given, returning the Service
+// and Triggers rendered.
+func renderHelmChart(t *testing.T, dir string, overrides map[string]any) (s v1.Service, tt []eventingv1.Trigger) {
+	t.Helper()
+	bb, err := os.ReadFile(filepath.Join(dir, "values.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]any{}
+	if err = yaml.Unmarshal(bb, &values); err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range overrides {
+		values[k] = v
+	}
+
+	// The subset of the functions available to the templates of a Helm chart
+	// used by the chart.
+	funcs := template.FuncMap{
+		"toYaml": func(v any) string {
+			bb, err := yaml.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return strings.TrimSuffix(string(bb), "\n")
+		},
+		"nindent": func(n int, s string) string {
+			pad := strings.Repeat(" ", n)
+			return "\n" + pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+		"quote": func(v any) string { return fmt.Sprintf("%q", fmt.Sprint(v)) },
+		"default": func(d, v any) any {
+			if v == nil || v == "" {
+				return d
+			}
+			return v
+		},
+		"hasKey": func(m map[string]any, k string) bool { _, ok := m[k]; return ok },
+	}
+	data := map[string]any{"Values": values, "Release": map[string]any{"Namespace": "release"}}
+
+	out := bytes.Buffer{}
+	for _, name := range []string{"service.yaml", "triggers.yaml"} {
+		tmpl, err := template.New(name).Funcs(funcs).ParseFiles(filepath.Join(dir, "templates", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out.WriteString("---\n")
+		if err = tmpl.Execute(&out, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, doc := range strings.Split(out.String(), "\n---\n") {
+		doc = strings.TrimPrefix(strings.TrimSpace(doc), "---")
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		if strings.Contains(doc, "\nkind: Trigger\n") {
+			var tr eventingv1.Trigger
+			if err = yaml.UnmarshalStrict([]byte(doc), &tr); err != nil {
+				t.Fatalf("invalid trigger %v:\n%v", err, doc)
+			}
+			tt = append(tt, tr)
+			continue
+		}
+		if err = yaml.UnmarshalStrict([]byte(doc), &s); err != nil {
+			t.Fatalf("invalid service %v:\n%v", err, doc)
+		}
+	}
+	return
+}
@@ -0,0 +1,222 @@
+package knative
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// kustomization is a kustomization.yaml.
+type kustomization struct {
+	APIVersion string           `json:"apiVersion"`
+	Kind       string           `json:"kind"`
+	Namespace  string           `json:"namespace,omitempty"`
+	Resources  []string         `json:"resources"`
+	Images     []kustomizeImage `json:"images,omitempty"`
+	Patches    []kustomizePatch `json:"patches,omitempty"`
+}
+
+type kustomizeImage struct {
+	Name    string `json:"name"`
+	NewName string `json:"newName,omitempty"`
+	NewTag  string `json:"newTag,omitempty"`
+	Digest  string `json:"digest,omitempty"`
+}
+
+type kustomizePatch struct {
+	Path   string          `json:"path"`
+	Target kustomizeTarget `json:"target"`
+}
+
+type kustomizeTarget struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+}
+
+// kustomizeOverlayPaths are the paths of the Service which an overlay
+// patches, if they differ from the base.  The namespace and image are
+// instead set by the overlay's kustomization.
+var kustomizeOverlayPaths = []string{
+	"/metadata/labels",
+	"/metadata/annotations",
+	"/spec/template/metadata/labels",
+	"/spec/template/metadata/annotations",
+	"/spec/template/spec/containerConcurrency",
+	"/spec/template/spec/imagePullSecrets",
+	"/spec/template/spec/volumes",
+	"/spec/template/spec/containers/0/env",
+	"/spec/template/spec/containers/0/envFrom",
+	"/spec/template/spec/containers/0/resources",
+	"/spec/template/spec/containers/0/volumeMounts",
+}
+
+// WriteKustomization writes the manifests as a kustomize base in dir/base,
+// and an overlay in dir/overlays/NAME for each of the given manifests of
+// the function's environments, by name.  The base sets the image of the
+// function, such that it may be updated with 'kustomize edit set image'.
+// Each overlay sets the namespace and image of its environment, and patches
+// the Service with those of its envs, labels, annotations and scale and
+// resource options which differ from the base.
+func (m Manifests) WriteKustomization(dir string, overlays map[string]Manifests) error {
+	base := filepath.Join(dir, "base")
+	if err := m.WriteDir(base); err != nil {
+		return err
+	}
+	k := kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Images:     []kustomizeImage{kustomizeImageOf(m.image(), m.image())},
+	}
+	for _, o := range m.Objects() {
+		k.Resources = append(k.Resources, ManifestFilename(o))
+	}
+	if err := writeKustomization(base, k); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(overlays))
+	for name := range overlays {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := m.writeOverlay(filepath.Join(dir, "overlays", name), overlays[name]); err != nil {
+			return fmt.Errorf("unable to write the overlay %q. %w", name, err)
+		}
+	}
+	return nil
+}
+
+// writeOverlay writes the overlay of the base which results in the given
+// manifests.
+func (m Manifests) writeOverlay(dir string, o Manifests) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	k := kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  []string{"../../base"},
+	}
+	if o.Service.Namespace != m.Service.Namespace {
+		k.Namespace = o.Service.Namespace
+	}
+	if o.image() != m.image() {
+		k.Images = []kustomizeImage{kustomizeImageOf(m.image(), o.image())}
+	}
+
+	patch, err := servicePatch(m.Service, o.Service)
+	if err != nil {
+		return err
+	}
+	if len(patch) > 0 {
+		bb, err := yaml.Marshal(patch)
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(filepath.Join(dir, "service-patch.yaml"), bb, 0644); err != nil {
+			return err
+		}
+		gvk := o.Service.GroupVersionKind()
+		k.Patches = []kustomizePatch{{
+			Path:   "service-patch.yaml",
+			Target: kustomizeTarget{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind, Name: o.Service.Name},
+		}}
+	}
+	return writeKustomization(dir, k)
+}
+
+// image of the function's container.
+func (m Manifests) image() string {
+	return m.Service.Spec.Template.Spec.Containers[0].Image
+}
+
+func writeKustomization(dir string, k kustomization) error {
+	bb, err := yaml.Marshal(k)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "kustomization.yaml"), bb, 0644)
+}
+
+// kustomizeImageOf returns the kustomize image which replaces the image
+// within the resources with the new image.
+func kustomizeImageOf(image, newImage string) kustomizeImage {
+	name, _, _ := splitImage(image)
+	newName, tag, digest := splitImage(newImage)
+	i := kustomizeImage{Name: name, NewTag: tag, Digest: digest}
+	if newName != name {
+		i.NewName = newName
+	}
+	if digest != "" {
+		i.NewTag = ""
+	}
+	return i
+}
+
+// splitImage returns the name, tag and digest of an image reference.
+func splitImage(image string) (name, tag, digest string) {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image, digest = image[:i], image[i+1:]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image, tag = image[:i], image[i+1:]
+	}
+	return image, tag, digest
+}
+
+// servicePatch returns the JSON patch which results in the Service s from the
+// base Service, at the paths which an overlay patches.
+func servicePatch(base, s *v1.Service) ([]map[string]any, error) {
+	bm, err := toManifestMap(base)
+	if err != nil {
+		return nil, err
+	}
+	sm, err := toManifestMap(s)
+	if err != nil {
+		return nil, err
+	}
+	var patch []map[string]any
+	for _, path := range kustomizeOverlayPaths {
+		bv, inBase := lookupPath(bm, path)
+		sv, inService := lookupPath(sm, path)
+		switch {
+		case inService && (!inBase || !reflect.DeepEqual(bv, sv)):
+			patch = append(patch, map[string]any{"op": "add", "path": path, "value": sv})
+		case inBase && !inService:
+			patch = append(patch, map[string]any{"op": "remove", "path": path})
+		}
+	}
+	return patch, nil
+}
+
+// lookupPath returns the value at the JSON pointer path within v.
+func lookupPath(v any, path string) (any, bool) {
+	for _, p := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+		switch vv := v.(type) {
+		case map[string]any:
+			var ok bool
+			if v, ok = vv[p]; !ok {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(p)
+			if err != nil || i >= len(vv) {
+				return nil, false
+			}
+			v = vv[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
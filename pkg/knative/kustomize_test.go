@@ -0,0 +1,127 @@
+//go:build !integration
+// +build !integration
+
+package knative
+
+import (
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestManifests_WriteKustomization ensures that the base of a kustomize
+// export builds the manifests of the function, and that each overlay builds
+// those of its environment.
+func TestManifests_WriteKustomization(t *testing.T) {
+	var (
+		envName  = "LOG_LEVEL"
+		envValue = "info"
+		debug    = "debug"
+		max      = int64(10)
+	)
+	f := fn.Function{
+		Name:      "myfunc",
+		Namespace: "prod",
+		Deploy: fn.DeploySpec{
+			Image:         "example.com/alice/myfunc@sha256:deadbeef",
+			Subscriptions: []fn.KnativeSubscription{{Source: "default"}},
+		},
+		Run: fn.RunSpec{
+			Envs: []fn.Env{{Name: &envName, Value: &envValue}},
+		},
+	}
+	m, err := GenerateManifests(f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	staging := f
+	staging.Namespace = "staging"
+	staging.Deploy.Image = "example.com/alice/myfunc:staging"
+	staging.Run.Envs = []fn.Env{{Name: &envName, Value: &debug}}
+	staging.Deploy.Options.Scale = &fn.ScaleOptions{Max: &max}
+	overlay, err := GenerateManifests(staging, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err = m.WriteKustomization(dir, map[string]Manifests{"staging": overlay}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Base
+	rm := kustomizeBuild(t, filepath.Join(dir, "base"))
+	if rm.Size() != 2 {
+		t.Fatalf("expected 2 resources, got %v", rm.Size())
+	}
+	s := kustomizeService(t, rm)
+	c := s.Spec.Template.Spec.Containers[0]
+	if s.Namespace != "prod" || c.Image != f.Deploy.Image || envValueOf(c.Env, envName) != "info" {
+		t.Fatalf("unexpected base service %v with image %v and envs %v", s.Namespace, c.Image, c.Env)
+	}
+
+	// Overlay
+	rm = kustomizeBuild(t, filepath.Join(dir, "overlays", "staging"))
+	s = kustomizeService(t, rm)
+	c = s.Spec.Template.Spec.Containers[0]
+	if s.Namespace != "staging" || c.Image != staging.Deploy.Image {
+		t.Fatalf("unexpected overlay service %v with image %v", s.Namespace, c.Image)
+	}
+	if len(c.Env) != 2 || envValueOf(c.Env, envName) != "debug" {
+		t.Fatalf("unexpected overlay envs %v", c.Env)
+	}
+	if s.Spec.Template.Annotations["autoscaling.knative.dev/max-scale"] != "10" {
+		t.Fatalf("unexpected overlay revision annotations %v", s.Spec.Template.Annotations)
+	}
+	for _, r := range rm.Resources() {
+		if r.GetNamespace() != "staging" {
+			t.Fatalf("expected %v to be in the staging namespace, got %q", r.GetName(), r.GetNamespace())
+		}
+	}
+}
+
+func envValueOf(ee []corev1.EnvVar, name string) string {
+	for _, e := range ee {
+		if e.Name == name {
+			return e.Value
+		}
+	}
+	return ""
+}
+
+func kustomizeBuild(t *testing.T, dir string) resmap.ResMap {
+	t.Helper()
+	rm, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(filesys.MakeFsOnDisk(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rm
+}
+
+func kustomizeService(t *testing.T, rm resmap.ResMap) (s v1.Service) {
+	t.Helper()
+	for _, r := range rm.Resources() {
+		if r.GetKind() != "Service" {
+			continue
+		}
+		bb, err := r.AsYAML()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = yaml.Unmarshal(bb, &s); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	t.Fatal("no service was built")
+	return
+}
@@ -0,0 +1,219 @@
+package knative
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/yaml"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Manifests are the resources with which a function is deployed, generated
+// as they would be by the Deployer but without contacting the cluster, such
+// that they can be applied by other means, such as by a GitOps tool.
+type Manifests struct {
+	// Service of the function.
+	Service *v1.Service
+
+	// Triggers of the function's subscriptions.
+	Triggers []*eventingv1.Trigger
+
+	// Secrets, ConfigMaps and PersistentVolumeClaims referenced by the
+	// Service.  These are not generated, and must exist in its namespace.
+	Secrets                []string
+	ConfigMaps             []string
+	PersistentVolumeClaims []string
+}
+
+// GenerateManifests generates the manifests of the function, which is
+// deployed as f.Deploy.Image into f.Namespace (or, if not set, the namespace
+// to which it was last deployed).  The Service's pods pull the image using
+// the given image pull Secrets, if any, which are referenced as are those of
+// the function's environment variables and volumes.
+func GenerateManifests(f fn.Function, imagePullSecrets []string) (m Manifests, err error) {
+	if f.Name == "" {
+		return m, fn.ErrNameRequired
+	}
+	if f.Deploy.Image == "" {
+		return m, fmt.Errorf("function %q has no image to deploy", f.Name)
+	}
+	namespace := f.Namespace
+	if namespace == "" {
+		namespace = f.Deploy.Namespace
+	}
+
+	if m.Service, err = generateNewService(f, nil); err != nil {
+		return m, fmt.Errorf("failed to generate the Knative Service: %v", err)
+	}
+	m.Service.SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("Service"))
+	m.Service.Namespace = namespace
+	removeBuiltEnv(m.Service)
+	for _, s := range imagePullSecrets {
+		m.Service.Spec.Template.Spec.ImagePullSecrets = append(m.Service.Spec.Template.Spec.ImagePullSecrets, corev1.LocalObjectReference{Name: s})
+	}
+
+	for _, t := range generateTriggers(f, m.Service) {
+		t.SetGroupVersionKind(eventingv1.SchemeGroupVersion.WithKind("Trigger"))
+		m.Triggers = append(m.Triggers, t)
+	}
+
+	// The resources referenced, as they would be checked to be present by
+	// the Deployer.
+	referencedSecrets := sets.New[string](imagePullSecrets...)
+	referencedConfigMaps := sets.New[string]()
+	referencedPVCs := sets.New[string]()
+	if _, _, err = processEnvs(f.Run.Envs, &referencedSecrets, &referencedConfigMaps); err != nil {
+		return
+	}
+	if _, _, err = processVolumes(f.Run.Volumes, &referencedSecrets, &referencedConfigMaps, &referencedPVCs); err != nil {
+		return
+	}
+	m.Secrets = sets.List(referencedSecrets)
+	m.ConfigMaps = sets.List(referencedConfigMaps)
+	m.PersistentVolumeClaims = sets.List(referencedPVCs)
+	return
+}
+
+// removeBuiltEnv removes the BUILT environment variable, with which the
+// Deployer forces a new revision upon each deployment, such that manifests
+// generated from the same function are the same.  A new revision is instead
+// created when the manifests change, such as when the image does.
+func removeBuiltEnv(s *v1.Service) {
+	c := &s.Spec.Template.Spec.Containers[0]
+	envs := c.Env[:0]
+	for _, e := range c.Env {
+		if e.Name != "BUILT" {
+			envs = append(envs, e)
+		}
+	}
+	c.Env = envs
+}
+
+// Objects are the generated resources: the Service followed by its Triggers.
+func (m Manifests) Objects() []runtime.Object {
+	oo := []runtime.Object{m.Service}
+	for _, t := range m.Triggers {
+		oo = append(oo, t)
+	}
+	return oo
+}
+
+// Write the manifests as a stream of YAML documents, preceded by a comment
+// listing the referenced resources which must exist.
+func (m Manifests) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, m.header()); err != nil {
+		return err
+	}
+	for i, o := range m.Objects() {
+		bb, err := MarshalManifest(o)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err = io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if _, err = w.Write(bb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteDir writes each manifest to its own file in dir, named by its kind and
+// name.  The referenced resources are listed in a comment in the file of the
+// Service.
+func (m Manifests) WriteDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for i, o := range m.Objects() {
+		bb, err := MarshalManifest(o)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			bb = append([]byte(m.header()), bb...)
+		}
+		if err = os.WriteFile(filepath.Join(dir, ManifestFilename(o)), bb, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// header is a comment listing the resources referenced by the Service, if
+// any.
+func (m Manifests) header() string {
+	if len(m.Secrets)+len(m.ConfigMaps)+len(m.PersistentVolumeClaims) == 0 {
+		return ""
+	}
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "# The following resources are referenced by the function %q, and must\n", m.Service.Name)
+	fmt.Fprintf(&b, "# exist in its namespace:\n")
+	for _, r := range []struct {
+		kind  string
+		names []string
+	}{
+		{"Secret", m.Secrets},
+		{"ConfigMap", m.ConfigMaps},
+		{"PersistentVolumeClaim", m.PersistentVolumeClaims},
+	} {
+		for _, name := range r.names {
+			fmt.Fprintf(&b, "#   %v %v\n", r.kind, name)
+		}
+	}
+	return b.String()
+}
+
+// ManifestFilename returns the name of the file of a manifest: its kind and
+// name in lower case.  For example "service-myfunc.yaml".
+func ManifestFilename(o runtime.Object) string {
+	name := ""
+	if m, ok := o.(interface{ GetName() string }); ok {
+		name = m.GetName()
+	}
+	return strings.ToLower(o.GetObjectKind().GroupVersionKind().Kind) + "-" + name + ".yaml"
+}
+
+// MarshalManifest returns the object as YAML, omitting its status and
+// unset creation timestamps, which are populated by the cluster.
+func MarshalManifest(o runtime.Object) ([]byte, error) {
+	bb, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	d := json.NewDecoder(bytes.NewReader(bb))
+	d.UseNumber()
+	if err = d.Decode(&m); err != nil {
+		return nil, err
+	}
+	delete(m, "status")
+	removeNullTimestamps(m)
+	return yaml.Marshal(m)
+}
+
+// removeNullTimestamps removes the unset creationTimestamp of each metadata
+// within the object.
+func removeNullTimestamps(m map[string]any) {
+	for k, v := range m {
+		if k == "creationTimestamp" && v == nil {
+			delete(m, k)
+		} else if mm, ok := v.(map[string]any); ok {
+			removeNullTimestamps(mm)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+//go:build !integration
+// +build !integration
+
+package knative
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestGenerateManifests ensures that the manifests of a function are the
+// Service and Triggers as deployed, within the function's namespace, and that
+// the resources they reference are listed.
+func TestGenerateManifests(t *testing.T) {
+	var (
+		envName   = "DB_PASSWORD"
+		secretEnv = "{{ secret:db:password }}"
+		cm        = "settings"
+		pvc       = "data"
+		cmPath    = "/etc/settings"
+		pvcPath   = "/data"
+	)
+	f := fn.Function{
+		Name:      "myfunc",
+		Namespace: "prod",
+		Deploy: fn.DeploySpec{
+			Image: "example.com/alice/myfunc@sha256:deadbeef",
+			Subscriptions: []fn.KnativeSubscription{
+				{Source: "default", Filters: map[string]string{"type": "order.created"}},
+			},
+		},
+		Run: fn.RunSpec{
+			Envs:    []fn.Env{{Name: &envName, Value: &secretEnv}},
+			Volumes: []fn.Volume{{ConfigMap: &cm, Path: &cmPath}, {PersistentVolumeClaim: &fn.PersistentVolumeClaim{ClaimName: &pvc}, Path: &pvcPath}},
+		},
+	}
+
+	m, err := GenerateManifests(f, []string{"regcred"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Service
+	if s.Kind != "Service" || s.APIVersion != "serving.knative.dev/v1" {
+		t.Fatalf("unexpected service type %v %v", s.APIVersion, s.Kind)
+	}
+	if s.Namespace != "prod" {
+		t.Fatalf("expected namespace prod, got %q", s.Namespace)
+	}
+	if image := s.Spec.Template.Spec.Containers[0].Image; image != f.Deploy.Image {
+		t.Fatalf("expected image %v, got %v", f.Deploy.Image, image)
+	}
+	if pp := s.Spec.Template.Spec.ImagePullSecrets; len(pp) != 1 || pp[0].Name != "regcred" {
+		t.Fatalf("unexpected image pull secrets %v", pp)
+	}
+
+	if len(m.Triggers) != 1 {
+		t.Fatalf("expected 1 trigger, got %v", len(m.Triggers))
+	}
+	tr := m.Triggers[0]
+	if tr.Name != "myfunc-function-trigger-0" || tr.Namespace != "prod" || tr.Kind != "Trigger" {
+		t.Fatalf("unexpected trigger %v/%v %v", tr.Namespace, tr.Name, tr.Kind)
+	}
+	if len(tr.OwnerReferences) != 0 {
+		t.Fatal("expected no owner references, as the service does not yet exist")
+	}
+	if ref := tr.Spec.Subscriber.Ref; ref.Kind != "Service" || ref.Name != "myfunc" {
+		t.Fatalf("unexpected subscriber %v", ref)
+	}
+	if tr.Spec.Broker != "default" || tr.Spec.Filter.Attributes["type"] != "order.created" {
+		t.Fatalf("unexpected trigger spec %v", tr.Spec)
+	}
+
+	if !reflect.DeepEqual(m.Secrets, []string{"db", "regcred"}) {
+		t.Fatalf("unexpected secrets %v", m.Secrets)
+	}
+	if !reflect.DeepEqual(m.ConfigMaps, []string{"settings"}) {
+		t.Fatalf("unexpected config maps %v", m.ConfigMaps)
+	}
+	if !reflect.DeepEqual(m.PersistentVolumeClaims, []string{"data"}) {
+		t.Fatalf("unexpected persistent volume claims %v", m.PersistentVolumeClaims)
+	}
+
+	// Requires an image
+	f.Deploy.Image = ""
+	if _, err = GenerateManifests(f, nil); err == nil {
+		t.Fatal("expected an error generating manifests without an image")
+	}
+}
+
+// TestManifests_Write ensures that manifests are written as a YAML stream or
+// as a file per manifest, without the fields populated by the cluster.
+func TestManifests_Write(t *testing.T) {
+	f := fn.Function{
+		Name:      "myfunc",
+		Namespace: "prod",
+		Deploy: fn.DeploySpec{
+			Image:         "example.com/alice/myfunc:latest",
+			Subscriptions: []fn.KnativeSubscription{{Source: "default"}},
+		},
+	}
+	m, err := GenerateManifests(f, []string{"regcred"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err = m.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{"#   Secret regcred\n", "kind: Service\n", "\n---\n", "kind: Trigger\n"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected output to contain %q:\n%v", s, out)
+		}
+	}
+	for _, s := range []string{"status:", "creationTimestamp", "BUILT"} {
+		if strings.Contains(out, s) {
+			t.Errorf("expected output to not contain %q:\n%v", s, out)
+		}
+	}
+
+	dir := t.TempDir()
+	if err = m.WriteDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"service-myfunc.yaml", "trigger-myfunc-function-trigger-0.yaml"} {
+		if _, err = os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected manifest file %v. %v", name, err)
+		}
+	}
+}
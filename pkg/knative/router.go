@@ -0,0 +1,113 @@
+package knative
+
+import (
+	"context"
+	"fmt"
+
+	clientservingv1 "knative.dev/client/pkg/serving/v1"
+	"knative.dev/client/pkg/wait"
+	"knative.dev/pkg/ptr"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+const (
+	// StableTag is the tag of the revision which receives the remainder of
+	// the traffic of a function during a rollout.
+	StableTag = "stable"
+	// CanaryTag is the tag of the latest revision of a function during a
+	// rollout.
+	CanaryTag = "canary"
+)
+
+type Router struct {
+	verbose bool
+}
+
+func NewRouter(verbose bool) *Router {
+	return &Router{verbose: verbose}
+}
+
+// Route the given percent of the traffic of the function's Knative Service
+// to its latest revision, tagged canary, and the remainder to the revision
+// tagged stable.  Routing all traffic to the latest revision or none of it
+// ends the rollout, removing the tags.
+func (r *Router) Route(ctx context.Context, f fn.Function, percent int64) error {
+	client, err := NewServingClient(f.Deploy.Namespace)
+	if err != nil {
+		return err
+	}
+	_, err = client.UpdateServiceWithRetry(ctx, f.Name, func(s *v1.Service) (*v1.Service, error) {
+		stable := taggedRevision(s, StableTag)
+		if stable == "" {
+			return nil, fn.ErrRolloutNotInProgress
+		}
+		s.Spec.Traffic = rolloutTraffic(stable, percent)
+		return s, nil
+	}, 3)
+	if err != nil {
+		return fmt.Errorf("knative router failed to update the Knative Service: %w", err)
+	}
+	if r.verbose {
+		fmt.Printf("Routing %v%% of traffic to the latest revision of %q\n", percent, f.Name)
+	}
+	err, _ = client.WaitForService(ctx, f.Name,
+		clientservingv1.WaitConfig{Timeout: DefaultWaitingTimeout, ErrorWindow: DefaultErrorWindowTimeout},
+		wait.NoopMessageCallback())
+	return err
+}
+
+// rolloutTraffic returns the traffic of a rollout which routes the given
+// percent to the latest revision and the remainder to the stable revision.
+// All traffic is routed to the latest revision by default, and none routes
+// it to the stable revision, without tags as the rollout is over.
+func rolloutTraffic(stable string, percent int64) []v1.TrafficTarget {
+	switch {
+	case percent >= 100:
+		return nil
+	case percent <= 0:
+		return []v1.TrafficTarget{{RevisionName: stable, Percent: ptr.Int64(100)}}
+	}
+	return []v1.TrafficTarget{
+		{Tag: StableTag, RevisionName: stable, Percent: ptr.Int64(100 - percent)},
+		{Tag: CanaryTag, LatestRevision: ptr.Bool(true), Percent: ptr.Int64(percent)},
+	}
+}
+
+// deployTraffic returns the traffic of the Service updated with a new
+// revision of the function.  A canary is rolled out against the revision
+// which received the traffic of the previous Service, which is that tagged
+// stable during a rollout, that to which it was pinned if aborted, or
+// otherwise its latest.
+func deployTraffic(f fn.Function, previous *v1.Service) []v1.TrafficTarget {
+	if !f.Deploy.Rollout.Canary() {
+		return nil
+	}
+	stable := taggedRevision(previous, StableTag)
+	if stable == "" {
+		for _, t := range previous.Spec.Traffic {
+			if t.RevisionName != "" && t.Percent != nil && *t.Percent == 100 {
+				stable = t.RevisionName
+			}
+		}
+	}
+	if stable == "" {
+		stable = previous.Status.LatestReadyRevisionName
+	}
+	if stable == "" {
+		return nil // no revision was ready
+	}
+	return rolloutTraffic(stable, f.Deploy.Rollout.Percent)
+}
+
+// taggedRevision returns the name of the revision of the Service's traffic
+// with the given tag.
+func taggedRevision(s *v1.Service, tag string) string {
+	for _, t := range s.Spec.Traffic {
+		if t.Tag == tag {
+			return t.RevisionName
+		}
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+//go:build !integration
+// +build !integration
+
+package knative
+
+import (
+	"testing"
+
+	"knative.dev/pkg/ptr"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestDeployTraffic ensures that a canary is rolled out against the revision
+// which received the traffic of the previous Service, and that otherwise all
+// traffic is routed to the latest revision.
+func TestDeployTraffic(t *testing.T) {
+	canary := fn.Function{Deploy: fn.DeploySpec{Rollout: &fn.RolloutSpec{Percent: 10}}}
+
+	previous := &v1.Service{}
+	previous.Status.LatestReadyRevisionName = "myfunc-00001"
+
+	// Without a rollout
+	if tt := deployTraffic(fn.Function{}, previous); tt != nil {
+		t.Fatalf("expected the default traffic, got %v", tt)
+	}
+
+	// Against the latest revision
+	tt := deployTraffic(canary, previous)
+	if len(tt) != 2 || tt[0].Tag != StableTag || tt[0].RevisionName != "myfunc-00001" || *tt[0].Percent != 90 {
+		t.Fatalf("unexpected stable traffic %v", tt)
+	}
+	if tt[1].Tag != CanaryTag || !*tt[1].LatestRevision || *tt[1].Percent != 10 {
+		t.Fatalf("unexpected canary traffic %v", tt)
+	}
+
+	// Against the stable revision of a rollout in progress
+	previous.Spec.Traffic = tt
+	previous.Status.LatestReadyRevisionName = "myfunc-00002"
+	if tt = deployTraffic(canary, previous); tt[0].RevisionName != "myfunc-00001" {
+		t.Fatalf("expected the stable revision to be retained, got %v", tt[0].RevisionName)
+	}
+
+	// Against the revision to which an aborted rollout pinned the traffic
+	previous.Spec.Traffic = []v1.TrafficTarget{{RevisionName: "myfunc-00001", Percent: ptr.Int64(100)}}
+	if tt = deployTraffic(canary, previous); tt[0].RevisionName != "myfunc-00001" {
+		t.Fatalf("expected the pinned revision to be stable, got %v", tt[0].RevisionName)
+	}
+}
+
+// TestRolloutTraffic ensures that promoting routes all traffic to the latest
+// revision, and that aborting routes it to the stable revision.
+func TestRolloutTraffic(t *testing.T) {
+	if tt := rolloutTraffic("myfunc-00001", 100); tt != nil {
+		t.Fatalf("expected the default traffic when promoted, got %v", tt)
+	}
+	tt := rolloutTraffic("myfunc-00001", 0)
+	if len(tt) != 1 || tt[0].RevisionName != "myfunc-00001" || *tt[0].Percent != 100 || tt[0].Tag != "" {
+		t.Fatalf("unexpected traffic when aborted %v", tt)
+	}
+	tt = rolloutTraffic("myfunc-00001", 40)
+	if *tt[0].Percent != 60 || *tt[1].Percent != 40 {
+		t.Fatalf("unexpected traffic %v", tt)
+	}
+}
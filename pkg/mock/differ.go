@@ -0,0 +1,23 @@
+package mock
+
+import (
+	"context"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+type Differ struct {
+	DiffInvoked bool
+	DiffFn      func(context.Context, fn.Function) ([]fn.Difference, error)
+}
+
+func NewDiffer() *Differ {
+	return &Differ{
+		DiffFn: func(context.Context, fn.Function) ([]fn.Difference, error) { return nil, nil },
+	}
+}
+
+func (d *Differ) Diff(ctx context.Context, f fn.Function) ([]fn.Difference, error) {
+	d.DiffInvoked = true
+	return d.DiffFn(ctx, f)
+}
@@ -0,0 +1,23 @@
+package mock
+
+import (
+	"context"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+type Router struct {
+	RouteInvoked bool
+	RouteFn      func(context.Context, fn.Function, int64) error
+}
+
+func NewRouter() *Router {
+	return &Router{
+		RouteFn: func(context.Context, fn.Function, int64) error { return nil },
+	}
+}
+
+func (r *Router) Route(ctx context.Context, f fn.Function, percent int64) error {
+	r.RouteInvoked = true
+	return r.RouteFn(ctx, f, percent)
+}
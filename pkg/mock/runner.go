@@ -3,7 +3,6 @@ package mock
 import (
 	"context"
 	"sync"
-	"time"
 
 	fn "knative.dev/func/pkg/functions"
 )
@@ -13,25 +12,33 @@ import (
 type Runner struct {
 	RunInvoked    bool
 	RootRequested string
-	RunFn         func(context.Context, fn.Function, time.Duration) (*fn.Job, error)
+	RunFn         func(context.Context, fn.Function, fn.RunOptions) (*fn.Job, error)
 	sync.Mutex
 }
 
 func NewRunner() *Runner {
 	return &Runner{
-		RunFn: func(ctx context.Context, f fn.Function, t time.Duration) (*fn.Job, error) {
+		RunFn: func(ctx context.Context, f fn.Function, opts fn.RunOptions) (*fn.Job, error) {
 			errs := make(chan error, 1)
 			stop := func() error { return nil }
-			return fn.NewJob(f, "127.0.0.1", "8080", errs, stop, false)
+			port := opts.Port
+			if port == "" {
+				port = "8080"
+			}
+			job, err := fn.NewJob(f, "127.0.0.1", port, errs, stop, false)
+			if job != nil {
+				job.Detached = opts.Detach
+			}
+			return job, err
 		},
 	}
 }
 
-func (r *Runner) Run(ctx context.Context, f fn.Function, t time.Duration) (*fn.Job, error) {
+func (r *Runner) Run(ctx context.Context, f fn.Function, opts fn.RunOptions) (*fn.Job, error) {
 	r.Lock()
 	defer r.Unlock()
 	r.RunInvoked = true
 	r.RootRequested = f.Root
 
-	return r.RunFn(ctx, f, t)
+	return r.RunFn(ctx, f, opts)
 }
@@ -2,6 +2,7 @@ package oci
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,19 +13,13 @@ import (
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 
+	"knative.dev/func/pkg/filesystem"
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/scaffolding"
 )
 
 var path = filepath.Join
 
-var defaultIgnored = []string{ // TODO: implement and use .funcignore
-	".git",
-	".func",
-	".funcignore",
-	".gitignore",
-}
-
 // Builder which creates an OCI-compliant multi-arch (index) container from
 // the function at path.
 type Builder struct {
@@ -82,8 +77,12 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, pp []fn.Platform) (e
 		return
 	}
 
-	// Write out the scaffolding
+	// Write out the scaffolding.  Languages for which scaffolding is not yet
+	// available are started directly from source, requiring only the certs.
 	err = scaffolding.Write(cfg.buildDir(), f.Root, f.Runtime, f.Invoke, repo.FS())
+	if errors.As(err, &scaffolding.ErrDetectorNotImplemented{}) {
+		err = filesystem.CopyFromFS("certs", cfg.buildDir(), repo.FS())
+	}
 	if err != nil {
 		return
 	}
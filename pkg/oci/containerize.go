@@ -14,10 +14,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/pkg/errors"
+
+	"knative.dev/func/pkg/builders"
+	"knative.dev/func/pkg/funcignore"
+	fn "knative.dev/func/pkg/functions"
 )
 
 // languageLayerBuilder builds the layer for the given language whuch may
@@ -26,10 +33,55 @@ import (
 type languageLayerBuilder func(*buildConfig, v1.Platform) (v1.Descriptor, v1.Layer, error)
 
 var languageLayerBuilders = map[string]languageLayerBuilder{
-	"go":     buildGoLayer,
-	"python": layerBuilderNotImplemented,
-	"node":   layerBuilderNotImplemented,
-	"rust":   layerBuilderNotImplemented,
+	"go":         buildGoLayer,
+	"python":     buildPythonLayer,
+	"node":       buildNodeLayer,
+	"typescript": buildNodeLayer,
+	"rust":       layerBuilderNotImplemented,
+}
+
+// dependencyLayerBuilders build the layer containing the dependencies of
+// the function for the given language, if the language vendors its
+// dependencies separately from the executable layer.  Keeping dependencies
+// in their own layer allows it to be reused when only the source changes.
+var dependencyLayerBuilders = map[string]languageLayerBuilder{
+	"python":     buildPythonDependencyLayer,
+	"node":       buildNodeDependencyLayer,
+	"typescript": buildNodeDependencyLayer,
+}
+
+// languageIgnored are files which are excluded from the data layer for each
+// language, such as those which are provided by the dependency layer.
+var languageIgnored = map[string][]string{
+	"node":       {"node_modules"},
+	"typescript": {"node_modules"},
+}
+
+// DefaultBaseImages for languages which require a runtime (such as an
+// interpreter) in the final container, indexed by Runtime Language.
+// Languages which are not listed are built upon an empty (scratch) image.
+// The base image can be altered using the function's builder image setting
+// for the host builder.
+var DefaultBaseImages = map[string]string{
+	"python":     "docker.io/library/python:3.12-slim",
+	"node":       "docker.io/library/node:20-slim",
+	"typescript": "docker.io/library/node:20-slim",
+}
+
+// languageCommands return the command which starts the function for each
+// language.  The default is the executable built by the Go layer builder.
+var languageCommands = map[string]func(*buildConfig) []string{
+	"python":     func(*buildConfig) []string { return []string{"python", "/scaffolding/main.py"} },
+	"node":       nodeCommand,
+	"typescript": nodeCommand,
+}
+
+// languageEnvs are the environment variables required by the function's
+// container for each language.
+var languageEnvs = map[string][]string{
+	"python":     {"PYTHONPATH=/site-packages", "PYTHONUNBUFFERED=1", "PYTHONDONTWRITEBYTECODE=1"},
+	"node":       {"NODE_ENV=production", "FUNC_LOG_LEVEL=info"},
+	"typescript": {"NODE_ENV=production", "FUNC_LOG_LEVEL=info"},
 }
 
 func layerBuilderNotImplemented(cfg *buildConfig, _ v1.Platform) (d v1.Descriptor, l v1.Layer, err error) {
@@ -104,7 +156,7 @@ func newDataLayer(cfg *buildConfig) (desc v1.Descriptor, layer v1.Layer, err err
 	source := cfg.f.Root // The source is the function's entire filesystem
 	target := path(cfg.buildDir(), "datalayer.tar.gz")
 
-	if err = newDataTarball(source, target, defaultIgnored, cfg.verbose); err != nil {
+	if err = newDataTarball(source, target, languageIgnored[cfg.f.Runtime], cfg.verbose); err != nil {
 		return
 	}
 
@@ -127,7 +179,20 @@ func newDataLayer(cfg *buildConfig) (desc v1.Descriptor, layer v1.Layer, err err
 	return
 }
 
+// newDataTarball writes the function's source at root to the target tarball,
+// excluding the files ignored by its .funcignore files and those whose
+// names are ignored.
 func newDataTarball(root, target string, ignored []string, verbose bool) error {
+	return newDirTarball(root, "/func", target, funcignore.Walk, ignored, verbose)
+}
+
+// walkFunc walks the files of the directory at root, such as
+// filepath.WalkDir.
+type walkFunc func(root string, fn fs.WalkDirFunc) error
+
+// newDirTarball writes the directory at root to the target tarball with
+// paths rooted at the given prefix in the container file hierarchy.
+func newDirTarball(root, prefix, target string, walk walkFunc, ignored []string, verbose bool) error {
 	targetFile, err := os.Create(target)
 	if err != nil {
 		return err
@@ -140,21 +205,32 @@ func newDataTarball(root, target string, ignored []string, verbose bool) error {
 	tw := tar.NewWriter(gw)
 	defer tw.Close()
 
-	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+	return writeDir(tw, root, prefix, walk, ignored, verbose)
+}
+
+// writeDir writes the contents of the directory root, as walked, to the tar
+// writer at the given prefix, excluding files whose names are ignored.
+func writeDir(tw *tar.Writer, root, prefix string, walk walkFunc, ignored []string, verbose bool) error {
+	return walk(root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
 		// Skip files explicitly ignored
 		for _, v := range ignored {
-			if info.Name() == v {
-				if info.IsDir() {
+			if d.Name() == v {
+				if d.IsDir() {
 					return filepath.SkipDir
 				}
 				return nil
 			}
 		}
 
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
 		lnk := "" // if link, this will be used as the target
 		if info.Mode()&fs.ModeSymlink != 0 {
 			if lnk, err = validatedLinkTarget(root, path); err != nil {
@@ -171,7 +247,7 @@ func newDataTarball(root, target string, ignored []string, verbose bool) error {
 		if err != nil {
 			return err
 		}
-		header.Name = slashpath.Join("/func", filepath.ToSlash(relPath))
+		header.Name = slashpath.Join(prefix, filepath.ToSlash(relPath))
 		if err := tw.WriteHeader(header); err != nil {
 			return err
 		}
@@ -236,7 +312,7 @@ func newCertsLayer(cfg *buildConfig) (desc v1.Descriptor, layer v1.Layer, err er
 	source := filepath.Join(cfg.buildDir(), "ca-certificates.crt")
 	target := path(cfg.buildDir(), "certslayer.tar.gz")
 
-	if err = newCertsTarball(source, target, defaultIgnored, cfg.verbose); err != nil {
+	if err = newCertsTarball(source, target, cfg.verbose); err != nil {
 		return
 	}
 
@@ -259,7 +335,7 @@ func newCertsLayer(cfg *buildConfig) (desc v1.Descriptor, layer v1.Layer, err er
 	return
 }
 
-func newCertsTarball(source, target string, ignored []string, verbose bool) error {
+func newCertsTarball(source, target string, verbose bool) error {
 	targetFile, err := os.Create(target)
 	if err != nil {
 		return err
@@ -328,21 +404,47 @@ func newDescriptor(layer v1.Layer) (desc v1.Descriptor, err error) {
 }
 
 // newImage creates an image for the given platform.
-// The image consists of the shared data layer which is provided
+// The image consists of the layers of the language's base image (if any),
+// the shared data and certs layers which are provided, the language's
+// dependency layer (if any) and the platform-specific exec layer.
 func newImage(cfg *buildConfig, dataDesc v1.Descriptor, dataLayer v1.Layer, certsDesc v1.Descriptor, certsLayer v1.Layer, p v1.Platform, verbose bool) (imageDesc v1.Descriptor, err error) {
 	buildFn, err := getLanguageLayerBuilder(cfg)
 	if err != nil {
 		return
 	}
 
+	// Write Base Image Layers as Blobs -> Layers
+	baseDescs, baseLayers, baseConfig, err := newBaseLayers(cfg, p)
+	if err != nil {
+		return
+	}
+	descs := append(baseDescs, dataDesc, certsDesc)
+	layers := append(baseLayers, dataLayer, certsLayer)
+
+	// Write Dependency Layer as Blob -> Layer
+	if depsFn, ok := dependencyLayerBuilders[cfg.f.Runtime]; ok {
+		depsDesc, depsLayer, err := depsFn(cfg, p)
+		if err != nil {
+			return imageDesc, err
+		}
+		descs = append(descs, depsDesc)
+		layers = append(layers, depsLayer)
+	}
+
 	// Write Exec Layer as Blob -> Layer
+	// A language may not require an exec layer, such as an interpreted
+	// language with no compilation step.
 	execDesc, execLayer, err := buildFn(cfg, p)
 	if err != nil {
 		return
 	}
+	if execLayer != nil {
+		descs = append(descs, execDesc)
+		layers = append(layers, execLayer)
+	}
 
 	// Write Config Layer as Blob -> Layer
-	configDesc, _, err := newConfig(cfg, p, dataLayer, certsLayer, execLayer)
+	configDesc, _, err := newConfig(cfg, p, baseConfig, layers...)
 	if err != nil {
 		return
 	}
@@ -352,7 +454,7 @@ func newImage(cfg *buildConfig, dataDesc v1.Descriptor, dataLayer v1.Layer, cert
 		SchemaVersion: 2,
 		MediaType:     types.OCIManifestSchema1,
 		Config:        configDesc,
-		Layers:        []v1.Descriptor{dataDesc, certsDesc, execDesc},
+		Layers:        descs,
 	}
 
 	// Write image manifest out as json to a tempfile
@@ -398,7 +500,7 @@ func newImage(cfg *buildConfig, dataDesc v1.Descriptor, dataLayer v1.Layer, cert
 	return
 }
 
-func newConfig(cfg *buildConfig, p v1.Platform, layers ...v1.Layer) (desc v1.Descriptor, config v1.ConfigFile, err error) {
+func newConfig(cfg *buildConfig, p v1.Platform, base *v1.ConfigFile, layers ...v1.Layer) (desc v1.Descriptor, config v1.ConfigFile, err error) {
 	volumes := make(map[string]struct{}) // Volumes are odd, see spec.
 	for _, v := range cfg.f.Run.Volumes {
 		if v.Path == nil {
@@ -430,8 +532,8 @@ func newConfig(cfg *buildConfig, p v1.Platform, layers ...v1.Layer) (desc v1.Des
 		Variant: p.Variant,
 		Config: v1.Config{
 			ExposedPorts: map[string]struct{}{"8080/tcp": {}},
-			Env:          newConfigEnvs(cfg),
-			Cmd:          []string{"/func/f"}, // NOTE: Using Cmd because Entrypoint can not be overridden
+			Env:          newConfigEnvs(cfg, base),
+			Cmd:          newConfigCmd(cfg), // NOTE: Using Cmd because Entrypoint can not be overridden
 			WorkingDir:   "/func/",
 			StopSignal:   "SIGKILL",
 			User:         "1000",
@@ -484,12 +586,27 @@ func newConfig(cfg *buildConfig, p v1.Platform, layers ...v1.Layer) (desc v1.Des
 	return
 }
 
+// newConfigCmd returns the command which starts the function.
+func newConfigCmd(cfg *buildConfig) []string {
+	if cmd, ok := languageCommands[cfg.f.Runtime]; ok {
+		return cmd(cfg)
+	}
+	return []string{"/func/f"}
+}
+
 // newConfigEnvs returns the final set of environment variables to build into
-// the container.  This consists of func-provided build metadata envs as well
-// as any environment variables provided on the function itself.
-func newConfigEnvs(cfg *buildConfig) []string {
+// the container.  This consists of those of the base image (if any), those
+// required by the language, func-provided build metadata envs as well as any
+// environment variables provided on the function itself.
+func newConfigEnvs(cfg *buildConfig, base *v1.ConfigFile) []string {
 	envs := []string{}
 
+	// Base image (PATH etc.) and language envs
+	if base != nil {
+		envs = append(envs, base.Config.Env...)
+	}
+	envs = append(envs, languageEnvs[cfg.f.Runtime]...)
+
 	// FUNC_CREATED
 	// Formats container timestamp as RFC3339; a stricter version of the ISO 8601
 	// format used by the container image manifest's 'Created' attribute.
@@ -544,6 +661,159 @@ func newImageIndex(cfg *buildConfig, imageDescs []v1.Descriptor) (index v1.Index
 	return
 }
 
+// newBaseLayers returns the layers and config of the base image of the
+// function's language for the given platform.  Languages without a base
+// image are built from scratch, in which case none are returned.  Layers are
+// written to the build's blobs directory from a cache held in the function's
+// runtime data directory such that they are only downloaded once.
+func newBaseLayers(cfg *buildConfig, p v1.Platform) (descs []v1.Descriptor, layers []v1.Layer, config *v1.ConfigFile, err error) {
+	if _, ok := DefaultBaseImages[cfg.f.Runtime]; !ok {
+		return // built from scratch
+	}
+	image, err := builders.Image(cfg.f, cfg.name, DefaultBaseImages)
+	if err != nil {
+		return
+	}
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return
+	}
+	if cfg.verbose {
+		fmt.Printf("pulling base image %v (%v/%v)\n", ref, p.OS, p.Architecture)
+	}
+	img, err := remote.Image(ref,
+		remote.WithContext(cfg.ctx),
+		remote.WithPlatform(p),
+		remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return descs, layers, config, fmt.Errorf("cannot pull base image %v. %w", image, err)
+	}
+	if config, err = img.ConfigFile(); err != nil {
+		return
+	}
+	if layers, err = img.Layers(); err != nil {
+		return
+	}
+	for _, layer := range layers {
+		desc, err := newDescriptor(layer)
+		if err != nil {
+			return descs, layers, config, err
+		}
+		// Docker-formatted layers are identical to their OCI counterparts.
+		if mt, err := layer.MediaType(); err == nil && mt != types.DockerLayer {
+			desc.MediaType = mt
+		}
+		if err = writeCachedBlob(cfg, layer, desc.Digest); err != nil {
+			return descs, layers, config, err
+		}
+		descs = append(descs, desc)
+	}
+	return
+}
+
+// writeCachedBlob writes the compressed contents of the layer to the blobs
+// directory, first populating the layer cache if necessary.
+func writeCachedBlob(cfg *buildConfig, layer v1.Layer, digest v1.Hash) (err error) {
+	var (
+		cached = path(cfg.f.Root, fn.RunDataDir, "cache", "layers", digest.Algorithm, digest.Hex)
+		blob   = path(cfg.blobsDir(), digest.Hex)
+	)
+	if _, err = os.Stat(blob); err == nil {
+		return // shared by more than one platform
+	}
+	if _, err = os.Stat(cached); os.IsNotExist(err) {
+		if err = os.MkdirAll(filepath.Dir(cached), os.ModePerm); err != nil {
+			return
+		}
+		if err = writeLayer(layer, cached); err != nil {
+			return
+		}
+	} else if err != nil {
+		return
+	}
+	if cfg.verbose {
+		fmt.Printf("cp %v %v\n", cached, rel(cfg.buildDir(), blob))
+	}
+	if err = os.Link(cached, blob); err == nil {
+		return
+	}
+	return copyFile(cached, blob) // cross-device etc.
+}
+
+// writeLayer downloads the compressed layer to the given path.
+func writeLayer(layer v1.Layer, target string) error {
+	rc, err := layer.Compressed()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	tmp := target + ".partial"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(file, rc); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, target)
+}
+
+func copyFile(source, target string) error {
+	src, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// platformName returns the name of the platform as used in the names of the
+// files built for it, such as "linux.arm.v7", such that the files of
+// platforms differing only by variant do not collide.
+func platformName(p v1.Platform) string {
+	name := fmt.Sprintf("%v.%v", p.OS, p.Architecture)
+	if p.Variant != "" {
+		name = name + "." + p.Variant
+	}
+	return name
+}
+
+// newLayerBlob creates a layer from the given tarball, moving it into the
+// blobs directory, and returns both its descriptor and layer metadata.
+func newLayerBlob(cfg *buildConfig, target string, p *v1.Platform) (desc v1.Descriptor, layer v1.Layer, err error) {
+	// Layer
+	if layer, err = tarball.LayerFromFile(target); err != nil {
+		return
+	}
+
+	// Descriptor
+	if desc, err = newDescriptor(layer); err != nil {
+		return
+	}
+	desc.Platform = p
+
+	// Blob
+	blob := path(cfg.blobsDir(), desc.Digest.Hex)
+	if cfg.verbose {
+		fmt.Printf("mv %v %v\n", rel(cfg.buildDir(), target), rel(cfg.buildDir(), blob))
+	}
+	err = os.Rename(target, blob)
+	return
+}
+
 // rel is a simple prefix trim used exclusively for verbose debugging
 // statements to print paths as relative to the current build directory
 // rather than absolute. Returns the path relative to the current working
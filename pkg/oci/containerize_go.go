@@ -28,7 +28,7 @@ func buildGoLayer(cfg *buildConfig, p v1.Platform) (desc v1.Descriptor, layer v1
 	}
 
 	// Tarball
-	target := path(cfg.buildDir(), fmt.Sprintf("execlayer.%v.%v.tar.gz", p.OS, p.Architecture))
+	target := path(cfg.buildDir(), fmt.Sprintf("execlayer.%v.tar.gz", platformName(p)))
 	if err = newExecTarball(exe, target, cfg.verbose); err != nil {
 		return
 	}
@@ -0,0 +1,305 @@
+package oci
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"knative.dev/func/pkg/filesystem"
+	"knative.dev/func/pkg/funcignore"
+	fn "knative.dev/func/pkg/functions"
+)
+
+// buildNodeLayer creates the exec layer for a Node.js function, which
+// consists of the scaffolding entry point and, for TypeScript, the compiled
+// function.  The scaffolding's link to the function is replaced with an
+// absolute link to the function's source in the data layer.
+func buildNodeLayer(cfg *buildConfig, p v1.Platform) (desc v1.Descriptor, layer v1.Layer, err error) {
+	// The compiled output is the same for all platforms, so compile once.
+	out := path(cfg.buildDir(), "result", "build")
+	if cfg.f.Runtime == "typescript" {
+		if _, err = os.Stat(out); os.IsNotExist(err) {
+			if err = tscBuild(cfg, out); err != nil {
+				return
+			}
+		} else if err != nil {
+			return
+		}
+	}
+	target := path(cfg.buildDir(), fmt.Sprintf("execlayer.%v.tar.gz", platformName(p)))
+	if err = newNodeExecTarball(cfg, out, target); err != nil {
+		return
+	}
+	return newLayerBlob(cfg, target, &p)
+}
+
+func newNodeExecTarball(cfg *buildConfig, build, target string) error {
+	targetFile, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer targetFile.Close()
+
+	gw := gzip.NewWriter(targetFile)
+	defer gw.Close()
+
+	tw := tar.NewWriter(gw)
+	defer tw.Close()
+
+	if err = writeScaffolding(tw, path(cfg.buildDir(), "index.js"), "index.js", cfg.verbose); err != nil {
+		return err
+	}
+	if cfg.f.Runtime != "typescript" {
+		return nil
+	}
+	return writeDir(tw, build, "/func/build", filepath.WalkDir, []string{}, cfg.verbose)
+}
+
+// tscBuild compiles the TypeScript function into the given directory.  The
+// TypeScript compiler and type definitions are those of the function's
+// (development) dependencies, which are installed into a copy of the
+// function's source in the build directory such that the source is
+// unaltered.
+func tscBuild(cfg *buildConfig, out string) (err error) {
+	src := path(cfg.buildDir(), "result", "source")
+	fsys, err := funcignore.FS(cfg.f.Root)
+	if err != nil {
+		return
+	}
+	fsys = filesystem.NewMaskingFS(func(name string) bool { return name == "node_modules" }, fsys)
+	if cfg.verbose {
+		fmt.Printf("cp -r %v %v\n", cfg.f.Root, rel(cfg.buildDir(), src))
+	}
+	if err = filesystem.CopyFromFS(".", src, fsys); err != nil {
+		return fmt.Errorf("cannot copy function source. %w", err)
+	}
+	if err = npmInstall(cfg, src, false, nil); err != nil {
+		return
+	}
+	tsc := filepath.Join("node_modules", "typescript", "bin", "tsc")
+	if cfg.verbose {
+		fmt.Printf("cd %v && node %v --outDir %v\n", src, tsc, out)
+	} else {
+		fmt.Printf("   %v\n", filepath.Base(out))
+	}
+	cmd := exec.CommandContext(cfg.ctx, "node", tsc, "--outDir", out)
+	cmd.Dir = src
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("cannot compile typescript. %w", err)
+	}
+	return
+}
+
+// buildNodeDependencyLayer creates a layer containing the production
+// dependencies (node_modules) of the function for the given platform.  The
+// layer is cached in the function's runtime data directory by the hash of
+// its package manifests and the platform, such that an identical layer is
+// reused by subsequent builds until the dependencies change.
+func buildNodeDependencyLayer(cfg *buildConfig, p v1.Platform) (desc v1.Descriptor, layer v1.Layer, err error) {
+	hash, err := nodeDependencyHash(cfg.f.Root)
+	if err != nil {
+		return
+	}
+	name := platformName(p)
+	var (
+		cacheDir = path(cfg.f.Root, fn.RunDataDir, "cache", "layers")
+		cached   = path(cacheDir, fmt.Sprintf("node_modules.%v.%v.tar.gz", hash, name))
+		target   = path(cfg.buildDir(), fmt.Sprintf("dependencylayer.%v.tar.gz", name))
+	)
+	if _, err = os.Stat(cached); os.IsNotExist(err) {
+		if err = newNodeDependencyTarball(cfg, p, hash, cached); err != nil {
+			return
+		}
+	} else if err != nil {
+		return
+	}
+	if cfg.verbose {
+		fmt.Printf("cp %v %v\n", cached, rel(cfg.buildDir(), target))
+	}
+	if err = copyFile(cached, target); err != nil {
+		return
+	}
+	return newLayerBlob(cfg, target, &p)
+}
+
+// newNodeDependencyTarball installs the production dependencies of the
+// function for the given platform into a staging directory and writes them
+// to the target tarball, replacing any cached dependency tarballs of prior
+// package manifests (identified by their hash).
+func newNodeDependencyTarball(cfg *buildConfig, p v1.Platform, hash, target string) (err error) {
+	staging := path(cfg.buildDir(), "result", "dependencies", filepath.Base(target))
+	if err = os.MkdirAll(path(staging, "node_modules"), os.ModePerm); err != nil {
+		return
+	}
+	for _, name := range []string{"package.json", "package-lock.json"} {
+		if err = copyFile(path(cfg.f.Root, name), path(staging, name)); err != nil && !os.IsNotExist(err) {
+			return
+		}
+	}
+	env, err := npmPlatformEnv(p)
+	if err != nil {
+		return
+	}
+	if err = npmInstall(cfg, staging, true, env); err != nil {
+		return
+	}
+	if env != nil {
+		if err = checkNativeAddons(path(staging, "node_modules"), p); err != nil {
+			return
+		}
+	}
+
+	old, _ := filepath.Glob(path(filepath.Dir(target), "node_modules.*.tar.gz"))
+	for _, o := range old {
+		if !strings.HasPrefix(filepath.Base(o), "node_modules."+hash+".") {
+			_ = os.Remove(o)
+		}
+	}
+	if err = os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return
+	}
+	partial := target + ".partial"
+	if err = newDirTarball(path(staging, "node_modules"), "/func/node_modules", partial, filepath.WalkDir, []string{}, cfg.verbose); err != nil {
+		return
+	}
+	return os.Rename(partial, target)
+}
+
+// npmInstall installs the dependencies defined by the package manifests in
+// dir, optionally omitting development dependencies.  A clean install is
+// performed if a lockfile exists.  Packages are cached in the function's
+// runtime data directory.  The given environment variables (such as those
+// selecting a platform) are added to that of npm.
+func npmInstall(cfg *buildConfig, dir string, production bool, env []string) error {
+	if _, err := exec.LookPath("npm"); err != nil {
+		return errors.New("npm not found.  Node.js and npm are required to build Node.js and TypeScript functions on the host")
+	}
+	args := []string{"install"}
+	if _, err := os.Stat(path(dir, "package-lock.json")); err == nil {
+		args = []string{"ci"}
+	}
+	if production {
+		args = append(args, "--omit=dev")
+	}
+	cache := path(cfg.f.Root, fn.RunDataDir, "cache", "npm")
+	args = append(args, "--cache", cache, "--prefer-offline", "--no-audit", "--no-fund")
+	if !cfg.verbose {
+		args = append(args, "--silent")
+	}
+	if cfg.verbose {
+		fmt.Printf("cd %v && npm %v\n", dir, strings.Join(args, " "))
+	}
+	cmd := exec.CommandContext(cfg.ctx, "npm", args...)
+	cmd.Dir = dir
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("cannot install dependencies. %w", err)
+	}
+	return nil
+}
+
+// nodeOS and nodeArch map OCI platform operating systems and architectures
+// to those of Node.js (process.platform and process.arch).
+var (
+	nodeOS = map[string]string{
+		"linux":   "linux",
+		"darwin":  "darwin",
+		"windows": "win32",
+	}
+	nodeArch = map[string]string{
+		"amd64":   "x64",
+		"arm64":   "arm64",
+		"arm":     "arm",
+		"386":     "ia32",
+		"ppc64le": "ppc64",
+		"s390x":   "s390x",
+	}
+)
+
+// npmPlatformEnv returns the npm configuration which installs dependencies
+// for the given platform: optional dependencies (such as packages of
+// prebuilt binaries) are selected for it, and install scripts which download
+// prebuilt binaries do so for it.  Nil is returned for the host platform.
+func npmPlatformEnv(p v1.Platform) ([]string, error) {
+	if p.OS == runtime.GOOS && p.Architecture == runtime.GOARCH {
+		return nil, nil
+	}
+	platform, ok1 := nodeOS[p.OS]
+	arch, ok2 := nodeArch[p.Architecture]
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("the platform %v is not supported for Node.js functions by the host builder", platformString(p))
+	}
+	return []string{
+		"npm_config_os=" + platform,
+		"npm_config_cpu=" + arch,
+		"npm_config_platform=" + platform,
+		"npm_config_arch=" + arch,
+		"npm_config_target_arch=" + arch,
+	}, nil
+}
+
+// checkNativeAddons returns an error if any installed package compiled a
+// native addon on this host (as node-gyp does when no prebuilt binary is
+// available), as it would be compiled for the host rather than the given
+// platform.
+func checkNativeAddons(modules string, p v1.Platform) error {
+	compiled := []string{}
+	err := filepath.WalkDir(modules, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && d.Name() == "config.gypi" && filepath.Base(filepath.Dir(path)) == "build" {
+			pkg, _ := filepath.Rel(modules, filepath.Dir(filepath.Dir(path)))
+			compiled = append(compiled, filepath.ToSlash(pkg))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(compiled) > 0 {
+		return fmt.Errorf("the native addons of %v were compiled for this host and can not be used on %v.  Build on a host of that platform, or build only for this host's platform", strings.Join(compiled, ", "), platformString(p))
+	}
+	return nil
+}
+
+// nodeDependencyHash returns a hash of the package manifests of the
+// function at root.
+func nodeDependencyHash(root string) (string, error) {
+	h := sha256.New()
+	for _, name := range []string{"package.json", "package-lock.json"} {
+		b, err := os.ReadFile(path(root, name))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+		h.Write(b)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// nodeCommand returns the command which starts the function using the
+// scaffolding.  The scaffolding of a TypeScript function loads its compiled
+// index, otherwise the "main" of its package.json (default index.js).
+func nodeCommand(cfg *buildConfig) []string {
+	if cfg.f.Runtime == "typescript" {
+		return []string{"node", "/scaffolding/index.js", "/func/build/index.js"}
+	}
+	return []string{"node", "/scaffolding/index.js"}
+}
@@ -0,0 +1,213 @@
+package oci
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/oci/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestBuilder_BuildNode ensures that a Node.js function is built upon its
+// base image with its dependencies in a separate layer, started by the
+// scaffolding.
+func TestBuilder_BuildNode(t *testing.T) {
+	if _, err := exec.LookPath("npm"); err != nil {
+		t.Skip("npm is required to build Node.js functions")
+	}
+	root, done := Mktemp(t)
+	defer done()
+
+	registry := mock.NewRegistry()
+	defer registry.Close()
+	base := registry.Addr().String() + "/library/node:20-slim"
+	img, err := mutate.Config(empty.Image, v1.Config{Env: []string{"PATH=/usr/local/bin"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "node"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Build.BuilderImages = map[string]string{"host": base}
+
+	// A function without dependencies can be installed without network
+	// access.  Local node_modules are excluded from the data layer.
+	if err = os.Remove(filepath.Join(root, "package-lock.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, "package.json"), []byte(`{"name":"f","main":"index.js"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.MkdirAll(filepath.Join(root, "node_modules", "local"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = NewBuilder("host", true).Build(context.Background(), f, TestPlatforms); err != nil {
+		t.Fatal(err)
+	}
+
+	last := path(f.Root, fn.RunDataDir, "builds", "last", "oci")
+	validateOCIStructure(last, t)
+
+	expected := []fileInfo{
+		{Path: "/etc/pki/tls/certs/ca-certificates.crt"},
+		{Path: "/etc/ssl/certs/ca-certificates.crt"},
+		{Path: "/func", Type: os.ModeDir},
+		{Path: "/func/README.md"},
+		{Path: "/func/func.yaml"},
+		{Path: "/func/index.js"},
+		{Path: "/func/node_modules", Type: os.ModeDir},
+		{Path: "/func/node_modules/.package-lock.json"},
+		{Path: "/func/package.json"},
+		{Path: "/func/test", Type: os.ModeDir},
+		{Path: "/func/test/integration.js"},
+		{Path: "/func/test/unit.js"},
+		{Path: "/scaffolding", Type: os.ModeDir},
+		{Path: "/scaffolding/f", Type: os.ModeSymlink, Linkname: "/func", Executable: true},
+		{Path: "/scaffolding/index.js"},
+	}
+	validateOCIFiles(last, expected, t)
+
+	config := readImageConfig(last, t)
+	if strings.Join(config.Config.Cmd, " ") != "node /scaffolding/index.js" {
+		t.Fatalf("unexpected command %v", config.Config.Cmd)
+	}
+
+	// The dependency layer is cached for reuse by subsequent builds.
+	cached, _ := filepath.Glob(path(root, fn.RunDataDir, "cache", "layers", "node_modules.*.tar.gz"))
+	if len(cached) != 1 {
+		t.Fatalf("expected one cached dependency layer, got %v", cached)
+	}
+}
+
+// Test_tscBuild_Copy ensures that the dependencies of a TypeScript function
+// are installed into a copy of its source in the build directory, leaving
+// the function's source unaltered.
+func Test_tscBuild_Copy(t *testing.T) {
+	if _, err := exec.LookPath("npm"); err != nil {
+		t.Skip("npm is required to build TypeScript functions")
+	}
+	root, done := Mktemp(t)
+	defer done()
+
+	// A function without dependencies can be installed without network
+	// access, but can not then be compiled.
+	if err := os.WriteFile(filepath.Join(root, "package.json"), []byte(`{"name":"f"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "index.ts"), []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &buildConfig{ctx: context.Background(), f: fn.Function{Root: root, Runtime: "typescript"}, h: "hash"}
+	_ = tscBuild(cfg, path(cfg.buildDir(), "result", "build"))
+
+	for _, name := range []string{"node_modules", "package-lock.json"} {
+		if _, err := os.Stat(filepath.Join(root, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %v to not be written to the function's source. %v", name, err)
+		}
+	}
+	for _, name := range []string{"index.ts", "package.json", "package-lock.json"} {
+		if _, err := os.Stat(path(cfg.buildDir(), "result", "source", name)); err != nil {
+			t.Errorf("expected %v in the copy of the function's source. %v", name, err)
+		}
+	}
+}
+
+// Test_nodeCommand ensures the scaffolding is started with the compiled
+// entry point of a TypeScript function, and otherwise resolves the entry
+// point itself.
+func Test_nodeCommand(t *testing.T) {
+	cfg := &buildConfig{f: fn.Function{Runtime: "node"}}
+	if cmd := nodeCommand(cfg); strings.Join(cmd, " ") != "node /scaffolding/index.js" {
+		t.Fatalf("unexpected node command %v", cmd)
+	}
+	cfg.f.Runtime = "typescript"
+	if cmd := nodeCommand(cfg); strings.Join(cmd, " ") != "node /scaffolding/index.js /func/build/index.js" {
+		t.Fatalf("unexpected typescript command %v", cmd)
+	}
+}
+
+// Test_npmPlatformEnv ensures that dependencies are installed for platforms
+// other than the host by configuring npm with the Node.js names of the
+// platform's operating system and architecture.
+func Test_npmPlatformEnv(t *testing.T) {
+	// The host platform is installed as-is.
+	env, err := npmPlatformEnv(v1.Platform{OS: runtime.GOOS, Architecture: runtime.GOARCH})
+	if err != nil || env != nil {
+		t.Fatalf("expected no configuration for the host platform, got %v (%v)", env, err)
+	}
+
+	// Another platform is named as Node.js does.
+	other := v1.Platform{OS: "linux", Architecture: "arm64"}
+	if runtime.GOARCH == "arm64" {
+		other.Architecture = "amd64"
+	}
+	if env, err = npmPlatformEnv(other); err != nil {
+		t.Fatal(err)
+	}
+	joined := strings.Join(env, " ")
+	for _, expected := range []string{"npm_config_os=linux", "npm_config_cpu=" + nodeArch[other.Architecture], "npm_config_arch=" + nodeArch[other.Architecture]} {
+		if !strings.Contains(joined, expected) {
+			t.Errorf("expected %v in %v", expected, joined)
+		}
+	}
+
+	// Unknown platforms are an error.
+	if _, err = npmPlatformEnv(v1.Platform{OS: "plan9", Architecture: "mips"}); err == nil {
+		t.Fatal("expected an error for an unsupported platform")
+	}
+}
+
+// Test_checkNativeAddons ensures that dependencies which compiled a native
+// addon on the host are reported, while those with prebuilt binaries are
+// not.
+func Test_checkNativeAddons(t *testing.T) {
+	modules := t.TempDir()
+	p := v1.Platform{OS: "linux", Architecture: "arm64"}
+
+	// A prebuilt binary (as downloaded by prebuild-install) is acceptable.
+	prebuilt := filepath.Join(modules, "prebuilt", "build", "Release")
+	if err := os.MkdirAll(prebuilt, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(prebuilt, "addon.node"), []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkNativeAddons(modules, p); err != nil {
+		t.Fatalf("unexpected error for prebuilt addon. %v", err)
+	}
+
+	// An addon compiled by node-gyp is not.
+	compiled := filepath.Join(modules, "@scope", "compiled", "build")
+	if err := os.MkdirAll(compiled, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(compiled, "config.gypi"), []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := checkNativeAddons(modules, p)
+	if err == nil || !strings.Contains(err.Error(), "@scope/compiled") {
+		t.Fatalf("expected an error naming the compiled addon, got %v", err)
+	}
+}
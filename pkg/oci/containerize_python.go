@@ -0,0 +1,196 @@
+package oci
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"knative.dev/func/pkg/builders"
+	fn "knative.dev/func/pkg/functions"
+)
+
+// defaultPythonVersion is the version of Python for which dependencies are
+// vendored when it can not be determined from the tag of the base image.
+const defaultPythonVersion = "3.12"
+
+// pythonPlatformTags are the wheel platform tags which are compatible with
+// each container platform.  Pure Python wheels are compatible with all.
+var pythonPlatformTags = map[string][]string{
+	"linux/amd64":   {"manylinux2014_x86_64", "manylinux_2_28_x86_64"},
+	"linux/arm64":   {"manylinux2014_aarch64", "manylinux_2_28_aarch64"},
+	"linux/arm/v7":  {"manylinux2014_armv7l", "manylinux_2_31_armv7l", "linux_armv7l"},
+	"linux/ppc64le": {"manylinux2014_ppc64le", "manylinux_2_28_ppc64le"},
+	"linux/s390x":   {"manylinux2014_s390x", "manylinux_2_28_s390x"},
+}
+
+// buildPythonLayer creates the exec layer for a Python function, which
+// consists of the scaffolding entry point.  The scaffolding's link to the
+// function is replaced with an absolute link to the function's source in the
+// data layer.
+func buildPythonLayer(cfg *buildConfig, p v1.Platform) (desc v1.Descriptor, layer v1.Layer, err error) {
+	target := path(cfg.buildDir(), fmt.Sprintf("execlayer.%v.tar.gz", platformName(p)))
+	if err = newPythonExecTarball(path(cfg.buildDir(), "main.py"), target, cfg.verbose); err != nil {
+		return
+	}
+	return newLayerBlob(cfg, target, &p)
+}
+
+func newPythonExecTarball(source, target string, verbose bool) error {
+	targetFile, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer targetFile.Close()
+
+	gw := gzip.NewWriter(targetFile)
+	defer gw.Close()
+
+	tw := tar.NewWriter(gw)
+	defer tw.Close()
+
+	return writeScaffolding(tw, source, "main.py", verbose)
+}
+
+// writeScaffolding writes the scaffolding entry point source to the tar
+// writer as /scaffolding/[name], along with the scaffolding's link to the
+// function's source in the data layer (/func).
+func writeScaffolding(tw *tar.Writer, source, name string, verbose bool) error {
+	main, err := os.ReadFile(source)
+	if err != nil {
+		return fmt.Errorf("cannot read scaffolding entry point. %w", err)
+	}
+	headers := []*tar.Header{
+		{Name: "/scaffolding", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "/scaffolding/" + name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(main))},
+		{Name: "/scaffolding/f", Typeflag: tar.TypeSymlink, Linkname: "/func", Mode: 0777},
+	}
+	for _, header := range headers {
+		if err = tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if verbose {
+			fmt.Printf("→ %v \n", header.Name)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err = tw.Write(main); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// buildPythonDependencyLayer vendors the wheels of the packages listed in
+// the function's requirements.txt for the given platform, placing them in
+// a site-packages layer.
+func buildPythonDependencyLayer(cfg *buildConfig, p v1.Platform) (desc v1.Descriptor, layer v1.Layer, err error) {
+	dir := path(cfg.buildDir(), "result", "site-packages."+platformName(p))
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return
+	}
+	if err = pipInstall(cfg, p, dir); err != nil {
+		return
+	}
+
+	target := path(cfg.buildDir(), fmt.Sprintf("dependencylayer.%v.tar.gz", platformName(p)))
+	if err = newDirTarball(dir, "/site-packages", target, filepath.WalkDir, []string{}, cfg.verbose); err != nil {
+		return
+	}
+	return newLayerBlob(cfg, target, &p)
+}
+
+// pipInstall installs the wheels for the requirements of the function for
+// the given platform into the target directory.  Only binary distributions
+// (wheels) can be vendored for platforms other than the current host.
+func pipInstall(cfg *buildConfig, p v1.Platform, dir string) error {
+	requirements := path(cfg.f.Root, "requirements.txt")
+	if _, err := os.Stat(requirements); os.IsNotExist(err) {
+		return nil // no dependencies
+	}
+	tags, ok := pythonPlatformTags[platformString(p)]
+	if !ok {
+		return fmt.Errorf("the platform %v is not supported for Python functions by the host builder", platformString(p))
+	}
+	python, err := pythonBin()
+	if err != nil {
+		return err
+	}
+	image, err := builders.Image(cfg.f, cfg.name, DefaultBaseImages)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"-m", "pip", "install",
+		"--disable-pip-version-check",
+		"--target", dir,
+		"--only-binary=:all:",
+		"--implementation", "cp",
+		"--python-version", pythonVersion(image),
+	}
+	for _, tag := range tags {
+		args = append(args, "--platform", tag)
+	}
+	args = append(args, "-r", requirements)
+	if !cfg.verbose {
+		args = append(args, "--quiet")
+	}
+
+	cache := path(cfg.f.Root, fn.RunDataDir, "cache", "pip")
+	if cfg.verbose {
+		fmt.Printf("PIP_CACHE_DIR=%v %v %v\n", cache, python, strings.Join(args, " "))
+	} else {
+		fmt.Printf("   %v\n", filepath.Base(dir))
+	}
+	cmd := exec.CommandContext(cfg.ctx, python, args...)
+	cmd.Dir = cfg.f.Root
+	cmd.Env = append(os.Environ(), "PIP_CACHE_DIR="+cache)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("cannot vendor Python dependencies for %v. %w", platformString(p), err)
+	}
+	return nil
+}
+
+// pythonBin returns the Python interpreter to use for vendoring wheels.
+// The binary specified by FUNC_PYTHON_PATH is used if defined.
+func pythonBin() (string, error) {
+	if bin := os.Getenv("FUNC_PYTHON_PATH"); bin != "" {
+		return bin, nil
+	}
+	for _, bin := range []string{"python3", "python"} {
+		if _, err := exec.LookPath(bin); err == nil {
+			return bin, nil
+		}
+	}
+	return "", errors.New("python interpreter not found.  Python 3 with pip is required to build Python functions on the host")
+}
+
+var pythonImageVersion = regexp.MustCompile(`:(\d+\.\d+)`)
+
+// pythonVersion returns the Python version of the given base image as
+// indicated by its tag (for example "python:3.11-slim" is "3.11"), or the
+// default version if it can not be determined.
+func pythonVersion(image string) string {
+	if m := pythonImageVersion.FindStringSubmatch(image); m != nil {
+		return m[1]
+	}
+	return defaultPythonVersion
+}
+
+// platformString returns the platform as os/architecture[/variant]
+func platformString(p v1.Platform) string {
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s = s + "/" + p.Variant
+	}
+	return s
+}
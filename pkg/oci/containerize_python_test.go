@@ -0,0 +1,135 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/oci/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestBuilder_BuildPython ensures that a Python function is built upon its
+// base image, with the scaffolding entry point as its command.
+func TestBuilder_BuildPython(t *testing.T) {
+	root, done := Mktemp(t)
+	defer done()
+
+	// A base image (without layers) is served by a local registry such that
+	// its config can be checked for inclusion.
+	registry := mock.NewRegistry()
+	defer registry.Close()
+	base := registry.Addr().String() + "/library/python:3.11-slim"
+	img, err := mutate.Config(empty.Image, v1.Config{Env: []string{"PATH=/usr/local/bin"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "python"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Build.BuilderImages = map[string]string{"host": base}
+
+	// Without requirements there are no dependencies to vendor, so the build
+	// does not require Python on the host.
+	if err = os.Remove(filepath.Join(root, "requirements.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = NewBuilder("host", true).Build(context.Background(), f, TestPlatforms); err != nil {
+		t.Fatal(err)
+	}
+
+	last := path(f.Root, fn.RunDataDir, "builds", "last", "oci")
+	validateOCIStructure(last, t)
+
+	expected := []fileInfo{
+		{Path: "/etc/pki/tls/certs/ca-certificates.crt"},
+		{Path: "/etc/ssl/certs/ca-certificates.crt"},
+		{Path: "/func", Type: os.ModeDir},
+		{Path: "/func/Procfile"},
+		{Path: "/func/README.md"},
+		{Path: "/func/app.sh", Executable: true},
+		{Path: "/func/func.py"},
+		{Path: "/func/func.yaml"},
+		{Path: "/func/test_func.py"},
+		{Path: "/scaffolding", Type: os.ModeDir},
+		{Path: "/scaffolding/f", Type: os.ModeSymlink, Linkname: "/func", Executable: true},
+		{Path: "/scaffolding/main.py"},
+		{Path: "/site-packages", Type: os.ModeDir},
+	}
+	validateOCIFiles(last, expected, t)
+
+	config := readImageConfig(last, t)
+	if strings.Join(config.Config.Cmd, " ") != "python /scaffolding/main.py" {
+		t.Fatalf("unexpected command %v", config.Config.Cmd)
+	}
+	env := strings.Join(config.Config.Env, " ")
+	if !strings.HasPrefix(env, "PATH=/usr/local/bin PYTHONPATH=/site-packages") {
+		t.Fatalf("expected the base image and python envs, got %v", env)
+	}
+}
+
+// Test_pythonVersion ensures the Python version for which dependencies are
+// vendored is that of the base image's tag.
+func Test_pythonVersion(t *testing.T) {
+	tests := map[string]string{
+		"docker.io/library/python:3.12-slim":  "3.12",
+		"localhost:5000/python:3.9":           "3.9",
+		"example.com/custom/python-base":      defaultPythonVersion,
+		"example.com/python:latest@sha256:ab": defaultPythonVersion,
+	}
+	for image, expected := range tests {
+		if v := pythonVersion(image); v != expected {
+			t.Errorf("expected %v for %v, got %v", expected, image, v)
+		}
+	}
+}
+
+// readImageConfig returns the config of the first image in the OCI layout
+// at path.
+func readImageConfig(path string, t *testing.T) v1.ConfigFile {
+	t.Helper()
+	blob := func(digest string) []byte {
+		bb, err := os.ReadFile(filepath.Join(path, "blobs", "sha256", strings.TrimPrefix(digest, "sha256:")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bb
+	}
+	bb, err := os.ReadFile(filepath.Join(path, "index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index ImageIndex
+	if err = json.Unmarshal(bb, &index); err != nil {
+		t.Fatal(err)
+	}
+	var manifest v1.Manifest
+	if err = json.Unmarshal(blob(index.Manifests[0].Digest), &manifest); err != nil {
+		t.Fatal(err)
+	}
+	var config v1.ConfigFile
+	if err = json.Unmarshal(blob(manifest.Config.Digest.String()), &config); err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 )
 
 // Test_validatedLinkTaarget ensures that the function disallows
@@ -64,3 +66,17 @@ func Test_validatedLinkTarget(t *testing.T) {
 	}
 
 }
+
+// Test_platformName ensures that the files built for platforms which differ
+// only by variant, such as linux/arm/v6 and linux/arm/v7, are named
+// distinctly.
+func Test_platformName(t *testing.T) {
+	v6 := platformName(v1.Platform{OS: "linux", Architecture: "arm", Variant: "v6"})
+	v7 := platformName(v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"})
+	if v6 != "linux.arm.v6" || v7 != "linux.arm.v7" {
+		t.Fatalf("unexpected platform names %q and %q", v6, v7)
+	}
+	if name := platformName(v1.Platform{OS: "linux", Architecture: "amd64"}); name != "linux.amd64" {
+		t.Fatalf("unexpected platform name %q", name)
+	}
+}
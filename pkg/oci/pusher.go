@@ -45,7 +45,7 @@ func NewPusher(insecure, anon, verbose bool) *Pusher {
 }
 
 func (p *Pusher) Push(ctx context.Context, f fn.Function) (digest string, err error) {
-	go p.handleUpdates(ctx)
+	go p.handleUpdates(ctx, f)
 	defer func() { p.done <- true }()
 	buildDir, err := getLastBuildDir(f)
 	if err != nil {
@@ -70,6 +70,9 @@ func (p *Pusher) Push(ctx context.Context, f fn.Function) (digest string, err er
 	if err = p.writeIndex(ctx, ref, ii); err != nil {
 		return
 	}
+	if err = reportLayers(ctx, f, ii); err != nil {
+		return
+	}
 	h, err := ii.Digest()
 	if err != nil {
 		return
@@ -81,11 +84,20 @@ func (p *Pusher) Push(ctx context.Context, f fn.Function) (digest string, err er
 	return
 }
 
-func (p *Pusher) handleUpdates(ctx context.Context) {
-	var bar *progress.ProgressBar
+func (p *Pusher) handleUpdates(ctx context.Context, f fn.Function) {
+	var (
+		bar     *progress.ProgressBar
+		percent int64 = -1
+	)
 	for {
 		select {
 		case update := <-p.updates:
+			// Progress is reported each percent, rather than each update.
+			if update.Total > 0 && update.Complete*100/update.Total != percent {
+				percent = update.Complete * 100 / update.Total
+				fn.ReportProgress(ctx, fn.ProgressEvent{Type: fn.ProgressPushUpdated,
+					Function: f.Name, Complete: update.Complete, Total: update.Total})
+			}
 			if bar == nil {
 				bar = progress.NewOptions64(update.Total,
 					progress.OptionSetVisibility(term.IsTerminal(int(os.Stdin.Fd()))),
@@ -110,6 +122,37 @@ func (p *Pusher) handleUpdates(ctx context.Context) {
 	}
 }
 
+// reportLayers reports each distinct layer of the images of the pushed
+// index as pushed.
+func reportLayers(ctx context.Context, f fn.Function, ii v1.ImageIndex) error {
+	m, err := ii.IndexManifest()
+	if err != nil {
+		return err
+	}
+	reported := map[v1.Hash]bool{}
+	for _, desc := range m.Manifests {
+		img, err := ii.Image(desc.Digest)
+		if err != nil {
+			return err
+		}
+		layers, err := img.Layers()
+		if err != nil {
+			return err
+		}
+		for _, l := range layers {
+			h, err := l.Digest()
+			if err != nil {
+				return err
+			}
+			if !reported[h] {
+				reported[h] = true
+				fn.ReportProgress(ctx, fn.ProgressEvent{Type: fn.ProgressLayerPushed, Function: f.Name, Layer: h.String()})
+			}
+		}
+	}
+	return nil
+}
+
 // The last build directory is symlinked upon successful build.
 func getLastBuildDir(f fn.Function) (string, error) {
 	dir := filepath.Join(f.Root, fn.RunDataDir, "builds", "last")
@@ -59,10 +59,16 @@ func TestPusher_Push(t *testing.T) {
 	}()
 	defer s.Close()
 
-	// Create and push a function
+	// Create and push a function, recording the layers reported as pushed
+	layers := 0
 	client := fn.New(
 		fn.WithBuilder(NewBuilder("", false)),
-		fn.WithPusher(NewPusher(insecure, anon, verbose)))
+		fn.WithPusher(NewPusher(insecure, anon, verbose)),
+		fn.WithProgressListener(fn.ProgressListenerFunc(func(e fn.ProgressEvent) {
+			if e.Type == fn.ProgressLayerPushed {
+				layers++
+			}
+		})))
 
 	f := fn.Function{Root: root, Runtime: "go", Name: "f", Registry: l.Addr().String() + "/funcs"}
 
@@ -83,6 +89,9 @@ func TestPusher_Push(t *testing.T) {
 	if !success {
 		t.Fatal("did not receive the image index JSON")
 	}
+	if layers == 0 {
+		t.Fatal("expected the pushed layers to be reported")
+	}
 }
 
 // TestPusher_BasicAuth ensures that the pusher authenticates via basic auth when
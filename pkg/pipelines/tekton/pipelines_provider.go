@@ -19,7 +19,6 @@ import (
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
-	gitignore "github.com/sabhiram/go-gitignore"
 	"github.com/tektoncd/cli/pkg/pipelinerun"
 	"github.com/tektoncd/cli/pkg/taskrun"
 	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
@@ -30,6 +29,7 @@ import (
 	k8slabels "k8s.io/apimachinery/pkg/labels"
 
 	"knative.dev/func/pkg/docker"
+	"knative.dev/func/pkg/funcignore"
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/k8s"
 	fnlabels "knative.dev/func/pkg/k8s/labels"
@@ -261,17 +261,9 @@ func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn
 }
 
 // Creates tar stream with the function sources as they were in "./source" directory.
+// Files ignored by the function's .funcignore files are excluded, or by its
+// .gitignore files if it has no .funcignore (see funcignore).
 func sourcesAsTarStream(f fn.Function) *io.PipeReader {
-	ignored := func(p string) bool { return strings.HasPrefix(p, ".git") }
-	if gi, err := gitignore.CompileIgnoreFile(filepath.Join(f.Root, ".gitignore")); err == nil {
-		ignored = func(p string) bool {
-			if strings.HasPrefix(p, ".git") {
-				return true
-			}
-			return gi.MatchesPath(p)
-		}
-	}
-
 	pr, pw := io.Pipe()
 
 	const nobodyID = 65534
@@ -293,7 +285,7 @@ func sourcesAsTarStream(f fn.Function) *io.PipeReader {
 			_ = pw.CloseWithError(fmt.Errorf("error while creating tar stream from sources: %w", err))
 		}
 
-		err = filepath.Walk(f.Root, func(p string, fi fs.FileInfo, err error) error {
+		err = funcignore.Walk(f.Root, func(p string, d fs.DirEntry, err error) error {
 			if err != nil {
 				return fmt.Errorf("error traversing function directory: %w", err)
 			}
@@ -307,8 +299,9 @@ func sourcesAsTarStream(f fn.Function) *io.PipeReader {
 				return nil
 			}
 
-			if ignored(relp) {
-				return nil
+			fi, err := d.Info()
+			if err != nil {
+				return fmt.Errorf("cannot stat source file: %w", err)
 			}
 
 			lnk := ""
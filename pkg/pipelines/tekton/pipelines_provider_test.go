@@ -27,6 +27,14 @@ func TestSourcesAsTarStream(t *testing.T) {
 	if err := os.WriteFile(filepath.Join(root, ".git", "a.txt"), []byte("hello"), 0644); err != nil {
 		t.Fatal(err)
 	}
+	// ignored by the .gitignore, as the function has no .funcignore
+	if err := os.MkdirAll(filepath.Join(root, "bin", "release"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(filepath.Join(root, "bin")) })
+	if err := os.WriteFile(filepath.Join(root, "bin", "release", "a.out"), []byte("binary"), 0644); err != nil {
+		t.Fatal(err)
+	}
 
 	rc := sourcesAsTarStream(fn.Function{Root: root})
 	t.Cleanup(func() { _ = rc.Close() })
@@ -1,13 +1,14 @@
 package scaffolding
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -26,9 +27,9 @@ func newDetector(runtime string) (detector, error) {
 	case "rust":
 		return nil, ErrDetectorNotImplemented{runtime}
 	case "node":
-		return nil, ErrDetectorNotImplemented{runtime}
+		return &nodeDetector{extensions: []string{".js", ".cjs", ".mjs"}}, nil
 	case "typescript":
-		return nil, ErrDetectorNotImplemented{runtime}
+		return &nodeDetector{extensions: []string{".ts"}}, nil
 	case "quarkus":
 		return nil, ErrDetectorNotImplemented{runtime}
 	case "java":
@@ -82,8 +83,175 @@ func (d goDetector) hasFunctionDeclaration(filename, function string) bool {
 
 type pythonDetector struct{}
 
-func (d pythonDetector) Detect(dir string) (bool, bool, error) {
-	return false, false, errors.New("the Python method signature detector is not yet available")
+var (
+	// pythonDeclaration matches module-level (unindented) function
+	// declarations, capturing the function's name.
+	pythonDeclaration = regexp.MustCompile(`(?m)^(?:async[ \t]+)?def[ \t]+([A-Za-z_][A-Za-z0-9_]*)[ \t]*\(`)
+
+	// pythonStrings matches triple-quoted strings (such as docstrings), whose
+	// lines may otherwise be mistaken for declarations.
+	pythonStrings = regexp.MustCompile(`(?s)""".*?"""|'''.*?'''`)
+)
+
+// Detect the signature of the Python function defined in func.py.  A
+// module-level "main" or "handle" function is the static signature, and a
+// module-level "new" factory, which returns the function instance, is the
+// instanced signature.
+func (d pythonDetector) Detect(dir string) (static, instanced bool, err error) {
+	source, err := os.ReadFile(filepath.Join(dir, "func.py"))
+	if err != nil {
+		return static, instanced, fmt.Errorf("signature detector encountered an error when reading the function's source code. %w", err)
+	}
+	source = pythonStrings.ReplaceAll(source, nil)
+	for _, m := range pythonDeclaration.FindAllSubmatch(source, -1) {
+		switch string(m[1]) {
+		case "main", "handle":
+			static = true
+		case "new":
+			instanced = true
+		}
+	}
+	return
+}
+
+// NODE
+
+// nodeDetector detects the signature of Node.js and TypeScript functions.
+// Only source files of the given extensions are considered, such that the
+// compiled output of a TypeScript function is not mistaken for its source.
+type nodeDetector struct {
+	extensions []string
+}
+
+var (
+	// nodeComments matches block and line comments.  Line comments must be
+	// preceded by whitespace or begin the line, such that URLs within
+	// strings are retained.
+	nodeComments = regexp.MustCompile(`(?s)/\*.*?\*/|(?m)(?:^|[ \t])//[^\n]*`)
+
+	// nodeStatic matches exports of a "handle" function.
+	nodeStatic = []*regexp.Regexp{
+		regexp.MustCompile(`\bexport\s+(?:async\s+)?function\s*\*?\s*handle\s*\(`),
+		regexp.MustCompile(`\bexport\s+(?:const|let|var)\s+handle\b`),
+		regexp.MustCompile(`\bexports\.handle\s*=`),
+		regexp.MustCompile(`\bexport\s*\{[^}]*\bhandle\s*(?:,|\})`),
+		regexp.MustCompile(`\bmodule\.exports\s*=\s*\{[^}]*\bhandle\b`),
+	}
+
+	// nodeInstanced matches exports of a "new" factory or of a class as the
+	// module itself.  Other exported classes are instanced only if they
+	// declare a "handle" method (see nodeHandlerClass).
+	nodeInstanced = []*regexp.Regexp{
+		regexp.MustCompile(`\bexport\s+default\s+(?:abstract\s+)?class\b`),
+		regexp.MustCompile(`\bmodule\.exports\s*=\s*class\b`),
+		regexp.MustCompile(`\bexports\.new\s*=`),
+		regexp.MustCompile(`\bexport\s*\{[^}]*\bas\s+new\b`),
+		regexp.MustCompile(`\bmodule\.exports\s*=\s*\{[^}]*\bnew\s*[:(]`),
+	}
+
+	// nodeClass matches the start of the body of a named exported class.
+	nodeClass = regexp.MustCompile(`\bexport\s+(?:abstract\s+)?class\s+[\w$]+[^{]*\{`)
+
+	// nodeHandleMethod matches the declaration of a "handle" method (or
+	// property) within a class body, as distinct from calls such as
+	// this.handle().
+	nodeHandleMethod = regexp.MustCompile(`(?:^|[\s;{}])(?:(?:public|async|static)\s+)*handle\s*(?:\([^)]*\)\s*(?::[^{;]*)?\{|[:=])`)
+)
+
+// nodeHandlerClass returns true if the source exports a named class which
+// declares a "handle" method.
+func nodeHandlerClass(source []byte) bool {
+	for _, loc := range nodeClass.FindAllIndex(source, -1) {
+		depth, start := 1, loc[1]
+		end := start
+		for end < len(source) && depth > 0 {
+			switch source[end] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+			}
+			end++
+		}
+		if nodeHandleMethod.Match(source[start:end]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Detect the signature of the Node.js or TypeScript function defined in its
+// entry point.  An exported "handle" function is the static signature, and
+// an exported "new" factory or class with a "handle" method, from which the
+// function instance is created, is the instanced signature.
+func (d nodeDetector) Detect(dir string) (static, instanced bool, err error) {
+	files := d.entrypoints(dir)
+	if len(files) == 0 {
+		return static, instanced, fmt.Errorf("signature detector found no entry point for the function. Expected one of %v", d.candidates(dir))
+	}
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			return static, instanced, fmt.Errorf("signature detector encountered an error when reading the function's source code. %w", err)
+		}
+		source = nodeComments.ReplaceAll(source, nil)
+		for _, r := range nodeStatic {
+			if r.Match(source) {
+				static = true
+			}
+		}
+		for _, r := range nodeInstanced {
+			if r.Match(source) {
+				instanced = true
+			}
+		}
+		if nodeHandlerClass(source) {
+			instanced = true
+		}
+	}
+	return
+}
+
+// entrypoints returns those candidate entry points of the function which
+// exist.
+func (d nodeDetector) entrypoints(dir string) (files []string) {
+	for _, c := range d.candidates(dir) {
+		if fi, err := os.Stat(filepath.Join(dir, c)); err == nil && !fi.IsDir() {
+			files = append(files, filepath.Join(dir, c))
+		}
+	}
+	return
+}
+
+// candidates returns the possible entry points of the function: the "main"
+// of its package.json followed by the conventional index files, limited to
+// those of the detector's extensions.
+func (d nodeDetector) candidates(dir string) (candidates []string) {
+	pkg := struct {
+		Main string `json:"main"`
+	}{}
+	if b, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+		_ = json.Unmarshal(b, &pkg)
+	}
+	seen := map[string]bool{}
+	for _, c := range []string{pkg.Main, "index.js", "index.ts", filepath.Join("src", "index.ts")} {
+		c = filepath.Clean(c)
+		if c == "." || seen[c] || !d.supported(c) {
+			continue
+		}
+		seen[c] = true
+		candidates = append(candidates, c)
+	}
+	return
+}
+
+func (d nodeDetector) supported(file string) bool {
+	for _, e := range d.extensions {
+		if filepath.Ext(file) == e {
+			return true
+		}
+	}
+	return false
 }
 
 // TODO: Other Detectors
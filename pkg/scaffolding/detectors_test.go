@@ -5,6 +5,7 @@ package scaffolding
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -16,14 +17,9 @@ import (
 // identify the signature to expect of a function's source.
 func TestDetector_Go(t *testing.T) {
 	// NOTE:
-	// The detector need only check the function's name; not the entire signature
-	// because invocation hint (http vs cloudevent) is available in the
-	// function's metadata, and the detector needs to be as simple as possible
-	// while fulfilling its purpose of detecting which signature is _expected_
-	// of the source code, not whether or not it actually does:  that's the job
-	// of the compiler later.  This detection is used to determine which
-	// scaffolding code needs to be written to get the user to a proper
-	// complile attempt.
+	// Detection is by the function's name, with the invocation hint (http vs
+	// cloudevent) taken from the function's metadata.  The detected signature
+	// is then validated; see TestValidate_Go for invalid signatures.
 	tests := []struct {
 		Name string    // Name of the test
 		Sig  Signature // Signature Expected
@@ -38,7 +34,13 @@ func TestDetector_Go(t *testing.T) {
 			Src: `
 package f
 
-func New() { }
+import "net/http"
+
+type F struct{}
+
+func New() *F { return &F{} }
+
+func (f *F) Handle(w http.ResponseWriter, r *http.Request) {}
 	`},
 		{
 			Name: "Static HTTP",
@@ -47,7 +49,9 @@ func New() { }
 			Src: `
 package f
 
-func Handle() { }
+import "net/http"
+
+func Handle(w http.ResponseWriter, r *http.Request) { }
 	`},
 		{
 			Name: "Instanced Cloudevents",
@@ -56,7 +60,14 @@ func Handle() { }
 			Inv:  "cloudevent", // Invoke is the only place Cloudevents is singular
 			Src: `
 package f
-func New() { }
+
+import "github.com/cloudevents/sdk-go/v2/event"
+
+type F struct{}
+
+func New() *F { return &F{} }
+
+func (f *F) Handle(e event.Event) (*event.Event, error) { return &e, nil }
 	`},
 		{
 			Name: "Static Cloudevents",
@@ -70,7 +81,7 @@ func Handle() { }
 		{
 			Name: "Static and Instanced - error",
 			Sig:  UnknownSignature,
-			Err:  errors.New("error expected"), // TODO: typed error and err.Is/As
+			Err:  ErrSignatureConflict,
 			Src: `
 package f
 func Handle() { }
@@ -79,7 +90,7 @@ func New() { }
 		{
 			Name: "No Signatures Found - error",
 			Sig:  UnknownSignature,
-			Err:  errors.New("error expected"), // TODO: typed error and err.Is/As
+			Err:  ErrSignatureNotFound,
 			Src: `
 package f
 // Intentionally Blank
@@ -90,6 +101,9 @@ package f
 			Err:  nil,
 			Src: `
 package f
+
+import "net/http"
+
 /*
 This comment block would cause the function to be detected as instanced
 without the use of the language parser.
@@ -97,7 +111,7 @@ without the use of the language parser.
 func New()
 
 */
-func Handle() { }
+func Handle(w http.ResponseWriter, r *http.Request) { }
 	`},
 		{
 			Name: "Instanced with Handler",
@@ -106,11 +120,13 @@ func Handle() { }
 			Src: `
 package f
 
+import "net/http"
+
 type F struct{}
 
 func New() *F { return &F{} }
 
-func (f *MyFunction) Handle() {}
+func (f *F) Handle(w http.ResponseWriter, r *http.Request) {}
 	`},
 	}
 
@@ -129,17 +145,318 @@ func (f *MyFunction) Handle() {}
 				t.Fatalf("unexpected error. %v", err)
 			}
 
-			if test.Err != nil {
-				if err == nil {
-					t.Fatal("expected error not received")
-				} else {
-					t.Logf("received expected error: %v", err)
+			if test.Err != nil && !errors.Is(err, test.Err) {
+				t.Fatalf("expected error '%v', got '%v'", test.Err, err)
+			}
+
+			if s != test.Sig {
+				t.Fatalf("Expected signature '%v', got '%v'", test.Sig, s)
+			}
+		})
+	}
+}
+
+// TestDetector_Python ensures that the python language detector will
+// correctly identify the signature to expect of a function's source.
+func TestDetector_Python(t *testing.T) {
+	tests := []struct {
+		Name string    // Name of the test
+		Sig  Signature // Signature Expected
+		Err  error     // Error Expected
+		Inv  string    // invocation hint; "http" (default) or "cloudevent"
+		Src  string    // Source code to check
+	}{
+		{
+			Name: "Static HTTP",
+			Sig:  StaticHTTP,
+			Src: `
+def main(context):
+    return "OK", 200
+`},
+		{
+			Name: "Static Cloudevents",
+			Sig:  StaticCloudevents,
+			Inv:  "cloudevent",
+			Src: `
+def main(context):
+    return context.cloud_event.data
+`},
+		{
+			Name: "Static HTTP handle",
+			Sig:  StaticHTTP,
+			Src: `
+async def handle(scope, receive, send):
+    pass
+`},
+		{
+			Name: "Instanced HTTP",
+			Sig:  InstancedHTTP,
+			Src: `
+def new():
+    return Function()
+
+class Function:
+    async def handle(self, scope, receive, send):
+        pass
+`},
+		{
+			Name: "Instanced Cloudevents",
+			Sig:  InstancedCloudevents,
+			Inv:  "cloudevent",
+			Src: `
+def new():
+    return Function()
+`},
+		{
+			Name: "Docstring ignored",
+			Sig:  InstancedHTTP,
+			Src: `
+def new():
+    """
+def main(context):
+    """
+    return Function()
+`},
+		{
+			Name: "Static and instanced - error",
+			Sig:  UnknownSignature,
+			Err:  ErrSignatureConflict,
+			Src: `
+def main(context):
+    return "OK", 200
+
+def new():
+    return Function()
+`},
+		{
+			Name: "No signature - error",
+			Sig:  UnknownSignature,
+			Err:  ErrSignatureNotFound,
+			Src: `
+def helper():
+    pass
+`},
+		{
+			Name: "Nested main ignored - error",
+			Sig:  UnknownSignature,
+			Err:  errors.New("error expected"),
+			Src: `
+class F:
+    def main(self, context):
+        return "OK", 200
+`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			root, cleanup := Mktemp(t)
+			defer cleanup()
+
+			if err := os.WriteFile(filepath.Join(root, "func.py"), []byte(test.Src), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := detectSignature(root, "python", test.Inv)
+			if err != nil && test.Err == nil {
+				t.Fatalf("unexpected error. %v", err)
+			}
+			if test.Err != nil && err == nil {
+				t.Fatal("expected error not received")
+			}
+			var e ScaffoldingError
+			if test.Err != nil && errors.As(test.Err, &e) && !errors.Is(err, test.Err) {
+				t.Fatalf("expected error '%v', got '%v'", test.Err, err)
+			}
+			if s != test.Sig {
+				t.Fatalf("Expected signature '%v', got '%v'", test.Sig, s)
+			}
+		})
+	}
+}
+
+// TestDetector_Node ensures that the Node.js and TypeScript language detector
+// will correctly identify the signature to expect of a function's source.
+func TestDetector_Node(t *testing.T) {
+	tests := []struct {
+		Name    string    // Name of the test
+		Runtime string    // "node" (default) or "typescript"
+		File    string    // File to write; default index.js
+		Main    string    // optional "main" of the package.json
+		Sig     Signature // Signature Expected
+		Err     error     // Error Expected
+		Inv     string    // invocation hint; "http" (default) or "cloudevent"
+		Src     string    // Source code to check
+	}{
+		{
+			Name: "Static HTTP",
+			Sig:  StaticHTTP,
+			Src: `
+const handle = async (context, body) => body;
+module.exports = { handle };
+`},
+		{
+			Name: "Static Cloudevents",
+			Sig:  StaticCloudevents,
+			Inv:  "cloudevent",
+			Src: `
+exports.handle = function (context, event) {};
+`},
+		{
+			Name: "Static package.json main",
+			Main: "lib/main.js",
+			File: "lib/main.js",
+			Sig:  StaticHTTP,
+			Src: `
+export async function handle(context, body) {}
+`},
+		{
+			Name: "Instanced HTTP factory",
+			Sig:  InstancedHTTP,
+			Src: `
+module.exports = { new: () => new Function() };
+`},
+		{
+			Name: "Instanced HTTP class",
+			Sig:  InstancedHTTP,
+			Src: `
+module.exports = class {
+  handle(context, body) {}
+};
+`},
+		{
+			Name:    "TypeScript Static",
+			Runtime: "typescript",
+			File:    "src/index.ts",
+			Main:    "build/index.js",
+			Sig:     StaticHTTP,
+			Src: `
+const handle: HTTPFunction = async (context: Context, body?: IncomingBody) => body;
+export { handle };
+`},
+		{
+			Name:    "TypeScript Instanced Cloudevents",
+			Runtime: "typescript",
+			File:    "src/index.ts",
+			Sig:     InstancedCloudevents,
+			Inv:     "cloudevent",
+			Src: `
+export default class Function {
+  handle(context: Context, event: CloudEvent) {}
+}
+`},
+		{
+			Name: "Comments ignored - error",
+			Sig:  UnknownSignature,
+			Err:  ErrSignatureNotFound,
+			Src: `
+// module.exports = { handle };
+/*
+export function handle() {}
+*/
+`},
+		{
+			Name: "Static and instanced - error",
+			Sig:  UnknownSignature,
+			Err:  ErrSignatureConflict,
+			Src: `
+export function handle() {}
+export class Function {
+  handle() {}
+}
+`},
+		{
+			Name: "Static with exported helper class",
+			Sig:  StaticHTTP,
+			Src: `
+export class ValidationError extends Error {
+  constructor(message) { super(message); this.handled = false; }
+}
+export async function handle(context, body) {
+  if (!body) throw new ValidationError('body required');
+  return body;
+}
+`},
+		{
+			Name:    "TypeScript Instanced named class",
+			Runtime: "typescript",
+			File:    "src/index.ts",
+			Sig:     InstancedHTTP,
+			Src: `
+export class ValidationError extends Error {}
+export class Function {
+  async handle(context: Context, body?: IncomingBody): Promise<string> {
+    return this.format(body);
+  }
+}
+`},
+		{
+			Name:    "TypeScript compiled output ignored - error",
+			Runtime: "typescript",
+			File:    "index.js",
+			Sig:     UnknownSignature,
+			Err:     errors.New("error expected"),
+			Src: `
+exports.handle = handle;
+`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			root, cleanup := Mktemp(t)
+			defer cleanup()
+
+			if test.Runtime == "" {
+				test.Runtime = "node"
+			}
+			if test.File == "" {
+				test.File = "index.js"
+			}
+			if test.Main != "" {
+				pkg := fmt.Sprintf(`{"main":%q}`, test.Main)
+				if err := os.WriteFile(filepath.Join(root, "package.json"), []byte(pkg), os.ModePerm); err != nil {
+					t.Fatal(err)
 				}
 			}
+			file := filepath.Join(root, filepath.FromSlash(test.File))
+			if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(file, []byte(test.Src), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
 
+			s, err := detectSignature(root, test.Runtime, test.Inv)
+			if err != nil && test.Err == nil {
+				t.Fatalf("unexpected error. %v", err)
+			}
+			if test.Err != nil && err == nil {
+				t.Fatal("expected error not received")
+			}
+			var e ScaffoldingError
+			if test.Err != nil && errors.As(test.Err, &e) && !errors.Is(err, test.Err) {
+				t.Fatalf("expected error '%v', got '%v'", test.Err, err)
+			}
 			if s != test.Sig {
 				t.Fatalf("Expected signature '%v', got '%v'", test.Sig, s)
 			}
 		})
 	}
 }
+
+// TestDetector_NodeTemplates ensures that the signatures of the embedded
+// Node.js and TypeScript templates are detected.
+func TestDetector_NodeTemplates(t *testing.T) {
+	templates := map[string]string{"http": "http", "cloudevent": "cloudevents"}
+	for _, runtime := range []string{"node", "typescript"} {
+		for invoke, template := range templates {
+			src := filepath.Join("..", "..", "templates", runtime, template)
+			s, err := detectSignature(src, runtime, invoke)
+			if err != nil {
+				t.Fatalf("%v %v: %v", runtime, invoke, err)
+			}
+			if s != toSignature(false, invoke) {
+				t.Fatalf("%v %v: expected static signature, got '%v'", runtime, invoke, s)
+			}
+		}
+	}
+}
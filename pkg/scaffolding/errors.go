@@ -8,6 +8,9 @@ type ScaffoldingError struct {
 }
 
 func (e ScaffoldingError) Error() string {
+	if e.Msg != "" && e.Err == nil {
+		return fmt.Sprintf("scaffolding error. %v", e.Msg)
+	}
 	if e.Msg != "" {
 		return fmt.Sprintf("scaffolding error. %v. %v", e.Msg, e.Err)
 	}
@@ -20,6 +23,7 @@ func (e ScaffoldingError) Unwrap() error {
 
 var ErrScaffoldingNotFound = ScaffoldingError{"scaffolding not found", nil}
 var ErrSignatureNotFound = ScaffoldingError{"supported signature not found", nil}
+var ErrSignatureConflict = ScaffoldingError{"function may not implement both the static and instanced method signatures simultaneously", nil}
 
 type ErrDetectorNotImplemented struct {
 	Runtime string
@@ -84,12 +84,17 @@ func detectSignature(src, runtime, invoke string) (s Signature, err error) {
 	// Function must implement either a static handler or the instanced handler
 	// but not both.
 	if static && instanced {
-		return s, fmt.Errorf("function may not implement both the static and instanced method signatures simultaneously")
+		return s, ErrSignatureConflict
 	} else if !static && !instanced {
-		return s, fmt.Errorf("function does not implement any known method signatures or does not compile")
-	} else if instanced {
-		return toSignature(true, invoke), nil
-	} else {
-		return toSignature(false, invoke), nil
+		return s, ErrSignatureNotFound
 	}
+	s = toSignature(instanced, invoke)
+
+	// Validate the signature if supported by the runtime's detector
+	if v, ok := d.(validator); ok {
+		if err = v.Validate(src, s); err != nil {
+			return UnknownSignature, err
+		}
+	}
+	return
 }
@@ -25,8 +25,8 @@ func TestWrite_RuntimeErrors(t *testing.T) {
 		{"go", nil},
 		{"python", nil},
 		{"rust", &ErrDetectorNotImplemented{}},
-		{"node", &ErrDetectorNotImplemented{}},
-		{"typescript", &ErrDetectorNotImplemented{}},
+		{"node", nil},
+		{"typescript", nil},
 		{"quarkus", &ErrDetectorNotImplemented{}},
 		{"java", &ErrDetectorNotImplemented{}},
 		{"other", &ErrRuntimeNotRecognized{}},
@@ -73,9 +73,13 @@ func TestWrite(t *testing.T) {
 	impl := `
 package f
 
+import "net/http"
+
 type F struct{}
 
 func New() *F { return nil }
+
+func (f *F) Handle(w http.ResponseWriter, r *http.Request) {}
 `
 	err = os.WriteFile(filepath.Join(root, "f.go"), []byte(impl), os.ModePerm)
 	if err != nil {
@@ -131,9 +135,13 @@ func TestWrite_ScaffoldingNotFound(t *testing.T) {
 	impl := `
 package f
 
+import "net/http"
+
 type F struct{}
 
 func New() *F { return nil }
+
+func (f *F) Handle(w http.ResponseWriter, r *http.Request) {}
 `
 	err = os.WriteFile(filepath.Join(root, "f.go"), []byte(impl), os.ModePerm)
 	if err != nil {
@@ -0,0 +1,452 @@
+package scaffolding
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// validator of method signatures.  Detectors which implement this interface
+// verify that the detected signature is one which the scaffolding accepts,
+// such that an invalid signature is reported before the scaffolding is
+// built.
+type validator interface {
+	Validate(dir string, s Signature) error
+}
+
+// ErrInvalidSignature indicates a function whose method signature is not
+// one accepted by the scaffolding.  Each error is prefixed with the file
+// and line at which it occurs.
+type ErrInvalidSignature struct {
+	Errors []string
+}
+
+func (e ErrInvalidSignature) Error() string {
+	return fmt.Sprintf("invalid function signature:\n  %v", strings.Join(e.Errors, "\n  "))
+}
+
+// Validate the signature of the Go function in dir by type-checking its
+// package.  The Handle function (static) or the instance returned by New
+// (instanced) must be of a form accepted by the func-go middleware, as must
+// any lifecycle methods (Start, Stop, Ready, Alive) of an instance.
+//
+// Only errors in the signature are reported; all other errors are left to
+// the compiler.  Types which can not be resolved (such as those of modules
+// not available to the type-checker) are assumed valid.
+func (d goDetector) Validate(dir string, s Signature) error {
+	v, err := newGoValidator(dir)
+	if err != nil {
+		return err
+	}
+	switch s {
+	case StaticHTTP:
+		v.static(httpHandler)
+	case StaticCloudevents:
+		v.static(cloudeventsHandler(true))
+	case InstancedHTTP:
+		v.instanced(httpHandler)
+	case InstancedCloudevents:
+		v.instanced(cloudeventsHandler(false))
+	}
+	if len(v.errors) > 0 {
+		return ErrInvalidSignature{v.errors}
+	}
+	return nil
+}
+
+// goValidator is the type-checked package of a Go function, and the
+// signature errors found therein.
+type goValidator struct {
+	fset   *token.FileSet
+	pkg    *types.Package
+	errors []string
+}
+
+func newGoValidator(dir string) (*goValidator, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("signature validator encountered an error when scanning the function's source code. %w", err)
+	}
+	var (
+		fset  = token.NewFileSet()
+		files []*ast.File
+	)
+	for _, e := range entries {
+		if e.IsDir() || strings.HasSuffix(e.Name(), "_test.go") {
+			continue
+		}
+		if ok, err := build.Default.MatchFile(dir, e.Name()); !ok || err != nil {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, e.Name()), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("signature validator encountered an error when parsing the function's source code. %w", err)
+		}
+		files = append(files, f)
+	}
+	conf := types.Config{
+		Importer: newGoImporter(files),
+		Error:    func(error) {}, // left to the compiler
+	}
+	pkg, _ := conf.Check("function", fset, files, nil)
+	return &goValidator{fset: fset, pkg: pkg}, nil
+}
+
+// static validates the package-level Handle function.
+func (v *goValidator) static(h handlerForm) {
+	fn, ok := v.pkg.Scope().Lookup("Handle").(*types.Func)
+	if !ok {
+		return
+	}
+	if !h.accepts(fn.Type().(*types.Signature)) {
+		v.errorf(fn, "Handle must be %v, found %v", h.expected, v.signature(fn))
+	}
+}
+
+// instanced validates the package-level New function and the methods of the
+// instance it returns.
+func (v *goValidator) instanced(h handlerForm) {
+	fn, ok := v.pkg.Scope().Lookup("New").(*types.Func)
+	if !ok {
+		return
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		v.errorf(fn, "New must accept no arguments and return the function instance, found %v", v.signature(fn))
+		return
+	}
+	t := sig.Results().At(0).Type()
+	if !resolved(t) {
+		return
+	}
+	// Handle is required
+	if m, ptr := lookupMethod(t, "Handle"); m == nil {
+		if !hasUnresolvedEmbeds(t) {
+			v.errorf(fn, "the instance returned by New (%v) does not implement Handle", v.typeString(t))
+		}
+	} else if ptr {
+		v.errorf(fn, "Handle is declared on %v, but New returns %v", v.typeString(types.NewPointer(t)), v.typeString(t))
+	} else if !h.accepts(m.Type().(*types.Signature)) {
+		v.errorf(m, "Handle must be %v, found %v", h.expected, v.signature(m))
+	}
+	// Lifecycle methods are optional
+	for _, l := range lifecycleMethods {
+		m, ptr := lookupMethod(t, l.name)
+		if m == nil {
+			continue
+		}
+		if ptr {
+			v.errorf(m, "%v is declared on %v, but New returns %v, so it will not be invoked", l.name, v.typeString(types.NewPointer(t)), v.typeString(t))
+		} else if !l.accepts(m.Type().(*types.Signature)) {
+			v.errorf(m, "%v must be %v, found %v", l.name, l.expected, v.signature(m))
+		}
+	}
+}
+
+func (v *goValidator) errorf(obj types.Object, format string, args ...any) {
+	pos := v.fset.Position(obj.Pos())
+	v.errors = append(v.errors, fmt.Sprintf("%v:%v: %v", filepath.Base(pos.Filename), pos.Line, fmt.Sprintf(format, args...)))
+}
+
+// signature of the function as it would be written in its source
+func (v *goValidator) signature(fn *types.Func) string {
+	return v.typeString(fn.Type())
+}
+
+func (v *goValidator) typeString(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string {
+		if p == v.pkg {
+			return ""
+		}
+		return p.Name()
+	})
+}
+
+// lookupMethod returns the named method of t.  If the method is only in the
+// method set of a pointer to t, ptr is true.
+func lookupMethod(t types.Type, name string) (m *types.Func, ptr bool) {
+	if s := types.NewMethodSet(t).Lookup(nil, name); s != nil {
+		return s.Obj().(*types.Func), false
+	}
+	if _, ok := t.(*types.Pointer); ok {
+		return
+	}
+	if _, ok := t.Underlying().(*types.Interface); ok {
+		return
+	}
+	if s := types.NewMethodSet(types.NewPointer(t)).Lookup(nil, name); s != nil {
+		return s.Obj().(*types.Func), true
+	}
+	return
+}
+
+// hasUnresolvedEmbeds returns true if the struct t (or to which t points)
+// embeds a type which could not be resolved, and may therefore provide
+// methods of which the validator is unaware.
+func hasUnresolvedEmbeds(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	s, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return !resolved(t)
+	}
+	for i := 0; i < s.NumFields(); i++ {
+		if f := s.Field(i); f.Embedded() && (!resolved(f.Type()) || hasUnresolvedEmbeds(f.Type())) {
+			return true
+		}
+	}
+	return false
+}
+
+// SIGNATURE FORMS
+
+// handlerForm is an accepted form of a method, and a description of the
+// form for use in errors.
+type handlerForm struct {
+	expected string
+	accepts  func(*types.Signature) bool
+}
+
+const (
+	contextType  = "context.Context"
+	eventType    = "github.com/cloudevents/sdk-go/v2/event.Event"
+	eventPtrType = "*github.com/cloudevents/sdk-go/v2/event.Event"
+)
+
+// typeAliases are alternate names of the types used in signatures, such as
+// those re-exported by the root package of the CloudEvents SDK.
+var typeAliases = map[string]string{
+	"github.com/cloudevents/sdk-go/v2.Event":  eventType,
+	"*github.com/cloudevents/sdk-go/v2.Event": eventPtrType,
+}
+
+var httpHandler = handlerForm{
+	expected: "func(http.ResponseWriter, *http.Request)",
+	accepts: func(s *types.Signature) bool {
+		return s.Results().Len() == 0 &&
+			matches(s.Params(), "net/http.ResponseWriter", "*net/http.Request")
+	},
+}
+
+// cloudeventsHandler returns the accepted forms of a CloudEvents handler.
+// Static handlers are passed directly to the CloudEvents SDK, which
+// accepts a response event regardless of the arguments.  Instanced handlers
+// must implement one of the interfaces of the func-go middleware, which
+// return a response event only if an event is received.
+func cloudeventsHandler(static bool) handlerForm {
+	expected := "func([context.Context][, event.Event]) ([*event.Event][, error])"
+	if !static {
+		expected = "func([context.Context][, event.Event]) [error], or func([context.Context, ]event.Event) (*event.Event[, error])"
+	}
+	return handlerForm{
+		expected: expected,
+		accepts: func(s *types.Signature) bool {
+			var (
+				params   = s.Params()
+				results  = s.Results()
+				received = params.Len() > 0 && typeName(params.At(params.Len()-1).Type()) == eventType
+				replies  = results.Len() > 0 && typeName(results.At(0).Type()) == eventPtrType
+			)
+			if !(matches(params) || matches(params, contextType) || matches(params, eventType) || matches(params, contextType, eventType)) {
+				return false
+			}
+			if !static && replies && !received {
+				return false
+			}
+			switch results.Len() {
+			case 0:
+				return true
+			case 1:
+				return replies || isError(results.At(0).Type())
+			case 2:
+				return replies && isError(results.At(1).Type())
+			}
+			return false
+		},
+	}
+}
+
+// lifecycleMethods are the optional methods of an instance, and their
+// accepted forms.
+var lifecycleMethods = []struct {
+	name string
+	handlerForm
+}{
+	{"Start", handlerForm{"func(context.Context, map[string]string) error", func(s *types.Signature) bool {
+		return matches(s.Params(), contextType, "map[string]string") && s.Results().Len() == 1 && isError(s.Results().At(0).Type())
+	}}},
+	{"Stop", handlerForm{"func(context.Context) error", func(s *types.Signature) bool {
+		return matches(s.Params(), contextType) && s.Results().Len() == 1 && isError(s.Results().At(0).Type())
+	}}},
+	{"Ready", handlerForm{"func(context.Context) (bool, error)", func(s *types.Signature) bool {
+		return matches(s.Params(), contextType) && s.Results().Len() == 2 && typeName(s.Results().At(0).Type()) == "bool" && isError(s.Results().At(1).Type())
+	}}},
+	{"Alive", handlerForm{"func(context.Context) (bool, error)", func(s *types.Signature) bool {
+		return matches(s.Params(), contextType) && s.Results().Len() == 2 && typeName(s.Results().At(0).Type()) == "bool" && isError(s.Results().At(1).Type())
+	}}},
+}
+
+// matches returns true if the tuple consists of exactly the named types.
+// Unresolved types match any name.
+func matches(t *types.Tuple, names ...string) bool {
+	if t.Len() != len(names) {
+		return false
+	}
+	for i, name := range names {
+		if typ := t.At(i).Type(); resolved(typ) && typeName(typ) != name {
+			return false
+		}
+	}
+	return true
+}
+
+// typeName returns the name of the type qualified by its package path.
+func typeName(t types.Type) string {
+	name := types.TypeString(t, func(p *types.Package) string { return p.Path() })
+	if alias, ok := typeAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+// isError returns true if t is the error type or implements it (such as the
+// CloudEvents protocol.Result).  Unresolved types are assumed to be errors.
+func isError(t types.Type) bool {
+	if !resolved(t) {
+		return true
+	}
+	errorType := types.Universe.Lookup("error").Type()
+	if _, ok := t.Underlying().(*types.Interface); !ok {
+		return false
+	}
+	return types.Identical(t, errorType) || types.Implements(t, errorType.Underlying().(*types.Interface))
+}
+
+// resolved returns false if the type is, or refers to, a type which could
+// not be resolved.
+func resolved(t types.Type) bool {
+	switch t := t.(type) {
+	case *types.Basic:
+		return t.Kind() != types.Invalid
+	case *types.Pointer:
+		return resolved(t.Elem())
+	case *types.Slice:
+		return resolved(t.Elem())
+	case *types.Map:
+		return resolved(t.Key()) && resolved(t.Elem())
+	case *types.Named:
+		return t.Obj().Pkg() == nil || t.Obj().Pkg().Scope().Lookup(standIn) == nil
+	}
+	return true
+}
+
+// IMPORTS
+
+// standIn is the name of an object declared by stand-in packages to mark
+// them as such.  It is not a valid identifier, so can not be referred to.
+const standIn = "·stand-in"
+
+// packageNames are the names of packages which differ from those derived
+// from their import path.
+var packageNames = map[string]string{
+	"github.com/cloudevents/sdk-go/v2": "cloudevents",
+}
+
+// goImporter imports packages of the standard library from the compiler's
+// export data.  Other packages, whose modules may not be available, are
+// replaced with a stand-in package declaring each type referred to by the
+// function's source.  This is sufficient to compare types by name.
+type goImporter struct {
+	std  types.Importer
+	refs map[string]map[string]bool // names referred to, by package path
+	pkgs map[string]*types.Package
+}
+
+func newGoImporter(files []*ast.File) *goImporter {
+	i := &goImporter{
+		std:  importer.Default(),
+		refs: map[string]map[string]bool{},
+		pkgs: map[string]*types.Package{},
+	}
+	for _, f := range files {
+		names := map[string]string{} // package path by name within the file
+		for _, s := range f.Imports {
+			path, err := strconv.Unquote(s.Path.Value)
+			if err != nil {
+				continue
+			}
+			name := packageName(path)
+			if s.Name != nil {
+				name = s.Name.Name
+			}
+			names[name] = path
+			if i.refs[path] == nil {
+				i.refs[path] = map[string]bool{}
+			}
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if x, ok := sel.X.(*ast.Ident); ok && names[x.Name] != "" {
+					i.refs[names[x.Name]][sel.Sel.Name] = true
+				}
+			}
+			return true
+		})
+	}
+	return i
+}
+
+func (i *goImporter) Import(path string) (*types.Package, error) {
+	if p, ok := i.pkgs[path]; ok {
+		return p, nil
+	}
+	if isStandard(path) {
+		p, err := i.std.Import(path)
+		if err == nil {
+			i.pkgs[path] = p
+			return p, nil
+		}
+	}
+	p := types.NewPackage(path, packageName(path))
+	for name := range i.refs[path] {
+		tn := types.NewTypeName(token.NoPos, p, name, nil)
+		types.NewNamed(tn, types.NewStruct(nil, nil), nil)
+		p.Scope().Insert(tn)
+	}
+	p.Scope().Insert(types.NewConst(token.NoPos, p, standIn, types.Typ[types.Bool], constant.MakeBool(true)))
+	p.MarkComplete()
+	i.pkgs[path] = p
+	return p, nil
+}
+
+// isStandard returns true if the import path is that of a package of the
+// standard library; those whose first element contains no dot.
+func isStandard(path string) bool {
+	return !strings.Contains(strings.Split(path, "/")[0], ".")
+}
+
+// packageName returns the conventional name of a package given its import
+// path; the last element of the path, excluding any major version suffix
+// and "go-" or "-go" affixes, unless otherwise known.
+func packageName(path string) string {
+	if name, ok := packageNames[path]; ok {
+		return name
+	}
+	elems := strings.Split(path, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = elems[len(elems)-2]
+	}
+	name = strings.TrimPrefix(strings.TrimSuffix(name, "-go"), "go-")
+	return strings.ReplaceAll(name, "-", "_")
+}
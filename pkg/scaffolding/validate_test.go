@@ -0,0 +1,219 @@
+//go:build !integration
+// +build !integration
+
+package scaffolding
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestValidate_Go ensures that the Go signature validator accepts the forms
+// of the func-go middleware and reports the location of those it does not.
+func TestValidate_Go(t *testing.T) {
+	tests := []struct {
+		Name string // Name of the test
+		Inv  string // invocation hint; "http" (default) or "cloudevent"
+		Err  string // Expected error (file:line and message), if any
+		Src  string // Source code to check
+	}{
+		{
+			Name: "Static HTTP wrong arguments",
+			Err:  "function.go:6: Handle must be func(http.ResponseWriter, *http.Request), found func(ctx context.Context, w http.ResponseWriter, r *http.Request)",
+			Src: `package f
+
+import "context"
+import "net/http"
+
+func Handle(ctx context.Context, w http.ResponseWriter, r *http.Request) {}
+`},
+		{
+			Name: "Static HTTP with result",
+			Err:  "function.go:5: Handle must be",
+			Src: `package f
+
+import "net/http"
+
+func Handle(w http.ResponseWriter, r *http.Request) error { return nil }
+`},
+		{
+			Name: "Static Cloudevents",
+			Inv:  "cloudevent",
+			Src: `package f
+
+import (
+	"context"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+func Handle(ctx context.Context, e cloudevents.Event) (*cloudevents.Event, protocol.Result) { return nil, nil }
+`},
+		{
+			Name: "Static Cloudevents wrong arguments",
+			Inv:  "cloudevent",
+			Err:  "function.go:5: Handle must be",
+			Src: `package f
+
+import "github.com/cloudevents/sdk-go/v2/event"
+
+func Handle(e event.Event, s string) {}
+`},
+		{
+			Name: "Instanced returns value with pointer receivers",
+			Err:  "function.go:7: Handle is declared on *F, but New returns F",
+			Src: `package f
+
+import "net/http"
+
+type F struct{}
+
+func New() F { return F{} }
+
+func (f *F) Handle(w http.ResponseWriter, r *http.Request) {}
+`},
+		{
+			Name: "Instanced missing Handle",
+			Err:  "function.go:5: the instance returned by New (*F) does not implement Handle",
+			Src: `package f
+
+type F struct{}
+
+func New() *F { return &F{} }
+`},
+		{
+			Name: "Instanced with arguments",
+			Err:  "function.go:5: New must accept no arguments",
+			Src: `package f
+
+type F struct{}
+
+func New(name string) *F { return &F{} }
+`},
+		{
+			Name: "Instanced lifecycle methods",
+			Src: `package f
+
+import (
+	"context"
+	"net/http"
+)
+
+type F struct{}
+
+func New() *F { return &F{} }
+
+func (f *F) Handle(w http.ResponseWriter, r *http.Request) {}
+
+func (f *F) Start(ctx context.Context, cfg map[string]string) error { return nil }
+
+func (f *F) Stop(ctx context.Context) error { return nil }
+
+func (f *F) Ready(ctx context.Context) (bool, error) { return true, nil }
+
+func (f *F) Alive(ctx context.Context) (bool, error) { return true, nil }
+`},
+		{
+			Name: "Instanced invalid lifecycle method",
+			Err:  "function.go:14: Start must be func(context.Context, map[string]string) error, found func(ctx context.Context) error",
+			Src: `package f
+
+import (
+	"context"
+	"net/http"
+)
+
+type F struct{}
+
+func New() *F { return &F{} }
+
+func (f *F) Handle(w http.ResponseWriter, r *http.Request) {}
+
+func (f *F) Start(ctx context.Context) error { return nil }
+`},
+		{
+			Name: "Instanced Cloudevents reply without event",
+			Inv:  "cloudevent",
+			Err:  "function.go:13: Handle must be",
+			Src: `package f
+
+import (
+	"context"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+type F struct{}
+
+func New() *F { return &F{} }
+
+func (f *F) Handle(ctx context.Context) (*event.Event, error) { return nil, nil }
+`},
+		{
+			Name: "Instanced embedding unresolved type",
+			Src: `package f
+
+import "example.com/base"
+
+type F struct{ *base.Function }
+
+func New() *F { return &F{} }
+`},
+		{
+			Name: "Instanced interface",
+			Src: `package f
+
+import "net/http"
+
+type Handler interface {
+	Handle(http.ResponseWriter, *http.Request)
+}
+
+func New() Handler { return nil }
+`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			root, cleanup := Mktemp(t)
+			defer cleanup()
+
+			if err := os.WriteFile(filepath.Join(root, "function.go"), []byte(test.Src), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := detectSignature(root, "go", test.Inv)
+			if test.Err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error. %v", err)
+				}
+				return
+			}
+			var e ErrInvalidSignature
+			if !errors.As(err, &e) {
+				t.Fatalf("expected ErrInvalidSignature, got '%v'", err)
+			}
+			if len(e.Errors) != 1 || !strings.HasPrefix(e.Errors[0], test.Err) {
+				t.Fatalf("expected error '%v', got '%v'", test.Err, e.Errors)
+			}
+		})
+	}
+}
+
+// TestValidate_GoTemplates ensures that the signatures of the embedded Go
+// templates are valid.
+func TestValidate_GoTemplates(t *testing.T) {
+	templates := map[string]string{"http": "http", "cloudevent": "cloudevents"}
+	for invoke, template := range templates {
+		src := filepath.Join("..", "..", "templates", "go", template)
+		if _, err := detectSignature(src, "go", invoke); err != nil {
+			t.Fatalf("go %v: %v", invoke, err)
+		}
+	}
+}
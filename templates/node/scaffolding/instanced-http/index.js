@@ -0,0 +1,68 @@
+'use strict';
+/*
+ * Scaffolding which serves the function linked at ./f using the
+ * faas-js-runtime.  The function exports either a 'new' factory or a class,
+ * from which the function instance is created.  The module loaded is the one
+ * given as the first argument (such as the compiled output of a TypeScript
+ * function) or otherwise the "main" of the function's package.json.  The
+ * function listens on the port defined by the PORT environment variable
+ * (default 8080).
+ */
+const path = require('path');
+const { pathToFileURL } = require('url');
+
+const f = path.join(__dirname, 'f');
+
+// The runtime is a dependency of the function.
+const { start } = require(require.resolve('faas-js-runtime', { paths: [f] }));
+
+// Methods of the instance which are served by the runtime.
+const methods = ['handle', 'init', 'shutdown', 'liveness', 'readiness'];
+
+async function load(entry) {
+  try {
+    return require(entry);
+  } catch (err) {
+    if (err.code !== 'ERR_REQUIRE_ESM') throw err;
+    return import(pathToFileURL(entry).href);
+  }
+}
+
+function isClass(v) {
+  return typeof v === 'function' && /^class\b/.test(Function.prototype.toString.call(v));
+}
+
+// isHandlerClass returns true for a class which declares a handle method,
+// as distinct from other classes the function may export (such as errors).
+function isHandlerClass(v) {
+  return isClass(v) && typeof v.prototype.handle === 'function';
+}
+
+// create the function instance using the exported factory or class.
+function create(m) {
+  if (typeof m.new === 'function') return m.new();
+  if (isClass(m)) return new m();
+  if (isClass(m.default)) return new m.default();
+  const C = Object.values(m).find(isHandlerClass);
+  if (!C) throw new TypeError("function must export either a 'new' factory or a class");
+  return new C();
+}
+
+load(process.argv[2] ? path.resolve(process.argv[2]) : require.resolve(f))
+  .then(create)
+  .then((instance) => {
+    const func = {};
+    for (const name of methods) {
+      if (typeof instance[name] === 'function') {
+        func[name] = instance[name].bind(instance);
+      }
+    }
+    return start(func, {
+      port: Number(process.env.PORT || 8080),
+      logLevel: process.env.FUNC_LOG_LEVEL || 'warn'
+    });
+  })
+  .catch((err) => {
+    console.error(err);
+    process.exit(1);
+  });
@@ -0,0 +1,25 @@
+"""
+Scaffolding which runs the function linked at ./f as a process using the
+func-python runtime.  The function's 'new' factory is invoked to create the
+function instance.  The function listens on the port defined by the PORT
+environment variable (default 8080).
+"""
+import importlib.util
+import os
+import sys
+
+from func_python.http import serve
+
+f = os.path.join(os.path.dirname(os.path.abspath(__file__)), "f")
+
+
+def load():
+    spec = importlib.util.spec_from_file_location("func", os.path.join(f, "func.py"))
+    module = importlib.util.module_from_spec(spec)
+    sys.path.insert(0, f)
+    spec.loader.exec_module(module)
+    return module
+
+
+if __name__ == "__main__":
+    serve(load().new)
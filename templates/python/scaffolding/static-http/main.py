@@ -0,0 +1,44 @@
+"""
+Scaffolding which runs the function linked at ./f as a process.  Functions
+which implement the 'main(context)' signature are served by the parliament
+runtime; those which implement 'handle' are served by the func-python
+runtime.  The function listens on the port defined by the PORT environment
+variable (default 8080).
+"""
+import ast
+import importlib.util
+import os
+import runpy
+import sys
+
+f = os.path.join(os.path.dirname(os.path.abspath(__file__)), "f")
+source = os.path.join(f, "func.py")
+
+
+def implements_handle():
+    """
+    Returns true if the function defines 'handle' at module level.  The
+    source is inspected rather than imported such that the function is
+    loaded only once, by the runtime which serves it.
+    """
+    with open(source) as file:
+        tree = ast.parse(file.read(), source)
+    return any(isinstance(node, (ast.FunctionDef, ast.AsyncFunctionDef))
+               and node.name == "handle" for node in tree.body)
+
+
+def load():
+    spec = importlib.util.spec_from_file_location("func", source)
+    module = importlib.util.module_from_spec(spec)
+    sys.path.insert(0, f)
+    spec.loader.exec_module(module)
+    return module
+
+
+if __name__ == "__main__":
+    if implements_handle():
+        from func_python.http import serve
+        serve(load().handle)
+    else:
+        sys.argv = ["parliament", f]
+        runpy.run_module("parliament", run_name="__main__", alter_sys=True)
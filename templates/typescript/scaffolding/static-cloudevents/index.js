@@ -0,0 +1,38 @@
+'use strict';
+/*
+ * Scaffolding which serves the function linked at ./f using the
+ * faas-js-runtime.  The function exports a 'handle' function.  The module
+ * loaded is the one given as the first argument (such as the compiled output
+ * of a TypeScript function) or otherwise the "main" of the function's
+ * package.json.  The function listens on the port defined by the PORT
+ * environment variable (default 8080).
+ */
+const path = require('path');
+const { pathToFileURL } = require('url');
+
+const f = path.join(__dirname, 'f');
+
+// The runtime is a dependency of the function.
+const { start } = require(require.resolve('faas-js-runtime', { paths: [f] }));
+
+async function load(entry) {
+  try {
+    return require(entry);
+  } catch (err) {
+    if (err.code !== 'ERR_REQUIRE_ESM') throw err;
+    return import(pathToFileURL(entry).href);
+  }
+}
+
+load(process.argv[2] ? path.resolve(process.argv[2]) : require.resolve(f))
+  .then((m) => {
+    const func = typeof m.handle === 'function' ? m : m.default;
+    return start(func, {
+      port: Number(process.env.PORT || 8080),
+      logLevel: process.env.FUNC_LOG_LEVEL || 'warn'
+    });
+  })
+  .catch((err) => {
+    console.error(err);
+    process.exit(1);
+  });